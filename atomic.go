@@ -0,0 +1,659 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// AtomicExt is the URI of the Atomic Operations extension.
+const AtomicExt = "https://jsonapi.org/ext/atomic"
+
+// Operation codes of the Atomic Operations extension.
+const (
+	OpAdd    = "add"
+	OpUpdate = "update"
+	OpRemove = "remove"
+)
+
+// An Operation represents an operation of the Atomic Operations extension.
+//
+// Data is a Resource when the operation targets a resource. For an update, it
+// is a partial *SoftResource that only holds the fields to update. When the
+// operation targets a relationship, Data is an Identifier for a to-one
+// relationship (an empty Identifier means null) and Identifiers for a to-many
+// relationship. Data is nil when a resource is removed.
+//
+// A resource added by an operation can be given a local ID (LID) instead of an
+// ID. The following operations can refer to it by using that local ID as an ID
+// in Data or as the LID of Ref. A local ID must therefore not be equal to the
+// ID of an existing resource of the same type.
+type Operation struct {
+	Op   string
+	Ref  Ref
+	Data any
+	LID  string
+	Meta Meta
+}
+
+// A Ref identifies the target of an operation.
+//
+// Relationship is only set when the operation targets a relationship.
+type Ref struct {
+	Type         string `json:"type"`
+	ID           string `json:"id,omitempty"`
+	LID          string `json:"lid,omitempty"`
+	Relationship string `json:"relationship,omitempty"`
+}
+
+// An OperationResult is the result of an operation.
+//
+// Data is nil if the operation does not return a resource.
+type OperationResult struct {
+	Data Resource
+	Meta Meta
+}
+
+// MarshalOperations marshals ops into a document of the Atomic Operations
+// extension.
+func MarshalOperations(ops []Operation) ([]byte, error) {
+	lids := map[string]map[string]bool{}
+	raws := make([]json.RawMessage, 0, len(ops))
+
+	for _, op := range ops {
+		m := map[string]any{"op": op.Op}
+
+		if op.Ref.ID != "" || op.Ref.LID != "" {
+			m["ref"] = op.Ref
+		}
+
+		if res, ok := op.Data.(Resource); ok && op.Op == OpAdd && op.LID != "" {
+			declareLID(lids, res.GetType().Name, op.LID)
+		}
+
+		switch d := op.Data.(type) {
+		case Resource:
+			m["data"] = marshalAtomicResource(d, op, lids)
+		case Identifier:
+			if d.ID == "" {
+				m["data"] = nil
+			} else {
+				m["data"] = atomicLinkage(d.Type, d.ID, lids)
+			}
+		case Identifiers:
+			data := make([]map[string]string, 0, len(d))
+			for _, iden := range d {
+				data = append(data, atomicLinkage(iden.Type, iden.ID, lids))
+			}
+
+			m["data"] = data
+		default:
+			if op.Data != nil {
+				return nil, errors.New("jsonapi: data contains an unknown type")
+			}
+		}
+
+		if len(op.Meta) > 0 {
+			m["meta"] = op.Meta
+		}
+
+		raw, err := json.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+
+		raws = append(raws, raw)
+	}
+
+	return json.Marshal(map[string]any{
+		"atomic:operations": raws,
+	})
+}
+
+// UnmarshalOperations reads a document of the Atomic Operations extension and
+// returns the operations it contains.
+//
+// The operations are validated against schema. The errors are of type Error
+// and their source pointers indicate the invalid operation.
+func UnmarshalOperations(payload []byte, schema *Schema) ([]Operation, error) {
+	ske := struct {
+		Operations []operationSkeleton `json:"atomic:operations"`
+	}{}
+
+	err := json.Unmarshal(payload, &ske)
+	if err != nil {
+		return nil, NewErrBadRequest("Invalid JSON", err.Error())
+	}
+
+	if len(ske.Operations) == 0 {
+		e := NewErrBadRequest(
+			"Missing operations",
+			"The document must contain a non-empty atomic:operations member.",
+		)
+		e.Source["pointer"] = ""
+
+		return nil, e
+	}
+
+	lids := map[string]map[string]bool{}
+	ops := make([]Operation, 0, len(ske.Operations))
+
+	for i := range ske.Operations {
+		op, err := unmarshalOperation(ske.Operations[i], schema, lids)
+		if err != nil {
+			return nil, withPointer(err, fmt.Sprintf("/atomic:operations/%d", i))
+		}
+
+		ops = append(ops, op)
+	}
+
+	return ops, nil
+}
+
+// MarshalOperationResults marshals results into a document of the Atomic
+// Operations extension.
+//
+// The resources are marshaled with all their fields and prepath is prepended to
+// their links.
+func MarshalOperationResults(results []OperationResult, prepath string) ([]byte, error) {
+	raws := make([]map[string]any, 0, len(results))
+
+	for _, result := range results {
+		m := map[string]any{}
+
+		if result.Data != nil {
+			typ := result.Data.GetType()
+			fields := typ.Fields()
+			relData := map[string][]string{}
+
+			for _, rel := range typ.Rels {
+				relData[typ.Name] = append(relData[typ.Name], rel.FromName)
+			}
+
+			m["data"] = json.RawMessage(
+				MarshalResource(result.Data, prepath, fields, relData),
+			)
+		}
+
+		if len(result.Meta) > 0 {
+			m["meta"] = result.Meta
+		}
+
+		raws = append(raws, m)
+	}
+
+	return json.Marshal(map[string]any{
+		"atomic:results": raws,
+		"jsonapi": JSONAPI{
+			Version: "1.1",
+			Ext:     []string{AtomicExt},
+		},
+	})
+}
+
+// UnmarshalOperationResults reads a document of the Atomic Operations extension
+// and returns the results it contains.
+func UnmarshalOperationResults(payload []byte, schema *Schema) ([]OperationResult, error) {
+	ske := struct {
+		Results []struct {
+			Data json.RawMessage `json:"data"`
+			Meta Meta            `json:"meta"`
+		} `json:"atomic:results"`
+	}{}
+
+	err := json.Unmarshal(payload, &ske)
+	if err != nil {
+		return nil, NewErrBadRequest("Invalid JSON", err.Error())
+	}
+
+	results := make([]OperationResult, 0, len(ske.Results))
+
+	for i, r := range ske.Results {
+		result := OperationResult{Meta: r.Meta}
+
+		if len(r.Data) > 0 && string(r.Data) != "null" {
+			res, _, err := unmarshalAtomicResource(r.Data, schema, nil, false)
+			if err != nil {
+				return nil, withPointer(err, fmt.Sprintf("/atomic:results/%d/data", i))
+			}
+
+			result.Data = res
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+type operationSkeleton struct {
+	Op   string          `json:"op"`
+	Ref  *Ref            `json:"ref"`
+	Href string          `json:"href"`
+	Data json.RawMessage `json:"data"`
+	Meta Meta            `json:"meta"`
+}
+
+// atomicIdentifier is an identifier that may hold a local ID.
+type atomicIdentifier struct {
+	ID   string `json:"id,omitempty"`
+	Type string `json:"type"`
+	LID  string `json:"lid,omitempty"`
+}
+
+// unmarshalOperation returns the operation described by ske.
+//
+// lids holds the local IDs declared by the previous operations. The local ID
+// declared by the operation, if any, is added to it.
+func unmarshalOperation(
+	ske operationSkeleton,
+	schema *Schema,
+	lids map[string]map[string]bool,
+) (Operation, error) {
+	op := Operation{
+		Op:   ske.Op,
+		Meta: ske.Meta,
+	}
+
+	switch ske.Op {
+	case OpAdd, OpUpdate, OpRemove:
+	default:
+		return op, withPointer(NewErrBadRequest(
+			"Invalid operation",
+			fmt.Sprintf("%q is not a valid operation code.", ske.Op),
+		), "/op")
+	}
+
+	ref, err := unmarshalRef(ske, schema, lids)
+	if err != nil {
+		return op, err
+	}
+
+	op.Ref = ref
+
+	// Relationship
+	if ref.Relationship != "" {
+		rel := schema.GetType(ref.Type).Rels[ref.Relationship]
+
+		if rel.ToOne && ske.Op != OpUpdate {
+			return op, withPointer(NewErrBadRequest(
+				"Invalid operation",
+				"A to-one relationship can only be updated.",
+			), "/op")
+		}
+
+		op.Data, err = unmarshalAtomicLinkage(ske.Data, rel, lids)
+		if err != nil {
+			return op, withPointer(err, "/data")
+		}
+
+		return op, nil
+	}
+
+	// Resource
+	switch ske.Op {
+	case OpAdd:
+		res, lid, err := unmarshalAtomicResource(ske.Data, schema, lids, false)
+		if err != nil {
+			return op, withPointer(err, "/data")
+		}
+
+		if ref.Type != "" && ref.Type != res.GetType().Name {
+			return op, withPointer(NewErrConflict(
+				fmt.Sprintf("Type %q does not match the reference.", res.GetType().Name),
+			), "/data/type")
+		}
+
+		op.Ref = Ref{}
+		op.Data = res
+		op.LID = lid
+	case OpUpdate:
+		res, lid, err := unmarshalAtomicResource(ske.Data, schema, lids, true)
+		if err != nil {
+			return op, withPointer(err, "/data")
+		}
+
+		target := Ref{Type: res.GetType().Name, ID: res.Get("id").(string), LID: lid}
+		if lid != "" {
+			target.ID = ""
+		}
+
+		if target.ID == "" && target.LID == "" {
+			return op, withPointer(NewErrBadRequest(
+				"Missing identifier",
+				"The resource to update must have an ID or a local ID.",
+			), "/data")
+		}
+
+		if ref.Type != "" && ref != target {
+			return op, withPointer(NewErrConflict(
+				"The resource does not match the reference.",
+			), "/data")
+		}
+
+		op.Ref = target
+		op.Data = res
+	case OpRemove:
+		if ref.ID == "" && ref.LID == "" {
+			return op, withPointer(NewErrBadRequest(
+				"Missing reference",
+				"The resource to remove must be referenced by an ID or a local ID.",
+			), "/ref")
+		}
+	}
+
+	return op, nil
+}
+
+// unmarshalRef returns the reference found in the ref or href member of ske
+// after validating it.
+func unmarshalRef(
+	ske operationSkeleton,
+	schema *Schema,
+	lids map[string]map[string]bool,
+) (Ref, error) {
+	ref := Ref{}
+	pointer := "/ref"
+
+	switch {
+	case ske.Ref != nil && ske.Href != "":
+		return ref, withPointer(NewErrBadRequest(
+			"Invalid reference",
+			"The ref and href members cannot be both present.",
+		), "/href")
+	case ske.Ref != nil:
+		ref = *ske.Ref
+	case ske.Href != "":
+		pointer = "/href"
+
+		u, err := url.Parse(ske.Href)
+		if err != nil {
+			return ref, withPointer(NewErrBadRequest("Invalid reference", err.Error()), pointer)
+		}
+
+		frags := parseFragments(u.Path)
+
+		switch {
+		case len(frags) >= 1 && len(frags) <= 2:
+			ref.Type = frags[0]
+			if len(frags) == 2 {
+				ref.ID = frags[1]
+			}
+		case len(frags) == 4 && frags[2] == "relationships":
+			ref.Type, ref.ID, ref.Relationship = frags[0], frags[1], frags[3]
+		default:
+			return ref, withPointer(NewErrBadRequest(
+				"Invalid reference",
+				fmt.Sprintf("%q does not reference a resource or a relationship.", ske.Href),
+			), pointer)
+		}
+	default:
+		return ref, nil
+	}
+
+	switch {
+	case !schema.HasType(ref.Type):
+		return ref, withPointer(NewErrBadRequest(
+			"Unknown type",
+			fmt.Sprintf("%q is not a known type.", ref.Type),
+		), pointer)
+	case ref.ID != "" && ref.LID != "":
+		return ref, withPointer(NewErrBadRequest(
+			"Invalid reference",
+			"A reference cannot have both an ID and a local ID.",
+		), pointer)
+	case ref.LID != "" && !lids[ref.Type][ref.LID]:
+		return ref, withPointer(newErrUnknownLID(ref.LID), pointer+"/lid")
+	}
+
+	if ref.Relationship != "" {
+		if _, ok := schema.GetType(ref.Type).Rels[ref.Relationship]; !ok {
+			return ref, withPointer(
+				NewErrUnknownFieldInBody(ref.Type, ref.Relationship),
+				pointer,
+			)
+		}
+
+		if ref.ID == "" && ref.LID == "" {
+			return ref, withPointer(NewErrBadRequest(
+				"Invalid reference",
+				"A relationship must be referenced with an ID or a local ID.",
+			), pointer)
+		}
+	}
+
+	return ref, nil
+}
+
+// unmarshalAtomicResource returns the resource found in data and its local ID.
+//
+// lids holds the local IDs that can be referenced. The local IDs found in the
+// relationships are replaced by IDs that hold the local IDs. If lids is nil,
+// the local ID of the resource is ignored.
+//
+// If partial is false, the local ID of the resource is declared in lids.
+// Otherwise, it must already be declared and it is also used as the ID of the
+// resource, which only holds the fields found in data.
+func unmarshalAtomicResource(
+	data []byte,
+	schema *Schema,
+	lids map[string]map[string]bool,
+	partial bool,
+) (Resource, string, error) {
+	if len(data) == 0 || data[0] != '{' {
+		return nil, "", NewErrMissingDataMember()
+	}
+
+	raw := map[string]json.RawMessage{}
+	iden := atomicIdentifier{}
+
+	err := json.Unmarshal(data, &raw)
+	if err == nil {
+		err = json.Unmarshal(data, &iden)
+	}
+
+	if err != nil {
+		return nil, "", NewErrBadRequest("Invalid JSON", err.Error())
+	}
+
+	if !schema.HasType(iden.Type) {
+		return nil, "", withPointer(NewErrBadRequest(
+			"Unknown type",
+			fmt.Sprintf("%q is not a known type.", iden.Type),
+		), "/type")
+	}
+
+	if iden.LID != "" && lids != nil {
+		switch {
+		case partial && iden.ID == "":
+			if !lids[iden.Type][iden.LID] {
+				return nil, "", withPointer(newErrUnknownLID(iden.LID), "/lid")
+			}
+
+			raw["id"], _ = json.Marshal(iden.LID)
+		case !partial:
+			if lids[iden.Type][iden.LID] {
+				return nil, "", withPointer(NewErrBadRequest(
+					"Duplicate local ID",
+					fmt.Sprintf("The local ID %q is already used.", iden.LID),
+				), "/lid")
+			}
+
+			declareLID(lids, iden.Type, iden.LID)
+		}
+	}
+
+	// Local IDs in relationships
+	if rawRels, ok := raw["relationships"]; ok {
+		rels := map[string]map[string]json.RawMessage{}
+
+		err = json.Unmarshal(rawRels, &rels)
+		if err != nil {
+			return nil, "", withPointer(
+				NewErrBadRequest("Invalid JSON", err.Error()),
+				"/relationships",
+			)
+		}
+
+		for name, rel := range rels {
+			if linkage, ok := rel["data"]; ok {
+				rel["data"], err = resolveLinkageLIDs(linkage, lids)
+				if err != nil {
+					return nil, "", withPointer(err, "/relationships/"+name+"/data")
+				}
+			}
+		}
+
+		raw["relationships"], _ = json.Marshal(rels)
+	}
+
+	data, _ = json.Marshal(raw)
+
+	if partial {
+		res, err := UnmarshalPartialResource(data, schema)
+
+		return res, iden.LID, err
+	}
+
+	res, err := UnmarshalResource(data, schema)
+
+	return res, iden.LID, err
+}
+
+// unmarshalAtomicLinkage returns the resource linkage found in data as an
+// Identifier or Identifiers depending on whether rel is a to-one or a to-many
+// relationship.
+func unmarshalAtomicLinkage(
+	data []byte,
+	rel Rel,
+	lids map[string]map[string]bool,
+) (any, error) {
+	if len(data) == 0 {
+		return nil, NewErrMissingDataMember()
+	}
+
+	data, err := resolveLinkageLIDs(data, lids)
+	if err != nil {
+		return nil, err
+	}
+
+	var idens Identifiers
+
+	switch {
+	case rel.ToOne && string(data) == "null":
+		return Identifier{}, nil
+	case rel.ToOne && data[0] == '{':
+		idens = Identifiers{{}}
+		err = json.Unmarshal(data, &idens[0])
+	case !rel.ToOne && data[0] == '[':
+		err = json.Unmarshal(data, &idens)
+	default:
+		err = errors.New("the data does not match the relationship")
+	}
+
+	if err != nil {
+		return nil, NewErrBadRequest("Invalid resource linkage", err.Error())
+	}
+
+	for _, iden := range idens {
+		if !rel.Targets(iden.Type) {
+			return nil, NewErrConflict(
+				fmt.Sprintf("Type %q does not match relationship %q.", iden.Type, rel.FromName),
+			)
+		}
+	}
+
+	if rel.ToOne {
+		return idens[0], nil
+	}
+
+	return idens, nil
+}
+
+// resolveLinkageLIDs returns the resource linkage found in data after replacing
+// the local IDs with IDs that hold the local IDs.
+//
+// An error is returned if a local ID is not declared in lids.
+func resolveLinkageLIDs(data json.RawMessage, lids map[string]map[string]bool) (json.RawMessage, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return data, nil
+	}
+
+	var (
+		idens []atomicIdentifier
+		err   error
+	)
+
+	toOne := data[0] == '{'
+	if toOne {
+		idens = make([]atomicIdentifier, 1)
+		err = json.Unmarshal(data, &idens[0])
+	} else {
+		err = json.Unmarshal(data, &idens)
+	}
+
+	if err != nil {
+		return nil, NewErrBadRequest("Invalid resource linkage", err.Error())
+	}
+
+	for i := range idens {
+		if idens[i].ID == "" && idens[i].LID != "" {
+			if !lids[idens[i].Type][idens[i].LID] {
+				return nil, newErrUnknownLID(idens[i].LID)
+			}
+
+			idens[i].ID, idens[i].LID = idens[i].LID, ""
+		}
+	}
+
+	if toOne {
+		return json.Marshal(idens[0])
+	}
+
+	return json.Marshal(idens)
+}
+
+// marshalAtomicResource returns a map that represents res as the data of op.
+func marshalAtomicResource(res Resource, op Operation, lids map[string]map[string]bool) map[string]any {
+	m := resourceObject(res, func(typ, id string) map[string]string {
+		return atomicLinkage(typ, id, lids)
+	})
+
+	if op.Op == OpUpdate && op.Ref.LID != "" {
+		delete(m, "id")
+		m["lid"] = op.Ref.LID
+	} else if op.LID != "" {
+		m["lid"] = op.LID
+	}
+
+	return m
+}
+
+// atomicLinkage returns the resource identifier object of the resource of type
+// typ identified by id, which is a local ID if it is declared in lids.
+func atomicLinkage(typ, id string, lids map[string]map[string]bool) map[string]string {
+	if lids[typ][id] {
+		return map[string]string{"type": typ, "lid": id}
+	}
+
+	return map[string]string{"type": typ, "id": id}
+}
+
+// declareLID adds the local ID lid of type typ to lids.
+func declareLID(lids map[string]map[string]bool, typ, lid string) {
+	if lids[typ] == nil {
+		lids[typ] = map[string]bool{}
+	}
+
+	lids[typ][lid] = true
+}
+
+// newErrUnknownLID returns an error for a local ID that is not declared by a
+// previous operation.
+func newErrUnknownLID(lid string) Error {
+	return NewErrBadRequest(
+		"Unknown local ID",
+		fmt.Sprintf("The local ID %q is not declared by a previous operation.", lid),
+	)
+}
@@ -0,0 +1,462 @@
+package jsonapi
+
+import (
+	"fmt"
+)
+
+// ExecuteOperations applies ops in order using the stores of the handler and
+// returns a result for each operation.
+//
+// The operations are applied atomically: if one of them fails, the changes
+// made by the previous ones are undone and the error is returned. Its source
+// pointer indicates the operation that failed. The stores are not locked
+// during the execution, which means concurrent requests may observe the
+// intermediate states.
+//
+// The local IDs of the added resources are replaced by new IDs, and so are the
+// references to them in the following operations. As with requests served by
+// the handler, the inverse relationships are kept in sync.
+func (h *Handler) ExecuteOperations(ops []Operation) ([]OperationResult, error) {
+	tx := &transaction{}
+
+	// The handler's helpers are used with stores that record the changes.
+	th := *h
+	th.Stores = tx.wrap(h.Stores)
+
+	exec := operationExecutor{
+		h:    &th,
+		lids: &LIDResolver{},
+	}
+	results := make([]OperationResult, 0, len(ops))
+
+	for i, op := range ops {
+		result, err := exec.execute(op)
+		if err != nil {
+			tx.rollback()
+
+			return nil, withPointer(err, fmt.Sprintf("/atomic:operations/%d", i))
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// operationExecutor executes operations one after the other.
+type operationExecutor struct {
+	h *Handler
+
+	// lids holds the IDs assigned to the resources added with a local ID.
+	lids *LIDResolver
+}
+
+func (e *operationExecutor) execute(op Operation) (OperationResult, error) {
+	if op.Ref.Relationship != "" {
+		return OperationResult{}, e.executeRel(op)
+	}
+
+	switch op.Op {
+	case OpAdd:
+		return e.add(op)
+	case OpUpdate:
+		return e.update(op)
+	case OpRemove:
+		return OperationResult{}, e.remove(op)
+	}
+
+	return OperationResult{}, NewErrBadRequest(
+		"Invalid operation",
+		fmt.Sprintf("%q is not a valid operation code.", op.Op),
+	)
+}
+
+func (e *operationExecutor) add(op Operation) (OperationResult, error) {
+	res, ok := op.Data.(Resource)
+	if !ok {
+		return OperationResult{}, NewErrMissingDataMember()
+	}
+
+	typ := res.GetType().Name
+
+	store, err := e.h.store(typ)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	id := res.Get("id").(string)
+	if id == "" {
+		id = e.h.newID()
+		res.Set("id", id)
+	} else {
+		existing, err := store.Resource(id)
+		if err != nil {
+			return OperationResult{}, err
+		}
+
+		if existing != nil {
+			return OperationResult{}, NewErrConflict(
+				fmt.Sprintf("A resource with ID %q already exists.", id),
+			)
+		}
+	}
+
+	if op.LID != "" {
+		e.lids.Add(typ, op.LID, id)
+	}
+
+	e.lids.ResolveResource(res)
+
+	err = store.Insert(res)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	err = e.h.relSync().resource(nil, res)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	added, err := e.h.resource(typ, id)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	copyLID(res, added)
+
+	return OperationResult{Data: added}, nil
+}
+
+func (e *operationExecutor) update(op Operation) (OperationResult, error) {
+	res, ok := op.Data.(Resource)
+	if !ok {
+		return OperationResult{}, NewErrMissingDataMember()
+	}
+
+	typ := res.GetType().Name
+
+	id, err := e.refID(op.Ref)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	store, err := e.h.store(typ)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	old, err := e.h.resource(typ, id)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	res.Set("id", id)
+	e.lids.ResolveResource(res)
+
+	err = store.Update(res)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	err = e.h.relSync().resource(old, res)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	updated, err := e.h.resource(typ, id)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	return OperationResult{Data: updated}, nil
+}
+
+func (e *operationExecutor) remove(op Operation) error {
+	id, err := e.refID(op.Ref)
+	if err != nil {
+		return err
+	}
+
+	store, err := e.h.store(op.Ref.Type)
+	if err != nil {
+		return err
+	}
+
+	old, err := e.h.resource(op.Ref.Type, id)
+	if err != nil {
+		return err
+	}
+
+	err = store.Delete(id)
+	if err != nil {
+		return err
+	}
+
+	return e.h.relSync().resource(old, nil)
+}
+
+func (e *operationExecutor) executeRel(op Operation) error {
+	id, err := e.refID(op.Ref)
+	if err != nil {
+		return err
+	}
+
+	store, err := e.h.store(op.Ref.Type)
+	if err != nil {
+		return err
+	}
+
+	parent, err := e.h.resource(op.Ref.Type, id)
+	if err != nil {
+		return err
+	}
+
+	rel, ok := parent.Rels()[op.Ref.Relationship]
+	if !ok {
+		return NewErrUnknownFieldInBody(op.Ref.Type, op.Ref.Relationship)
+	}
+
+	var idens Identifiers
+
+	switch d := op.Data.(type) {
+	case Identifier:
+		idens = Identifiers{}
+		if d.ID != "" || d.LID != "" {
+			idens = append(idens, Identifier{Type: d.Type, ID: e.identifierID(d)})
+		}
+	case Identifiers:
+		idens = make(Identifiers, 0, len(d))
+		for _, iden := range d {
+			idens = append(idens, Identifier{Type: iden.Type, ID: e.identifierID(iden)})
+		}
+	default:
+		return NewErrMissingDataMember()
+	}
+
+	if rel.ToOne && op.Op != OpUpdate {
+		return NewErrBadRequest(
+			"Invalid operation",
+			"A to-one relationship can only be updated.",
+		)
+	}
+
+	oldIdens := relIdentifiers(parent, rel)
+	newIdens := idens
+
+	switch op.Op {
+	case OpAdd:
+		newIdens = addIdentifiers(oldIdens, idens)
+	case OpRemove:
+		newIdens = removeIdentifiers(oldIdens, idens)
+	}
+
+	err = rel.checkWrite(len(newIdens), newIdens.IDs(), false)
+	if err != nil {
+		return withPointer(err, "/data")
+	}
+
+	switch {
+	case rel.ToOne, rel.Polymorphic(), op.Op == OpUpdate:
+		err = setRel(store, op.Ref.Type, id, rel, newIdens)
+	case op.Op == OpAdd:
+		err = store.AddToMany(id, rel.FromName, idens.IDs())
+	default:
+		err = store.RemoveFromMany(id, rel.FromName, idens.IDs())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return e.h.relSync().rel(op.Ref.Type, id, rel.FromName, oldIdens, newIdens)
+}
+
+// refID returns the ID of the resource referenced by ref.
+func (e *operationExecutor) refID(ref Ref) (string, error) {
+	if ref.LID == "" {
+		return ref.ID, nil
+	}
+
+	id, ok := e.lids.ID(ref.Type, ref.LID)
+	if !ok {
+		return "", newErrUnknownLID(ref.LID)
+	}
+
+	return id, nil
+}
+
+// identifierID returns the ID of the resource identified by iden.
+//
+// The ID of iden might be a local ID, as explained in the documentation of
+// Operation.
+func (e *operationExecutor) identifierID(iden Identifier) string {
+	if iden.ID == "" {
+		iden.ID, iden.LID = iden.LID, ""
+	}
+
+	if id, ok := e.lids.ID(iden.Type, iden.ID); ok {
+		return id
+	}
+
+	return iden.ID
+}
+
+// A transaction records how to undo the changes made through the stores it
+// wraps.
+type transaction struct {
+	undo []func() error
+}
+
+// wrap returns stores where each store records its changes in t.
+func (t *transaction) wrap(stores map[string]Store) map[string]Store {
+	wrapped := make(map[string]Store, len(stores))
+
+	for typ, store := range stores {
+		if store != nil {
+			wrapped[typ] = &txStore{Store: store, tx: t}
+		}
+	}
+
+	return wrapped
+}
+
+// rollback undoes the recorded changes in reverse order.
+//
+// Undoing as many changes as possible is preferred, so errors are ignored.
+func (t *transaction) rollback() {
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		_ = t.undo[i]()
+	}
+
+	t.undo = nil
+}
+
+// txStore is a Store that records in a transaction how to undo the changes
+// made through it.
+type txStore struct {
+	Store
+	tx *transaction
+}
+
+// Insert implements the Store interface.
+func (s *txStore) Insert(res Resource) error {
+	err := s.Store.Insert(res)
+	if err != nil {
+		return err
+	}
+
+	id := res.Get("id").(string)
+	s.record(func() error { return s.Store.Delete(id) })
+
+	return nil
+}
+
+// Update implements the Store interface.
+func (s *txStore) Update(res Resource) error {
+	old, err := s.existing(res.Get("id").(string))
+	if err != nil {
+		return err
+	}
+
+	err = s.Store.Update(res)
+	if err != nil {
+		return err
+	}
+
+	s.record(func() error { return s.Store.Update(old) })
+
+	return nil
+}
+
+// Delete implements the Store interface.
+func (s *txStore) Delete(id string) error {
+	old, err := s.existing(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.Store.Delete(id)
+	if err != nil {
+		return err
+	}
+
+	s.record(func() error { return s.Store.Insert(old) })
+
+	return nil
+}
+
+// UpdateToOne implements the Store interface.
+func (s *txStore) UpdateToOne(id, rel, toID string) error {
+	old, err := s.existing(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.Store.UpdateToOne(id, rel, toID)
+	if err != nil {
+		return err
+	}
+
+	oldID, _ := old.Get(rel).(string)
+	s.record(func() error { return s.Store.UpdateToOne(id, rel, oldID) })
+
+	return nil
+}
+
+// UpdateToMany implements the Store interface.
+func (s *txStore) UpdateToMany(id, rel string, ids []string) error {
+	return s.updateMany(id, rel, func() error {
+		return s.Store.UpdateToMany(id, rel, ids)
+	})
+}
+
+// AddToMany implements the Store interface.
+func (s *txStore) AddToMany(id, rel string, ids []string) error {
+	return s.updateMany(id, rel, func() error {
+		return s.Store.AddToMany(id, rel, ids)
+	})
+}
+
+// RemoveFromMany implements the Store interface.
+func (s *txStore) RemoveFromMany(id, rel string, ids []string) error {
+	return s.updateMany(id, rel, func() error {
+		return s.Store.RemoveFromMany(id, rel, ids)
+	})
+}
+
+// updateMany calls update and records how to restore the IDs of the to-many
+// relationship rel of the resource identified by id.
+func (s *txStore) updateMany(id, rel string, update func() error) error {
+	old, err := s.existing(id)
+	if err != nil {
+		return err
+	}
+
+	err = update()
+	if err != nil {
+		return err
+	}
+
+	oldIDs, _ := old.Get(rel).([]string)
+	s.record(func() error { return s.Store.UpdateToMany(id, rel, oldIDs) })
+
+	return nil
+}
+
+// existing returns the resource identified by id or a not found error.
+func (s *txStore) existing(id string) (Resource, error) {
+	res, err := s.Store.Resource(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		return nil, NewErrNotFound()
+	}
+
+	return res, nil
+}
+
+func (s *txStore) record(undo func() error) {
+	s.tx.undo = append(s.tx.undo, undo)
+}
@@ -0,0 +1,419 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalOperations(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+
+	payload := `{
+		"atomic:operations": [{
+			"op": "add",
+			"data": {
+				"type": "users",
+				"lid": "u",
+				"attributes": {"name": "Alice"}
+			}
+		}, {
+			"op": "add",
+			"data": {
+				"type": "articles",
+				"lid": "a",
+				"attributes": {"title": "Title"},
+				"relationships": {
+					"author": {"data": {"type": "users", "lid": "u"}}
+				}
+			}
+		}, {
+			"op": "update",
+			"data": {
+				"type": "articles",
+				"lid": "a",
+				"attributes": {"title": "New"}
+			}
+		}, {
+			"op": "add",
+			"href": "/articles/a1/relationships/related",
+			"data": [{"type": "articles", "lid": "a"}]
+		}, {
+			"op": "update",
+			"ref": {"type": "articles", "lid": "a", "relationship": "author"},
+			"data": null
+		}, {
+			"op": "remove",
+			"ref": {"type": "users", "id": "u1"},
+			"meta": {"key": "value"}
+		}]
+	}`
+
+	ops, err := UnmarshalOperations([]byte(payload), schema)
+	assert.NoError(err)
+	assert.Len(ops, 6)
+
+	// Add with local ID
+	assert.Equal(OpAdd, ops[0].Op)
+	assert.Equal("u", ops[0].LID)
+	assert.Equal("Alice", ops[0].Data.(Resource).Get("name"))
+	assert.Equal("", ops[0].Data.(Resource).Get("id"))
+
+	// Relationship referencing a local ID
+	assert.Equal("a", ops[1].LID)
+	assert.Equal("u", ops[1].Data.(Resource).Get("author"))
+
+	// Update by local ID
+	assert.Equal(Ref{Type: "articles", LID: "a"}, ops[2].Ref)
+	assert.Equal("New", ops[2].Data.(Resource).Get("title"))
+	assert.Len(ops[2].Data.(Resource).Attrs(), 1)
+
+	// Relationship from href
+	assert.Equal(
+		Ref{Type: "articles", ID: "a1", Relationship: "related"},
+		ops[3].Ref,
+	)
+	assert.Equal(NewIdentifiers("articles", []string{"a"}), ops[3].Data)
+
+	// To-one relationship set to null
+	assert.Equal(
+		Ref{Type: "articles", LID: "a", Relationship: "author"},
+		ops[4].Ref,
+	)
+	assert.Equal(Identifier{}, ops[4].Data)
+
+	// Remove
+	assert.Equal(Ref{Type: "users", ID: "u1"}, ops[5].Ref)
+	assert.Nil(ops[5].Data)
+	assert.Equal(Meta{"key": "value"}, ops[5].Meta)
+
+	// Marshaling
+	pl, err := MarshalOperations(ops)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"atomic:operations": [{
+			"op": "add",
+			"data": {
+				"type": "users",
+				"lid": "u",
+				"attributes": {"name": "Alice"},
+				"relationships": {"articles": {"data": []}}
+			}
+		}, {
+			"op": "add",
+			"data": {
+				"type": "articles",
+				"lid": "a",
+				"attributes": {"title": "Title"},
+				"relationships": {
+					"author": {"data": {"type": "users", "lid": "u"}},
+					"related": {"data": []}
+				}
+			}
+		}, {
+			"op": "update",
+			"ref": {"type": "articles", "lid": "a"},
+			"data": {
+				"type": "articles",
+				"lid": "a",
+				"attributes": {"title": "New"}
+			}
+		}, {
+			"op": "add",
+			"ref": {"type": "articles", "id": "a1", "relationship": "related"},
+			"data": [{"type": "articles", "lid": "a"}]
+		}, {
+			"op": "update",
+			"ref": {"type": "articles", "lid": "a", "relationship": "author"},
+			"data": null
+		}, {
+			"op": "remove",
+			"ref": {"type": "users", "id": "u1"},
+			"meta": {"key": "value"}
+		}]
+	}`, string(pl))
+}
+
+func TestUnmarshalOperationsInvalid(t *testing.T) {
+	schema := newHandlerSchema()
+
+	tests := []struct {
+		name    string
+		payload string
+		pointer string
+	}{
+		{
+			name:    "invalid json",
+			payload: `{`,
+		}, {
+			name:    "no operations",
+			payload: `{"atomic:operations":[]}`,
+			pointer: "",
+		}, {
+			name:    "invalid operation code",
+			payload: `{"atomic:operations":[{"op":"replace"}]}`,
+			pointer: "/atomic:operations/0/op",
+		}, {
+			name: "ref and href",
+			payload: `{"atomic:operations":[{
+				"op":"remove",
+				"ref":{"type":"users","id":"u1"},
+				"href":"/users/u1"
+			}]}`,
+			pointer: "/atomic:operations/0/href",
+		}, {
+			name:    "unknown type in ref",
+			payload: `{"atomic:operations":[{"op":"remove","ref":{"type":"unknown","id":"1"}}]}`,
+			pointer: "/atomic:operations/0/ref",
+		}, {
+			name: "unknown relationship",
+			payload: `{"atomic:operations":[{
+				"op":"update",
+				"href":"/users/u1/relationships/unknown"
+			}]}`,
+			pointer: "/atomic:operations/0/href",
+		}, {
+			name:    "unknown local id in ref",
+			payload: `{"atomic:operations":[{"op":"remove","ref":{"type":"users","lid":"u"}}]}`,
+			pointer: "/atomic:operations/0/ref/lid",
+		}, {
+			name: "unknown local id in relationship",
+			payload: `{"atomic:operations":[{
+				"op":"add",
+				"data":{
+					"type":"articles",
+					"relationships":{"author":{"data":{"type":"users","lid":"u"}}}
+				}
+			}]}`,
+			pointer: "/atomic:operations/0/data/relationships/author/data",
+		}, {
+			name: "duplicate local id",
+			payload: `{"atomic:operations":[
+				{"op":"add","data":{"type":"users","lid":"u"}},
+				{"op":"add","data":{"type":"users","lid":"u"}}
+			]}`,
+			pointer: "/atomic:operations/1/data/lid",
+		}, {
+			name:    "add without data",
+			payload: `{"atomic:operations":[{"op":"add"}]}`,
+			pointer: "/atomic:operations/0/data",
+		}, {
+			name:    "unknown type in data",
+			payload: `{"atomic:operations":[{"op":"add","data":{"type":"unknown"}}]}`,
+			pointer: "/atomic:operations/0/data/type",
+		}, {
+			name:    "update without id",
+			payload: `{"atomic:operations":[{"op":"update","data":{"type":"users"}}]}`,
+			pointer: "/atomic:operations/0/data",
+		}, {
+			name: "update not matching ref",
+			payload: `{"atomic:operations":[{
+				"op":"update",
+				"ref":{"type":"users","id":"u2"},
+				"data":{"type":"users","id":"u1"}
+			}]}`,
+			pointer: "/atomic:operations/0/data",
+		}, {
+			name:    "remove without ref",
+			payload: `{"atomic:operations":[{"op":"remove"}]}`,
+			pointer: "/atomic:operations/0/ref",
+		}, {
+			name: "add to to-one relationship",
+			payload: `{"atomic:operations":[{
+				"op":"add",
+				"ref":{"type":"articles","id":"a1","relationship":"author"},
+				"data":{"type":"users","id":"u1"}
+			}]}`,
+			pointer: "/atomic:operations/0/op",
+		}, {
+			name: "wrong type in linkage",
+			payload: `{"atomic:operations":[{
+				"op":"add",
+				"ref":{"type":"articles","id":"a1","relationship":"related"},
+				"data":[{"type":"users","id":"u1"}]
+			}]}`,
+			pointer: "/atomic:operations/0/data",
+		},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		_, err := UnmarshalOperations([]byte(test.payload), schema)
+		assert.Error(err, test.name)
+
+		e, ok := err.(Error)
+		assert.True(ok, test.name)
+
+		if test.pointer != "" || test.name == "no operations" {
+			assert.Equal(test.pointer, e.Source["pointer"], test.name)
+		}
+	}
+}
+
+func TestOperationResults(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+
+	typ := schema.GetType("users")
+	res := typ.New()
+	res.Set("id", "u1")
+	res.Set("name", "Alice")
+	res.Set("articles", []string{"a1"})
+
+	results := []OperationResult{
+		{Data: res},
+		{Meta: Meta{"key": "value"}},
+	}
+
+	pl, err := MarshalOperationResults(results, "https://example.org")
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"atomic:results": [{
+			"data": {
+				"type": "users",
+				"id": "u1",
+				"attributes": {"name": "Alice"},
+				"relationships": {
+					"articles": {
+						"data": [{"type": "articles", "id": "a1"}],
+						"links": {
+							"related": "https://example.org/users/u1/articles",
+							"self": "https://example.org/users/u1/relationships/articles"
+						}
+					}
+				},
+				"links": {"self": "https://example.org/users/u1"}
+			}
+		}, {
+			"meta": {"key": "value"}
+		}],
+		"jsonapi": {
+			"version": "1.1",
+			"ext": ["https://jsonapi.org/ext/atomic"]
+		}
+	}`, string(pl))
+
+	results2, err := UnmarshalOperationResults(pl, schema)
+	assert.NoError(err)
+	assert.Len(results2, 2)
+	assert.True(Equal(res, results2[0].Data))
+	assert.Nil(results2[1].Data)
+	assert.Equal(Meta{"key": "value"}, results2[1].Meta)
+
+	// Unknown type
+	_, err = UnmarshalOperationResults(
+		[]byte(`{"atomic:results":[{"data":{"type":"unknown","id":"1"}}]}`),
+		schema,
+	)
+	assert.Error(err)
+}
+
+func TestHandlerExecuteOperations(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+
+	stores := map[string]Store{}
+	for i := range schema.Types {
+		stores[schema.Types[i].Name] = NewMemoryStore(schema.Types[i])
+	}
+
+	handler := NewHandler(schema, stores)
+
+	n := 0
+	handler.NewID = func() string {
+		n++
+		return []string{"", "id1", "id2", "id3"}[n]
+	}
+
+	ops, err := UnmarshalOperations([]byte(`{
+		"atomic:operations": [{
+			"op": "add",
+			"data": {"type": "users", "lid": "u", "attributes": {"name": "Alice"}}
+		}, {
+			"op": "add",
+			"data": {
+				"type": "articles",
+				"lid": "a",
+				"attributes": {"title": "Title"},
+				"relationships": {
+					"author": {"data": {"type": "users", "lid": "u"}}
+				}
+			}
+		}, {
+			"op": "update",
+			"data": {"type": "articles", "lid": "a", "attributes": {"title": "New"}}
+		}, {
+			"op": "add",
+			"ref": {"type": "articles", "lid": "a", "relationship": "related"},
+			"data": [{"type": "articles", "lid": "a"}]
+		}]
+	}`), schema)
+	assert.NoError(err)
+
+	results, err := handler.ExecuteOperations(ops)
+	assert.NoError(err)
+	assert.Len(results, 4)
+
+	assert.Equal("id1", results[0].Data.Get("id"))
+	assert.Equal("id2", results[1].Data.Get("id"))
+	assert.Equal("id1", results[1].Data.Get("author"))
+	assert.Equal("New", results[2].Data.Get("title"))
+	assert.Nil(results[3].Data)
+
+	user, _ := stores["users"].Resource("id1")
+	assert.Equal([]string{"id2"}, user.Get("articles"))
+
+	article, _ := stores["articles"].Resource("id2")
+	assert.Equal([]string{"id2"}, article.Get("related"))
+
+	// Rollback
+	ops, err = UnmarshalOperations([]byte(`{
+		"atomic:operations": [{
+			"op": "add",
+			"data": {"type": "users", "id": "u2", "attributes": {"name": "Bob"}}
+		}, {
+			"op": "update",
+			"data": {
+				"type": "articles",
+				"id": "id2",
+				"attributes": {"title": "Newer"},
+				"relationships": {
+					"author": {"data": {"type": "users", "id": "u2"}}
+				}
+			}
+		}, {
+			"op": "remove",
+			"ref": {"type": "articles", "id": "id2"}
+		}, {
+			"op": "remove",
+			"ref": {"type": "articles", "id": "unknown"}
+		}]
+	}`), schema)
+	assert.NoError(err)
+
+	_, err = handler.ExecuteOperations(ops)
+	assert.Error(err)
+
+	e, ok := err.(Error)
+	assert.True(ok)
+	assert.Equal("404", e.Status)
+	assert.Equal("/atomic:operations/3", e.Source["pointer"])
+
+	bob, _ := stores["users"].Resource("u2")
+	assert.Nil(bob)
+
+	article, _ = stores["articles"].Resource("id2")
+	assert.NotNil(article)
+	assert.Equal("New", article.Get("title"))
+	assert.Equal("id1", article.Get("author"))
+
+	user, _ = stores["users"].Resource("id1")
+	assert.Equal([]string{"id2"}, user.Get("articles"))
+}
@@ -0,0 +1,268 @@
+package jsonapi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// AttrTypeCustom is the value of the first attribute type registered with
+// RegisterAttrType. The following ones get the next values.
+const AttrTypeCustom = 1 << 10
+
+// An AttrCodec defines an attribute type that is not built in, like an enum, a
+// UUID, an amount of money or a geographic point.
+//
+// The Go type of the values is the type of the value returned by Zero, which
+// cannot be a pointer. Nullable attributes hold pointers to values of that
+// type. The methods are only given values of that type.
+type AttrCodec interface {
+	// Zero returns the zero value.
+	Zero() any
+
+	// MarshalAttr returns the JSON encoding of v.
+	MarshalAttr(v any) ([]byte, error)
+
+	// UnmarshalAttr returns the value encoded in data.
+	UnmarshalAttr(data []byte) (any, error)
+
+	// CompareAttr returns -1, 0, or +1 depending on whether v is less than,
+	// equal to, or greater than v2. It is used by filters and for sorting.
+	CompareAttr(v, v2 any) int
+
+	// ValidateAttr returns an error if v is not an acceptable value. It is
+	// called on the values read from payloads.
+	ValidateAttr(v any) error
+}
+
+// An AttrSchemaCodec is an AttrCodec that also describes the JSON values of its
+// attribute type. The schema is used by Type.JSONSchema, Type.Validate and
+// OpenAPI, which otherwise accept any value for attributes of that type.
+type AttrSchemaCodec interface {
+	AttrCodec
+
+	// AttrSchema returns the schema of the values produced by MarshalAttr,
+	// like {"type": "string", "format": "uuid"}. Since it is also found in
+	// OpenAPI documents, it should only use the keywords supported by both
+	// JSON Schema and OpenAPI 3.0.
+	AttrSchema() map[string]any
+}
+
+// RegisterAttrType registers an attribute type named name whose values are
+// handled by codec and returns its value (see AttrTypeCustom).
+//
+// Once registered, the name and the Go type of the values are recognized by
+// GetAttrType, which means struct fields of that Go type can be attributes
+// of the types built by BuildType and the resources made by Wrap.
+//
+// An error is returned if the name or the Go type is already used by another
+// attribute type.
+func RegisterAttrType(name string, codec AttrCodec) (int, error) {
+	if name == "" || strings.HasPrefix(name, "*") {
+		return AttrTypeInvalid, fmt.Errorf("jsonapi: attribute type name %q is invalid", name)
+	}
+
+	goType := reflect.TypeOf(codec.Zero())
+	if goType == nil || goType.Kind() == reflect.Ptr {
+		return AttrTypeInvalid, fmt.Errorf(
+			"jsonapi: zero value of attribute type %q is nil or a pointer",
+			name,
+		)
+	}
+
+	// Built-in types
+	for _, t := range []string{name, goType.String()} {
+		if typ, _ := GetAttrType(t); typ != AttrTypeInvalid {
+			return AttrTypeInvalid, fmt.Errorf("jsonapi: attribute type %q already exists", t)
+		}
+	}
+
+	return attrTypes.add(customAttrType{
+		name:   name,
+		goType: goType,
+		codec:  codec,
+	})
+}
+
+// GetAttrCodec returns the codec of the attribute type typ, or nil if typ was
+// not registered with RegisterAttrType.
+func GetAttrCodec(typ int) AttrCodec {
+	ct, ok := attrTypes.get(typ)
+	if !ok {
+		return nil
+	}
+
+	return ct.codec
+}
+
+// attrTypes holds the attribute types registered with RegisterAttrType.
+var attrTypes = &attrTypeRegistry{} //nolint:gochecknoglobals
+
+// An attrTypeRegistry is a list of registered attribute types, where the
+// index of a type is its value minus AttrTypeCustom.
+type attrTypeRegistry struct {
+	mu    sync.RWMutex
+	types []customAttrType
+}
+
+// customAttrType is an attribute type registered with RegisterAttrType.
+type customAttrType struct {
+	name   string
+	goType reflect.Type
+	codec  AttrCodec
+}
+
+// add registers ct and returns its value.
+func (r *attrTypeRegistry) add(ct customAttrType) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The names are checked again in case another type was registered
+	// concurrently.
+	for _, t := range r.types {
+		for _, name := range []string{ct.name, ct.goType.String()} {
+			if t.name == name || t.goType.String() == name {
+				return AttrTypeInvalid, fmt.Errorf(
+					"jsonapi: attribute type %q already exists",
+					name,
+				)
+			}
+		}
+	}
+
+	r.types = append(r.types, ct)
+
+	return AttrTypeCustom + len(r.types) - 1, nil
+}
+
+// get returns the attribute type whose value is typ.
+func (r *attrTypeRegistry) get(typ int) (customAttrType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i := typ - AttrTypeCustom
+	if i < 0 || i >= len(r.types) {
+		return customAttrType{}, false
+	}
+
+	return r.types[i], true
+}
+
+// find returns the value of the attribute type named t or whose values are of
+// the Go type named t, or AttrTypeInvalid if there is none.
+func (r *attrTypeRegistry) find(t string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i, ct := range r.types {
+		if ct.name == t || ct.goType.String() == t {
+			return AttrTypeCustom + i
+		}
+	}
+
+	return AttrTypeInvalid
+}
+
+// customValue returns the registered attribute type of v, which is a value of
+// that type or a pointer to one, and the value itself. null is true if v is
+// nil. ok is false if v is not of a registered type.
+func customValue(v any) (ct customAttrType, val any, null, ok bool) {
+	if v == nil {
+		return customAttrType{}, nil, true, false
+	}
+
+	rv := reflect.ValueOf(v)
+	nullable := rv.Kind() == reflect.Ptr
+	name := rv.Type().String()
+
+	ct, ok = attrTypes.get(attrTypes.find(strings.TrimPrefix(name, "*")))
+	if !ok {
+		return customAttrType{}, nil, false, false
+	}
+
+	if !nullable {
+		return ct, v, false, true
+	}
+
+	if rv.IsNil() {
+		return ct, nil, true, true
+	}
+
+	return ct, rv.Elem().Interface(), false, true
+}
+
+// customAttrValue is a value of a registered attribute type that is marshaled
+// with the type's codec.
+type customAttrValue struct {
+	codec AttrCodec
+	val   any
+}
+
+// MarshalJSON marshals the value with the codec.
+func (c customAttrValue) MarshalJSON() ([]byte, error) {
+	return c.codec.MarshalAttr(c.val)
+}
+
+// marshalableAttr returns v, the value of attr, in a form that json.Marshal
+// encodes correctly.
+func marshalableAttr(attr Attr, v any) any {
+	if attr.Type < AttrTypeCustom {
+		return v
+	}
+
+	ct, val, null, ok := customValue(v)
+	if !ok || null {
+		return v
+	}
+
+	return customAttrValue{codec: ct.codec, val: val}
+}
+
+// unmarshalCustomAttr unmarshals data into a value of the registered attribute
+// type typ, or a pointer to one if nullable is true.
+func unmarshalCustomAttr(typ int, nullable bool, data []byte) (any, error) {
+	ct, ok := attrTypes.get(typ)
+	if !ok {
+		return nil, errors.New("attribute is of invalid or unknown type")
+	}
+
+	v, err := ct.codec.UnmarshalAttr(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if reflect.TypeOf(v) != ct.goType {
+		return nil, fmt.Errorf("attribute value is not of type %q", ct.goType)
+	}
+
+	err = ct.codec.ValidateAttr(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullable {
+		p := reflect.New(ct.goType)
+		p.Elem().Set(reflect.ValueOf(v))
+
+		return p.Interface(), nil
+	}
+
+	return v, nil
+}
+
+// customZeroValue returns the zero value of the registered attribute type typ,
+// or a nil pointer of the right type if nullable is true.
+func customZeroValue(typ int, nullable bool) any {
+	ct, ok := attrTypes.get(typ)
+	if !ok {
+		return nil
+	}
+
+	if nullable {
+		return reflect.Zero(reflect.PtrTo(ct.goType)).Interface()
+	}
+
+	return ct.codec.Zero()
+}
@@ -0,0 +1,415 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterAttrType(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := registerColor()
+	assert.GreaterOrEqual(typ, AttrTypeCustom)
+	assert.Equal(colorCodec{}, GetAttrCodec(typ))
+	assert.Nil(GetAttrCodec(AttrTypeString))
+	assert.Nil(GetAttrCodec(AttrTypeCustom + 999))
+
+	// Errors
+	_, err := RegisterAttrType("", colorCodec{})
+	assert.EqualError(err, `jsonapi: attribute type name "" is invalid`)
+
+	_, err = RegisterAttrType("*color", colorCodec{})
+	assert.EqualError(err, `jsonapi: attribute type name "*color" is invalid`)
+
+	_, err = RegisterAttrType("colorptr", colorPtrCodec{})
+	assert.EqualError(err, `jsonapi: zero value of attribute type "colorptr" is nil or a pointer`)
+
+	_, err = RegisterAttrType("int", colorCodec{})
+	assert.EqualError(err, `jsonapi: attribute type "int" already exists`)
+
+	_, err = RegisterAttrType("other", stringCodec{})
+	assert.EqualError(err, `jsonapi: attribute type "string" already exists`)
+
+	_, err = RegisterAttrType("color", stringCodec{})
+	assert.EqualError(err, `jsonapi: attribute type "color" already exists`)
+
+	_, err = RegisterAttrType("colour", colorCodec{})
+	assert.EqualError(err, `jsonapi: attribute type "jsonapi_test.color" already exists`)
+}
+
+func TestCustomAttrType(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := registerColor()
+
+	// Names
+	for _, name := range []string{"color", "jsonapi_test.color"} {
+		ty, null := GetAttrType(name)
+		assert.Equal(typ, ty)
+		assert.False(null)
+
+		ty, null = GetAttrType("*" + name)
+		assert.Equal(typ, ty)
+		assert.True(null)
+	}
+
+	assert.Equal("color", GetAttrTypeString(typ, false))
+	assert.Equal("*color", GetAttrTypeString(typ, true))
+
+	// Zero values
+	assert.Equal(colorRed, GetZeroValue(typ, false))
+	assert.Equal((*color)(nil), GetZeroValue(typ, true))
+
+	// Unmarshaling
+	attr := Attr{Name: "color", Type: typ}
+
+	v, err := attr.UnmarshalToType([]byte(`"green"`))
+	assert.NoError(err)
+	assert.Equal(colorGreen, v)
+
+	_, err = attr.UnmarshalToType([]byte(`"purple"`))
+	assert.EqualError(err, "400 Bad Request: The field value is invalid for the expected type.")
+
+	// Values that can be decoded but are rejected by the validation.
+	_, err = attr.UnmarshalToType([]byte(`7`))
+	assert.Error(err)
+
+	attr.Nullable = true
+
+	v, err = attr.UnmarshalToType([]byte(`"blue"`))
+	assert.NoError(err)
+	assert.Equal(colorPtr(colorBlue), v)
+
+	v, err = attr.UnmarshalToType([]byte(`null`))
+	assert.NoError(err)
+	assert.Equal((*color)(nil), v)
+}
+
+func TestCustomAttrTypeResources(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := registerColor()
+
+	// Wrapper
+	res := &paint{ID: "p1", Color: colorBlue}
+	assert.NoError(Check(*res))
+
+	wrap := Wrap(res)
+	assert.Equal(colorBlue, wrap.Get("color"))
+	assert.Nil(wrap.Get("color2"))
+
+	schema := &Schema{}
+	_ = schema.AddType(MustBuildType(paint{}))
+
+	url, _ := NewURLFromRaw(schema, "/paints/p1")
+
+	payload, err := MarshalDocument(&Document{Data: wrap}, url)
+	assert.NoError(err)
+	assert.Contains(string(payload), `"color":"blue"`)
+	assert.Contains(string(payload), `"color2":null`)
+
+	doc, err := UnmarshalDocument(payload, schema)
+	assert.NoError(err)
+	assert.True(Equal(wrap, doc.Data.(Resource)))
+
+	// SoftResource
+	sr := &SoftResource{Type: &Type{Name: "paints"}}
+	sr.AddAttr(Attr{Name: "color", Type: typ})
+	sr.AddAttr(Attr{Name: "color2", Type: typ, Nullable: true})
+	assert.Equal(colorRed, sr.Get("color"))
+	assert.Equal((*color)(nil), sr.Get("color2"))
+
+	sr.Set("color", colorGreen)
+	sr.Set("color2", colorPtr(colorBlue))
+	assert.Equal(colorGreen, sr.Get("color"))
+
+	sr2 := sr.Copy()
+	assert.True(Equal(sr, sr2))
+
+	*sr2.Get("color2").(*color) = colorRed
+	assert.Equal(colorPtr(colorBlue), sr.Get("color2"))
+}
+
+func TestCustomAttrTypeSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	colorType := registerColor()
+	labelType := registerLabel()
+
+	typ := Type{Name: "paints"}
+	_ = typ.AddAttr(Attr{Name: "color", Type: colorType})
+	_ = typ.AddAttr(Attr{Name: "label", Type: labelType})
+	_ = typ.AddAttr(Attr{Name: "label2", Type: labelType, Nullable: true})
+
+	// JSON Schema
+	attrs := typ.JSONSchema()["properties"].(map[string]any)["attributes"].(map[string]any)
+	attrs = attrs["properties"].(map[string]any)
+
+	// Without a schema from the codec, any value is accepted.
+	assert.Equal(map[string]any{}, attrs["color"])
+	assert.Equal(map[string]any{"type": "string", "minLength": 1}, attrs["label"])
+	assert.Equal(map[string]any{
+		"type":      []string{"string", "null"},
+		"minLength": 1,
+	}, attrs["label2"])
+
+	errs := typ.Validate([]byte(`{
+		"data": {
+			"type": "paints",
+			"attributes": {"color": [], "label": "", "label2": 1}
+		}
+	}`))
+
+	pointers := []string{}
+	for _, err := range errs {
+		pointers = append(pointers, err.Source["pointer"].(string))
+	}
+
+	assert.Equal([]string{"/data/attributes/label", "/data/attributes/label2"}, pointers)
+
+	// OpenAPI
+	schema := &Schema{}
+	_ = schema.AddType(typ)
+
+	doc := OpenAPI(schema, OpenAPIInfo{})
+	res := doc["components"].(map[string]any)["schemas"].(map[string]any)["paints"]
+	attrs = res.(map[string]any)["properties"].(map[string]any)["attributes"].(map[string]any)
+	attrs = attrs["properties"].(map[string]any)
+
+	assert.Equal(map[string]any{}, attrs["color"])
+	assert.Equal(map[string]any{
+		"type":      "string",
+		"minLength": 1,
+		"nullable":  true,
+	}, attrs["label2"])
+}
+
+func TestCustomAttrTypeFilterAndSort(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := registerColor()
+
+	colors := []any{colorBlue, colorRed, colorGreen}
+	colorPtrs := []any{colorPtr(colorGreen), (*color)(nil), colorPtr(colorRed)}
+
+	col := &Resources{}
+
+	for i := range colors {
+		sr := &SoftResource{Type: &Type{Name: "paints"}}
+		sr.SetID([]string{"p0", "p1", "p2"}[i])
+		sr.AddAttr(Attr{Name: "color", Type: typ})
+		sr.AddAttr(Attr{Name: "color2", Type: typ, Nullable: true})
+		sr.Set("color", colors[i])
+		sr.Set("color2", colorPtrs[i])
+		col.Add(sr)
+	}
+
+	ids := func(c Collection) []string {
+		ids := []string{}
+		for i := 0; i < c.Len(); i++ {
+			ids = append(ids, c.At(i).Get("id").(string))
+		}
+
+		return ids
+	}
+
+	// Sort
+	assert.Equal(
+		[]string{"p1", "p2", "p0"},
+		ids(Range(col, nil, nil, []string{"color"}, 10, 0)),
+	)
+	assert.Equal(
+		[]string{"p0", "p2", "p1"},
+		ids(Range(col, nil, nil, []string{"-color"}, 10, 0)),
+	)
+	assert.Equal(
+		[]string{"p1", "p2", "p0"},
+		ids(Range(col, nil, nil, []string{"color2"}, 10, 0)),
+	)
+
+	// Filter
+	tests := []struct {
+		field       string
+		op          string
+		val         any
+		expectedIDs []string
+	}{
+		{field: "color", op: "=", val: colorGreen, expectedIDs: []string{"p2"}},
+		{field: "color", op: "!=", val: colorGreen, expectedIDs: []string{"p0", "p1"}},
+		{field: "color", op: ">", val: colorRed, expectedIDs: []string{"p0", "p2"}},
+		{field: "color", op: "<=", val: colorGreen, expectedIDs: []string{"p1", "p2"}},
+		{field: "color", op: "=", val: "green", expectedIDs: []string{}},
+		{field: "color2", op: "=", val: (*color)(nil), expectedIDs: []string{"p1"}},
+		{field: "color2", op: "!=", val: (*color)(nil), expectedIDs: []string{"p0", "p2"}},
+		{field: "color2", op: "<", val: colorPtr(colorGreen), expectedIDs: []string{"p2"}},
+		{field: "color2", op: ">", val: (*color)(nil), expectedIDs: []string{}},
+	}
+
+	for _, test := range tests {
+		filter := &Filter{Field: test.field, Op: test.op, Val: test.val}
+		assert.Equal(
+			test.expectedIDs,
+			ids(Range(col, nil, filter, []string{"id"}, 10, 0)),
+			test.field+" "+test.op,
+		)
+	}
+}
+
+// registerColor registers the color attribute type if it is not already
+// registered and returns its value.
+func registerColor() int {
+	if typ, _ := GetAttrType("color"); typ != AttrTypeInvalid {
+		return typ
+	}
+
+	typ, err := RegisterAttrType("color", colorCodec{})
+	if err != nil {
+		panic(err)
+	}
+
+	return typ
+}
+
+type color int
+
+const (
+	colorRed color = iota
+	colorGreen
+	colorBlue
+)
+
+func colorPtr(c color) *color {
+	return &c
+}
+
+func colorNames() []string {
+	return []string{"red", "green", "blue"}
+}
+
+// colorCodec encodes colors with their names.
+type colorCodec struct{}
+
+func (colorCodec) Zero() any {
+	return colorRed
+}
+
+func (colorCodec) MarshalAttr(v any) ([]byte, error) {
+	return json.Marshal(colorNames()[v.(color)])
+}
+
+func (colorCodec) UnmarshalAttr(data []byte) (any, error) {
+	// Numbers are accepted as is and checked by ValidateAttr.
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		return color(n), nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	for i, name := range colorNames() {
+		if name == s {
+			return color(i), nil
+		}
+	}
+
+	return nil, errors.New("unknown color")
+}
+
+func (colorCodec) CompareAttr(v, v2 any) int {
+	switch c, c2 := v.(color), v2.(color); {
+	case c < c2:
+		return -1
+	case c > c2:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (colorCodec) ValidateAttr(v any) error {
+	if c := v.(color); c < colorRed || c > colorBlue {
+		return errors.New("unknown color")
+	}
+
+	return nil
+}
+
+// colorPtrCodec is invalid because its values are pointers.
+type colorPtrCodec struct{ colorCodec }
+
+func (colorPtrCodec) Zero() any {
+	return colorPtr(colorRed)
+}
+
+// stringCodec is invalid because its Go type is a built-in one.
+type stringCodec struct{ colorCodec }
+
+func (stringCodec) Zero() any {
+	return ""
+}
+
+// registerLabel registers the label attribute type, whose codec describes its
+// values with a schema, if it is not already registered and returns its value.
+func registerLabel() int {
+	if typ, _ := GetAttrType("label"); typ != AttrTypeInvalid {
+		return typ
+	}
+
+	typ, err := RegisterAttrType("label", labelCodec{})
+	if err != nil {
+		panic(err)
+	}
+
+	return typ
+}
+
+type label string
+
+// labelCodec encodes labels as non-empty strings.
+type labelCodec struct{}
+
+func (labelCodec) Zero() any {
+	return label("")
+}
+
+func (labelCodec) MarshalAttr(v any) ([]byte, error) {
+	return json.Marshal(string(v.(label)))
+}
+
+func (labelCodec) UnmarshalAttr(data []byte) (any, error) {
+	var s string
+	err := json.Unmarshal(data, &s)
+
+	return label(s), err
+}
+
+func (labelCodec) CompareAttr(v, v2 any) int {
+	return strings.Compare(string(v.(label)), string(v2.(label)))
+}
+
+func (labelCodec) ValidateAttr(v any) error {
+	if v.(label) == "" {
+		return errors.New("empty label")
+	}
+
+	return nil
+}
+
+func (labelCodec) AttrSchema() map[string]any {
+	return map[string]any{"type": "string", "minLength": 1}
+}
+
+type paint struct {
+	ID     string `json:"id" api:"paints"`
+	Color  color  `json:"color" api:"attr"`
+	Color2 *color `json:"color2" api:"attr"`
+}
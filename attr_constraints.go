@@ -0,0 +1,273 @@
+package jsonapi
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CheckValue checks v, a value of the attribute, against the constraints of the
+// attribute that are about values: Min, Max, MinLength, MaxLength, Pattern and
+// Enum. It returns an Error (see NewErrConstraintViolationInBody) for the first
+// constraint that is not satisfied, or nil.
+//
+// Null values always satisfy those constraints.
+func (a Attr) CheckValue(v any) error {
+	v, ok := derefAttrValue(v)
+	if !ok {
+		return nil
+	}
+
+	if n, ok := attrNumber(v); ok {
+		if a.Min != nil && n.Cmp(ratFromFloat(*a.Min)) < 0 {
+			return NewErrConstraintViolationInBody(a.Name, "min",
+				fmt.Sprintf("%q must be at least %v.", a.Name, *a.Min))
+		}
+
+		if a.Max != nil && n.Cmp(ratFromFloat(*a.Max)) > 0 {
+			return NewErrConstraintViolationInBody(a.Name, "max",
+				fmt.Sprintf("%q must be at most %v.", a.Name, *a.Max))
+		}
+	}
+
+	if l, ok := attrLength(v); ok {
+		if a.MinLength != nil && l < *a.MinLength {
+			return NewErrConstraintViolationInBody(a.Name, "minlen",
+				fmt.Sprintf("%q must have a length of at least %d.", a.Name, *a.MinLength))
+		}
+
+		if a.MaxLength != nil && l > *a.MaxLength {
+			return NewErrConstraintViolationInBody(a.Name, "maxlen",
+				fmt.Sprintf("%q must have a length of at most %d.", a.Name, *a.MaxLength))
+		}
+	}
+
+	if s, ok := v.(string); ok && a.Pattern != "" {
+		// An invalid pattern is rejected by Type.AddAttr and BuildType,
+		// so the error is ignored. No value matches such a pattern.
+		if matched, _ := regexp.MatchString(a.Pattern, s); !matched {
+			return NewErrConstraintViolationInBody(a.Name, "pattern",
+				fmt.Sprintf("%q must match the pattern %q.", a.Name, a.Pattern))
+		}
+	}
+
+	if len(a.Enum) > 0 {
+		s := enumValue(v)
+
+		for _, e := range a.Enum {
+			if e == s {
+				return nil
+			}
+		}
+
+		return NewErrConstraintViolationInBody(a.Name, "enum",
+			fmt.Sprintf("%q must be one of %s.", a.Name, strings.Join(a.Enum, ", ")))
+	}
+
+	return nil
+}
+
+// checkWrite checks that the attribute can be set in a payload, v being the
+// value found in it. create is true if the payload represents a new resource.
+func (a Attr) checkWrite(v any, create bool) error {
+	switch {
+	case a.ReadOnly:
+		return NewErrConstraintViolationInBody(a.Name, "readonly",
+			fmt.Sprintf("%q is read-only.", a.Name))
+	case a.WriteOnce && !create:
+		return NewErrConstraintViolationInBody(a.Name, "writeonce",
+			fmt.Sprintf("%q cannot be changed once the resource is created.", a.Name))
+	}
+
+	if _, ok := derefAttrValue(v); !ok && a.Required {
+		return NewErrConstraintViolationInBody(a.Name, "required",
+			fmt.Sprintf("%q is required.", a.Name))
+	}
+
+	return a.CheckValue(v)
+}
+
+// copy returns a copy of the attribute that does not share its constraints.
+func (a Attr) copy() Attr {
+	for _, p := range []**float64{&a.Min, &a.Max} {
+		if *p != nil {
+			f := **p
+			*p = &f
+		}
+	}
+
+	for _, p := range []**int{&a.MinLength, &a.MaxLength} {
+		if *p != nil {
+			l := **p
+			*p = &l
+		}
+	}
+
+	if a.Enum != nil {
+		a.Enum = append([]string{}, a.Enum...)
+	}
+
+	return a
+}
+
+// parseAttrTag reads the options of the api tag of an attribute, like
+// "attr,required,max=10", and the pattern tag, and sets the corresponding
+// constraints on attr.
+func parseAttrTag(attr *Attr, sf reflect.StructField) error {
+	opts := strings.Split(sf.Tag.Get("api"), ",")[1:]
+
+	for _, opt := range opts {
+		var (
+			name, val = opt, ""
+			err       error
+		)
+
+		if i := strings.Index(opt, "="); i >= 0 {
+			name, val = opt[:i], opt[i+1:]
+		}
+
+		switch name {
+		case "required":
+			attr.Required = true
+		case "readonly":
+			attr.ReadOnly = true
+		case "writeonce":
+			attr.WriteOnce = true
+		case "min", "max":
+			var f float64
+
+			f, err = strconv.ParseFloat(val, 64)
+			if name == "min" {
+				attr.Min = &f
+			} else {
+				attr.Max = &f
+			}
+		case "minlen", "maxlen":
+			var l int
+
+			l, err = strconv.Atoi(val)
+			if name == "minlen" {
+				attr.MinLength = &l
+			} else {
+				attr.MaxLength = &l
+			}
+		case "enum":
+			attr.Enum = strings.Split(val, "|")
+		default:
+			err = fmt.Errorf("unknown option %q", opt)
+		}
+
+		if err != nil {
+			return fmt.Errorf("jsonapi: api tag of attribute %q is invalid: %s", sf.Name, err)
+		}
+	}
+
+	attr.Pattern = sf.Tag.Get("pattern")
+
+	if _, err := regexp.Compile(attr.Pattern); err != nil {
+		return fmt.Errorf("jsonapi: pattern of attribute %q is invalid", sf.Name)
+	}
+
+	return nil
+}
+
+// derefAttrValue returns the value pointed to by v if it is a pointer. ok is
+// false if v is nil.
+func derefAttrValue(v any) (any, bool) {
+	if v == nil {
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return v, true
+	}
+
+	if rv.IsNil() {
+		return nil, false
+	}
+
+	return rv.Elem().Interface(), true
+}
+
+// attrNumber returns the value of v if it is a number.
+func attrNumber(v any) (*big.Rat, bool) {
+	switch v := v.(type) {
+	case int:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int8:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int16:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case uint:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint8:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint16:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint32:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint64:
+		return new(big.Rat).SetUint64(v), true
+	case float32:
+		return ratFromFloat(float64(v)), true
+	case float64:
+		return ratFromFloat(v), true
+	case Decimal:
+		return v.rat(), true
+	default:
+		return nil, false
+	}
+}
+
+// ratFromFloat returns f as a rational number. Infinities are replaced by the
+// largest finite numbers and NaN by 0.
+func ratFromFloat(f float64) *big.Rat {
+	switch {
+	case math.IsInf(f, 1):
+		f = math.MaxFloat64
+	case math.IsInf(f, -1):
+		f = -math.MaxFloat64
+	case math.IsNaN(f):
+		f = 0
+	}
+
+	return new(big.Rat).SetFloat64(f)
+}
+
+// attrLength returns the number of characters of a string, the number of bytes
+// of bytes, or the number of elements of an array or an object.
+func attrLength(v any) (int, bool) {
+	switch v := v.(type) {
+	case string:
+		return utf8.RuneCountInString(v), true
+	case []byte:
+		return len(v), true
+	case []string:
+		return len(v), true
+	case []float64:
+		return len(v), true
+	case map[string]any:
+		return len(v), true
+	default:
+		return 0, false
+	}
+}
+
+// enumValue returns the text of v that is compared with the values of Enum.
+func enumValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,297 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttrCheckValue(t *testing.T) {
+	assert := assert.New(t)
+
+	minVal, maxVal := 1.0, 10.5
+	minLen, maxLen := 2, 3
+
+	tests := []struct {
+		attr               Attr
+		val                any
+		expectedConstraint string
+	}{
+		{
+			attr: Attr{Type: AttrTypeInt, Min: &minVal, Max: &maxVal},
+			val:  1,
+		}, {
+			attr:               Attr{Type: AttrTypeInt, Min: &minVal, Max: &maxVal},
+			val:                0,
+			expectedConstraint: "min",
+		}, {
+			attr:               Attr{Type: AttrTypeUint64, Min: &minVal, Max: &maxVal},
+			val:                uint64(11),
+			expectedConstraint: "max",
+		}, {
+			attr: Attr{Type: AttrTypeFloat64, Min: &minVal, Max: &maxVal},
+			val:  10.5,
+		}, {
+			attr:               Attr{Type: AttrTypeDecimal, Max: &maxVal},
+			val:                Decimal("10.50000001"),
+			expectedConstraint: "max",
+		}, {
+			attr:               Attr{Type: AttrTypeInt8, Nullable: true, Min: &minVal},
+			val:                ptr(int8(-1)),
+			expectedConstraint: "min",
+		}, {
+			attr: Attr{Type: AttrTypeInt8, Nullable: true, Min: &minVal},
+			val:  nilptr("int8"),
+		}, {
+			attr: Attr{Type: AttrTypeString, MinLength: &minLen, MaxLength: &maxLen},
+			val:  "éé",
+		}, {
+			attr:               Attr{Type: AttrTypeString, MinLength: &minLen},
+			val:                "a",
+			expectedConstraint: "minlen",
+		}, {
+			attr:               Attr{Type: AttrTypeStrings, MaxLength: &maxLen},
+			val:                []string{"a", "b", "c", "d"},
+			expectedConstraint: "maxlen",
+		}, {
+			attr:               Attr{Type: AttrTypeBytes, MaxLength: &maxLen},
+			val:                []byte{1, 2, 3, 4},
+			expectedConstraint: "maxlen",
+		}, {
+			attr: Attr{Type: AttrTypeObject, MinLength: &minLen},
+			val:  map[string]any{"a": 1, "b": 2},
+		}, {
+			attr: Attr{Type: AttrTypeString, Pattern: "^[a-z]+$"},
+			val:  "abc",
+		}, {
+			attr:               Attr{Type: AttrTypeString, Pattern: "^[a-z]+$"},
+			val:                "abc1",
+			expectedConstraint: "pattern",
+		}, {
+			attr: Attr{Type: AttrTypeString, Enum: []string{"draft", "published"}},
+			val:  "draft",
+		}, {
+			attr:               Attr{Type: AttrTypeString, Enum: []string{"draft", "published"}},
+			val:                "deleted",
+			expectedConstraint: "enum",
+		}, {
+			attr: Attr{Type: AttrTypeInt, Nullable: true, Enum: []string{"1", "2"}},
+			val:  ptr(2),
+		}, {
+			attr: Attr{Type: AttrTypeBool, Min: &minVal, MinLength: &minLen},
+			val:  true,
+		},
+	}
+
+	for i, test := range tests {
+		test.attr.Name = "attr"
+		err := test.attr.CheckValue(test.val)
+
+		if test.expectedConstraint == "" {
+			assert.NoError(err, i)
+		} else if e, ok := err.(Error); assert.True(ok, i) {
+			assert.Equal("422", e.Status, i)
+			assert.Equal(test.expectedConstraint, e.Meta["constraint"], i)
+			assert.Equal("attr", e.Meta["field"], i)
+		}
+	}
+
+	// Details
+	assert.EqualError(
+		Attr{Name: "attr", Enum: []string{"a", "b"}}.CheckValue("c"),
+		`422 Unprocessable Entity: "attr" must be one of a, b.`,
+	)
+	assert.EqualError(
+		Attr{Name: "attr", Max: &maxVal}.CheckValue(11),
+		`422 Unprocessable Entity: "attr" must be at most 10.5.`,
+	)
+}
+
+func TestAttrConstraintsTags(t *testing.T) {
+	assert := assert.New(t)
+
+	typ, err := BuildType(constrainedType{})
+	assert.NoError(err)
+
+	minVal, maxVal := 0.0, 100.0
+	minLen, maxLen := 1, 20
+
+	assert.Equal(Attr{
+		Name:      "title",
+		Type:      AttrTypeString,
+		Required:  true,
+		MinLength: &minLen,
+		MaxLength: &maxLen,
+		Pattern:   "^[A-Z]",
+	}, typ.Attrs["title"])
+	assert.Equal(Attr{
+		Name: "status",
+		Type: AttrTypeString,
+		Enum: []string{"draft", "published"},
+	}, typ.Attrs["status"])
+	assert.Equal(Attr{
+		Name:     "score",
+		Type:     AttrTypeInt,
+		Nullable: true,
+		Min:      &minVal,
+		Max:      &maxVal,
+	}, typ.Attrs["score"])
+	assert.True(typ.Attrs["views"].ReadOnly)
+	assert.True(typ.Attrs["slug"].WriteOnce)
+
+	// Wrapper
+	assert.Equal(typ.Attrs["title"], Wrap(&constrainedType{}).Attr("title"))
+
+	// Copies do not share constraints
+	typ2 := typ.Copy()
+	*typ2.Attrs["score"].Min = 5
+	typ2.Attrs["status"].Enum[0] = "deleted"
+	assert.Equal(0.0, *typ.Attrs["score"].Min)
+	assert.Equal("draft", typ.Attrs["status"].Enum[0])
+	assert.True(typ.Equal(typ.Copy()))
+
+	// Invalid tags
+	err = Check(struct {
+		ID    string `json:"id" api:"invalid"`
+		Title string `json:"title" api:"attr,maxlen=abc"`
+	}{})
+	assert.EqualError(
+		err,
+		`jsonapi: api tag of attribute "Title" is invalid: `+
+			`strconv.Atoi: parsing "abc": invalid syntax`,
+	)
+
+	err = Check(struct {
+		ID    string `json:"id" api:"invalid"`
+		Title string `json:"title" api:"attr,unknown"`
+	}{})
+	assert.EqualError(
+		err,
+		`jsonapi: api tag of attribute "Title" is invalid: unknown option "unknown"`,
+	)
+
+	err = Check(struct {
+		ID    string `json:"id" api:"invalid"`
+		Title string `json:"title" api:"attr" pattern:"("`
+	}{})
+	assert.EqualError(err, `jsonapi: pattern of attribute "Title" is invalid`)
+
+	// Invalid pattern
+	err = (&Type{Name: "type"}).AddAttr(Attr{Name: "attr", Type: AttrTypeString, Pattern: "("})
+	assert.EqualError(err, `jsonapi: pattern of attribute "attr" is invalid`)
+}
+
+func TestUnmarshalResourceConstraints(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+	_ = schema.AddType(MustBuildType(constrainedType{}))
+
+	// New resources
+	tests := []struct {
+		payload         string
+		expectedPointer string
+		expectedMeta    string
+	}{
+		{
+			payload: `{"attributes": {"title": "Title", "slug": "title"}}`,
+		}, {
+			payload:         `{"attributes": {"status": "draft"}}`,
+			expectedPointer: "/attributes/title",
+			expectedMeta:    "required",
+		}, {
+			payload:         `{"attributes": {"title": null}}`,
+			expectedPointer: "/attributes/title",
+			expectedMeta:    "invalid",
+		}, {
+			payload:         `{"attributes": {"title": "title"}}`,
+			expectedPointer: "/attributes/title",
+			expectedMeta:    "pattern",
+		}, {
+			payload:         `{"attributes": {"title": "Title", "status": "deleted"}}`,
+			expectedPointer: "/attributes/status",
+			expectedMeta:    "enum",
+		}, {
+			payload:         `{"attributes": {"title": "Title", "score": 101}}`,
+			expectedPointer: "/attributes/score",
+			expectedMeta:    "max",
+		}, {
+			payload: `{"attributes": {"title": "Title", "score": null}}`,
+		}, {
+			payload:         `{"attributes": {"title": "Title", "views": 10}}`,
+			expectedPointer: "/attributes/views",
+			expectedMeta:    "readonly",
+		},
+	}
+
+	for _, test := range tests {
+		payload := `{"type": "constrained", "id": "c1", ` + test.payload[1:]
+
+		_, err := UnmarshalResource([]byte(payload), schema)
+
+		if test.expectedMeta == "" {
+			assert.NoError(err, test.payload)
+			continue
+		}
+
+		if e, ok := err.(Error); assert.True(ok, test.payload) {
+			assert.Equal(test.expectedPointer, e.Source["pointer"], test.payload)
+
+			if test.expectedMeta != "invalid" {
+				assert.Equal("422", e.Status, test.payload)
+				assert.Equal(test.expectedMeta, e.Meta["constraint"], test.payload)
+			}
+		}
+	}
+
+	// All the problems
+	payload := `{
+		"type": "constrained",
+		"attributes": {"status": "deleted", "views": 10}
+	}`
+
+	_, err := UnmarshalResourceAll([]byte(payload), schema)
+	if errs, ok := err.(Errors); assert.True(ok) && assert.Len(errs, 3) {
+		assert.Equal("/attributes/status", errs[0].Source["pointer"])
+		assert.Equal("/attributes/views", errs[1].Source["pointer"])
+		assert.Equal("/attributes/title", errs[2].Source["pointer"])
+	}
+
+	// Updates
+	payload = `{"type": "constrained", "id": "c1", "attributes": {"status": "published"}}`
+	res, err := UnmarshalPartialResource([]byte(payload), schema)
+	assert.NoError(err)
+	assert.Equal("published", res.Get("status"))
+
+	payload = `{"type": "constrained", "id": "c1", "attributes": {"slug": "other"}}`
+	_, err = UnmarshalPartialResource([]byte(payload), schema)
+	assert.EqualError(
+		err,
+		`422 Unprocessable Entity: "slug" cannot be changed once the resource is created.`,
+	)
+
+	payload = `{"type": "constrained", "id": "c1", "attributes": {"title": ""}}`
+	_, err = UnmarshalPartialResource([]byte(payload), schema)
+	assert.Equal("minlen", err.(Error).Meta["constraint"])
+
+	// Documents are only checked against the constraints on values, since
+	// they are not always sent by clients.
+	payload = `{"data": {"type": "constrained", "id": "c1", "attributes": {"views": 10}}}`
+	_, err = UnmarshalDocument([]byte(payload), schema)
+	assert.NoError(err)
+
+	payload = `{"data": {"type": "constrained", "id": "c1", "attributes": {"score": -1}}}`
+	_, err = UnmarshalDocument([]byte(payload), schema)
+	assert.EqualError(err, `422 Unprocessable Entity: "score" must be at least 0.`)
+}
+
+type constrainedType struct {
+	ID     string `json:"id" api:"constrained"`
+	Title  string `json:"title" api:"attr,required,minlen=1,maxlen=20" pattern:"^[A-Z]"`
+	Status string `json:"status" api:"attr,enum=draft|published"`
+	Score  *int   `json:"score" api:"attr,min=0,max=100"`
+	Views  int    `json:"views" api:"attr,readonly"`
+	Slug   string `json:"slug" api:"attr,writeonce"`
+}
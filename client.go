@@ -0,0 +1,429 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// A Client sends requests to a JSON:API service described by a schema.
+//
+// The documents received from the service are unmarshaled with Schema. When
+// the service responds with an error status code, the returned error is of
+// type Errors and holds the errors found in the response. Other errors are
+// returned if the request could not be sent or the response could not be
+// read.
+type Client struct {
+	// BaseURL is the URL the paths are appended to, for example
+	// "https://example.org/api".
+	BaseURL string
+	Schema  *Schema
+
+	// HTTPClient is used to send the requests. http.DefaultClient is
+	// used if it is nil.
+	HTTPClient *http.Client
+}
+
+// NewClient returns a *Client for the service found at baseURL and described by
+// schema.
+func NewClient(baseURL string, schema *Schema) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Schema:  schema,
+	}
+}
+
+// Get returns the resource of type typ identified by id.
+//
+// params can be nil, in which case the default values of the service are used.
+func (c *Client) Get(typ, id string, params *Params) (Resource, error) {
+	doc, err := c.Do(http.MethodGet, c.url(params, false, typ, id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return docResource(doc)
+}
+
+// List returns the collection of resources of type typ.
+//
+// params can be nil, in which case the default values of the service are used.
+func (c *Client) List(typ string, params *Params) (Collection, error) {
+	doc, err := c.Do(http.MethodGet, c.url(params, true, typ), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return docCollection(doc)
+}
+
+// Create creates res and returns the resource created by the service.
+//
+// The ID of res can be empty if the service generates the IDs.
+func (c *Client) Create(res Resource) (Resource, error) {
+	body, err := marshalRequestData(resourceObject(res, linkage))
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := c.Do(http.MethodPost, c.url(nil, true, res.GetType().Name), body)
+	if err != nil {
+		return nil, err
+	}
+
+	return docResource(doc)
+}
+
+// Update updates the resource identified by the ID of res with the fields
+// defined in res, which can be a partial resource.
+//
+// The updated resource is returned, or nil if the service does not return it.
+func (c *Client) Update(res Resource) (Resource, error) {
+	body, err := marshalRequestData(resourceObject(res, linkage))
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.url(nil, false, res.GetType().Name, res.Get("id").(string))
+
+	doc, err := c.Do(http.MethodPatch, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return docResource(doc)
+}
+
+// Delete deletes the resource of type typ identified by id.
+func (c *Client) Delete(typ, id string) error {
+	_, err := c.Do(http.MethodDelete, c.url(nil, false, typ, id), nil)
+
+	return err
+}
+
+// GetRelated returns the resource that the to-one relationship rel of the
+// resource of type typ identified by id points to, or nil if it is empty.
+func (c *Client) GetRelated(typ, id, rel string, params *Params) (Resource, error) {
+	if _, err := c.rel(typ, rel, true); err != nil {
+		return nil, err
+	}
+
+	doc, err := c.Do(http.MethodGet, c.url(params, false, typ, id, rel), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return docResource(doc)
+}
+
+// ListRelated returns the resources that the to-many relationship rel of the
+// resource of type typ identified by id points to.
+func (c *Client) ListRelated(typ, id, rel string, params *Params) (Collection, error) {
+	if _, err := c.rel(typ, rel, false); err != nil {
+		return nil, err
+	}
+
+	doc, err := c.Do(http.MethodGet, c.url(params, true, typ, id, rel), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return docCollection(doc)
+}
+
+// GetRelationship returns the IDs of the resources that the relationship rel
+// of the resource of type typ identified by id points to.
+//
+// The types of the resources are left out, which is only a problem for a
+// polymorphic relationship (see GetRelationshipIdentifiers).
+func (c *Client) GetRelationship(typ, id, rel string) ([]string, error) {
+	idens, err := c.GetRelationshipIdentifiers(typ, id, rel)
+	if err != nil {
+		return nil, err
+	}
+
+	return idens.IDs(), nil
+}
+
+// GetRelationshipIdentifiers returns the identifiers of the resources that the
+// relationship rel of the resource of type typ identified by id points to.
+//
+// Unlike GetRelationship, it tells the types of the resources, which can differ
+// for a polymorphic relationship.
+func (c *Client) GetRelationshipIdentifiers(typ, id, rel string) (Identifiers, error) {
+	r, ok := c.Schema.GetType(typ).Rels[rel]
+	if !ok {
+		return nil, fmt.Errorf("jsonapi: type %q has no relationship %q", typ, rel)
+	}
+
+	u := c.url(nil, !r.ToOne, typ, id, "relationships", rel)
+
+	doc, err := c.Do(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	idens := Identifiers{}
+	if doc == nil {
+		return idens, nil
+	}
+
+	iden := func(res Resource) Identifier {
+		return Identifier{Type: res.GetType().Name, ID: res.Get("id").(string)}
+	}
+
+	switch d := doc.Data.(type) {
+	case Resource:
+		idens = append(idens, iden(d))
+	case Collection:
+		for i := 0; i < d.Len(); i++ {
+			idens = append(idens, iden(d.At(i)))
+		}
+	}
+
+	return idens, nil
+}
+
+// UpdateToOne sets the to-one relationship rel of the resource of type typ
+// identified by id to toID. An empty toID empties the relationship.
+//
+// A polymorphic relationship cannot be updated from IDs only, Update must be
+// used instead.
+func (c *Client) UpdateToOne(typ, id, rel, toID string) error {
+	r, err := c.rel(typ, rel, true)
+	if err != nil {
+		return err
+	}
+
+	if r.Polymorphic() {
+		return fmt.Errorf("jsonapi: %q is a polymorphic relationship", rel)
+	}
+
+	var data any
+	if toID != "" {
+		data = linkage(r.ToType, toID)
+	}
+
+	return c.updateRel(http.MethodPatch, typ, id, rel, data)
+}
+
+// UpdateToMany replaces the IDs of the to-many relationship rel of the
+// resource of type typ identified by id.
+//
+// Like UpdateToOne, it cannot be used with a polymorphic relationship.
+func (c *Client) UpdateToMany(typ, id, rel string, ids []string) error {
+	return c.updateToMany(http.MethodPatch, typ, id, rel, ids)
+}
+
+// AddToMany adds ids to the to-many relationship rel of the resource of type
+// typ identified by id.
+func (c *Client) AddToMany(typ, id, rel string, ids []string) error {
+	return c.updateToMany(http.MethodPost, typ, id, rel, ids)
+}
+
+// RemoveFromMany removes ids from the to-many relationship rel of the resource
+// of type typ identified by id.
+func (c *Client) RemoveFromMany(typ, id, rel string, ids []string) error {
+	return c.updateToMany(http.MethodDelete, typ, id, rel, ids)
+}
+
+// Do sends a request with the given method and body to the given URL and
+// returns the document found in the response.
+//
+// rawurl is appended to BaseURL. The returned document is nil if the response
+// has no content.
+func (c *Client) Do(method, rawurl string, body []byte) (*Document, error) {
+	req, err := http.NewRequest(method, c.BaseURL+rawurl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", MediaType)
+
+	if body != nil {
+		req.Header.Set("Content-Type", MediaType)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, responseErrors(resp.StatusCode, payload, c.Schema)
+	}
+
+	if resp.StatusCode == http.StatusNoContent || len(payload) == 0 {
+		return nil, nil
+	}
+
+	return UnmarshalDocument(payload, c.Schema)
+}
+
+// URL returns the string to give to Do for reaching u.
+//
+// It is built with the String method of URL, followed by the include parameter.
+func (c *Client) URL(u *URL) string {
+	str := u.String()
+
+	if len(u.Params.Include) > 0 {
+		paths := make([]string, 0, len(u.Params.Include))
+
+		for _, inc := range u.Params.Include {
+			names := make([]string, 0, len(inc))
+			for _, rel := range inc {
+				names = append(names, rel.FromName)
+			}
+
+			paths = append(paths, strings.Join(names, "."))
+		}
+
+		sep := "?"
+		if strings.Contains(str, "?") {
+			sep = "&"
+		}
+
+		str += sep + "include=" + strings.Join(paths, "%2C")
+	}
+
+	return str
+}
+
+// url returns the string to give to Do for reaching the given path with params.
+func (c *Client) url(params *Params, isCol bool, frags ...string) string {
+	escaped := make([]string, len(frags))
+	for i := range frags {
+		escaped[i] = url.PathEscape(frags[i])
+	}
+
+	if params == nil {
+		return "/" + strings.Join(escaped, "/")
+	}
+
+	return c.URL(&URL{
+		Fragments: escaped,
+		IsCol:     isCol,
+		Params:    params,
+	})
+}
+
+// rel returns the relationship rel of type typ after making sure its kind
+// (to-one or to-many) is toOne.
+func (c *Client) rel(typ, rel string, toOne bool) (Rel, error) {
+	r, ok := c.Schema.GetType(typ).Rels[rel]
+	if !ok {
+		return Rel{}, fmt.Errorf("jsonapi: type %q has no relationship %q", typ, rel)
+	}
+
+	if r.ToOne != toOne {
+		kind := "to-many"
+		if toOne {
+			kind = "to-one"
+		}
+
+		return Rel{}, fmt.Errorf("jsonapi: %q is not a %s relationship", rel, kind)
+	}
+
+	return r, nil
+}
+
+func (c *Client) updateToMany(method, typ, id, rel string, ids []string) error {
+	r, err := c.rel(typ, rel, false)
+	if err != nil {
+		return err
+	}
+
+	if r.Polymorphic() {
+		return fmt.Errorf("jsonapi: %q is a polymorphic relationship", rel)
+	}
+
+	data := make([]map[string]string, 0, len(ids))
+	for _, toID := range ids {
+		data = append(data, linkage(r.ToType, toID))
+	}
+
+	return c.updateRel(method, typ, id, rel, data)
+}
+
+func (c *Client) updateRel(method, typ, id, rel string, data any) error {
+	body, err := marshalRequestData(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Do(method, c.url(nil, false, typ, id, "relationships", rel), body)
+
+	return err
+}
+
+// marshalRequestData returns a document whose primary data is data.
+func marshalRequestData(data any) ([]byte, error) {
+	return json.Marshal(map[string]any{"data": data})
+}
+
+// linkage returns the resource identifier object of the resource of type typ
+// identified by id.
+func linkage(typ, id string) map[string]string {
+	return map[string]string{"type": typ, "id": id}
+}
+
+// docResource returns the resource found in doc, or nil.
+func docResource(doc *Document) (Resource, error) {
+	if doc == nil || doc.Data == nil {
+		return nil, nil
+	}
+
+	res, ok := doc.Data.(Resource)
+	if !ok {
+		return nil, errors.New("jsonapi: the primary data is not a resource")
+	}
+
+	return res, nil
+}
+
+// docCollection returns the collection found in doc.
+func docCollection(doc *Document) (Collection, error) {
+	if doc == nil {
+		return nil, errors.New("jsonapi: the response has no content")
+	}
+
+	col, ok := doc.Data.(Collection)
+	if !ok {
+		return nil, errors.New("jsonapi: the primary data is not a collection")
+	}
+
+	return col, nil
+}
+
+// responseErrors returns the errors found in payload, or an error made from the
+// status code if there are none.
+func responseErrors(status int, payload []byte, schema *Schema) Errors {
+	doc, err := UnmarshalDocument(payload, schema)
+	if err == nil && len(doc.Errors) > 0 {
+		return doc.Errors
+	}
+
+	e := NewError()
+	e.Status = strconv.Itoa(status)
+	e.Title = http.StatusText(status)
+
+	return Errors{e}
+}
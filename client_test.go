@@ -0,0 +1,178 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+
+	stores := map[string]Store{}
+	for i := range schema.Types {
+		stores[schema.Types[i].Name] = NewMemoryStore(schema.Types[i])
+	}
+
+	handler := NewHandler(schema, stores)
+	handler.NewID = func() string { return "a1" }
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", schema)
+
+	// Create
+	users := schema.GetType("users")
+	user := users.New()
+	user.Set("id", "u1")
+	user.Set("name", "Alice")
+
+	created, err := client.Create(user)
+	assert.NoError(err)
+	assert.Equal("u1", created.Get("id"))
+	assert.Equal("Alice", created.Get("name"))
+
+	articles := schema.GetType("articles")
+	article := articles.New()
+	article.Set("title", "Title")
+	article.Set("author", "u1")
+
+	created, err = client.Create(article)
+	assert.NoError(err)
+	assert.Equal("a1", created.Get("id"))
+
+	// Conflict
+	_, err = client.Create(user)
+	assert.Error(err)
+
+	var errs Errors
+
+	assert.True(errors.As(err, &errs))
+	assert.Equal("409", errs[0].Status)
+
+	// Get
+	res, err := client.Get("users", "u1", nil)
+	assert.NoError(err)
+	assert.Equal("Alice", res.Get("name"))
+
+	ids, err := client.GetRelationship("users", "u1", "articles")
+	assert.NoError(err)
+	assert.Equal([]string{"a1"}, ids)
+
+	_, err = client.Get("users", "unknown", nil)
+	assert.True(errors.As(err, &errs))
+	assert.Equal("404", errs[0].Status)
+
+	// List with params
+	u, err := NewURLFromRaw(schema, "/articles?fields[articles]=title&include=author")
+	assert.NoError(err)
+	assert.Equal(
+		"/articles?fields%5Barticles%5D=title&fields%5Busers%5D=articles%2Cname"+
+			"&sort=title%2Cid&include=author",
+		client.URL(u),
+	)
+
+	col, err := client.List("articles", u.Params)
+	assert.NoError(err)
+	assert.Equal(1, col.Len())
+	assert.Equal("Title", col.At(0).Get("title"))
+
+	doc, err := client.Do(http.MethodGet, client.URL(u), nil)
+	assert.NoError(err)
+	assert.Len(doc.Included, 1)
+
+	// Update
+	partial := &SoftResource{Type: &Type{Name: "articles"}}
+	partial.SetID("a1")
+	_ = partial.Type.AddAttr(articles.Attrs["title"])
+	partial.Set("title", "New")
+
+	updated, err := client.Update(partial)
+	assert.NoError(err)
+	assert.Equal("New", updated.Get("title"))
+
+	ids, err = client.GetRelationship("articles", "a1", "author")
+	assert.NoError(err)
+	assert.Equal([]string{"u1"}, ids)
+
+	// Related resources
+	author, err := client.GetRelated("articles", "a1", "author", nil)
+	assert.NoError(err)
+	assert.Equal("u1", author.Get("id"))
+
+	related, err := client.ListRelated("users", "u1", "articles", nil)
+	assert.NoError(err)
+	assert.Equal(1, related.Len())
+
+	_, err = client.GetRelated("users", "u1", "articles", nil)
+	assert.EqualError(err, `jsonapi: "articles" is not a to-one relationship`)
+
+	_, err = client.ListRelated("users", "u1", "unknown", nil)
+	assert.EqualError(err, `jsonapi: type "users" has no relationship "unknown"`)
+
+	// Relationships
+	assert.NoError(client.AddToMany("articles", "a1", "related", []string{"a1", "a2"}))
+	assert.NoError(client.RemoveFromMany("articles", "a1", "related", []string{"a2"}))
+
+	ids, err = client.GetRelationship("articles", "a1", "related")
+	assert.NoError(err)
+	assert.Equal([]string{"a1"}, ids)
+
+	assert.NoError(client.UpdateToMany("articles", "a1", "related", []string{}))
+
+	ids, err = client.GetRelationship("articles", "a1", "related")
+	assert.NoError(err)
+	assert.Equal([]string{}, ids)
+
+	assert.NoError(client.UpdateToOne("articles", "a1", "author", ""))
+
+	ids, err = client.GetRelationship("articles", "a1", "author")
+	assert.NoError(err)
+	assert.Equal([]string{}, ids)
+
+	author, err = client.GetRelated("articles", "a1", "author", nil)
+	assert.NoError(err)
+	assert.Nil(author)
+
+	assert.NoError(client.UpdateToOne("articles", "a1", "author", "u1"))
+
+	ids, err = client.GetRelationship("articles", "a1", "author")
+	assert.NoError(err)
+	assert.Equal([]string{"u1"}, ids)
+
+	// Delete
+	assert.NoError(client.Delete("articles", "a1"))
+
+	_, err = client.Get("articles", "a1", nil)
+	assert.True(errors.As(err, &errs))
+	assert.Equal("404", errs[0].Status)
+}
+
+func TestClientErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("not a document"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, newHandlerSchema())
+
+	_, err := client.Get("users", "u1", nil)
+	assert.EqualError(err, "502 Bad Gateway: Bad Gateway")
+
+	// Unreachable service
+	client = NewClient("http://127.0.0.1:0", newHandlerSchema())
+
+	_, err = client.List("users", nil)
+	assert.Error(err)
+}
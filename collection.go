@@ -18,6 +18,17 @@ type Collection interface {
 	Add(Resource)
 }
 
+// A TotalHolder is a collection that knows how many resources were found
+// before it was split into pages. MarshalDocument uses it to provide the last
+// page link and the total in the meta object of the document.
+//
+// Total returns a negative number if the total is unknown. SoftCollection
+// implements this interface.
+type TotalHolder interface {
+	Total() int
+	SetTotal(total int)
+}
+
 // MarshalCollection marshals a Collection into a JSON-encoded payload.
 func MarshalCollection(c Collection, prepath string, fields map[string][]string, relData map[string][]string) []byte {
 	var raws []*json.RawMessage
@@ -42,25 +53,9 @@ func MarshalCollection(c Collection, prepath string, fields map[string][]string,
 
 // UnmarshalCollection unmarshals a JSON-encoded payload into a Collection.
 func UnmarshalCollection(data []byte, schema *Schema) (Collection, error) {
-	var cske []json.RawMessage
-
-	err := json.Unmarshal(data, &cske)
-	if err != nil {
-		return nil, err
-	}
-
-	col := &Resources{}
-
-	for i := range cske {
-		res, err := UnmarshalResource(cske[i], schema)
-		if err != nil {
-			return nil, err
-		}
-
-		col.Add(res)
-	}
+	u := &unmarshaler{schema: schema}
 
-	return col, nil
+	return u.collection(data, "")
 }
 
 // Resources is a slice of objects that implement the Resource interface. They
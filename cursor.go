@@ -0,0 +1,199 @@
+package jsonapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MarshalCursor returns an opaque cursor that represents the position of res in
+// a collection sorted according to rules.
+//
+// The cursor holds the values of the fields used for sorting. It is only valid
+// for a collection sorted with the same rules. Since IDs are unique, the rules
+// that come after "id" are ignored and "id" is added if it is missing.
+func MarshalCursor(res Resource, rules []string) string {
+	rules = cursorRules(rules)
+
+	ske := cursorSkeleton{
+		Rules:  rules,
+		Values: make([]json.RawMessage, len(rules)),
+	}
+
+	for i, rule := range rules {
+		ske.Values[i], _ = json.Marshal(res.Get(strings.TrimPrefix(rule, "-")))
+	}
+
+	payload, _ := json.Marshal(ske)
+
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// RangeCursor is like Range, but the page is defined by cursors (see
+// MarshalCursor) instead of a page number.
+//
+// If after is not empty, the page starts with the first resource that comes
+// after the position represented by after. Otherwise, if before is not empty,
+// the page ends with the last resource that comes before the position
+// represented by before. When both are set, the resources must also come
+// before before. Otherwise, the page starts with the first resource.
+//
+// An error is returned if a cursor is invalid or was not created with sort.
+// The resources of c must all be of the same type.
+func RangeCursor(
+	c Collection,
+	ids []string,
+	filter *Filter,
+	sort []string,
+	size uint,
+	after, before string,
+) (Collection, error) {
+	page := Resources{}
+
+	if c.Len() == 0 {
+		return &page, nil
+	}
+
+	sort = cursorRules(sort)
+
+	typ := c.GetType()
+	if typ.Name == "" {
+		typ = c.At(0).GetType()
+	}
+
+	var afterRes, beforeRes Resource
+
+	if after != "" {
+		res, err := unmarshalCursor(after, typ, sort)
+		if err != nil {
+			return nil, NewErrInvalidPageCursorParameter("page[after]", after)
+		}
+
+		afterRes = res
+	}
+
+	if before != "" {
+		res, err := unmarshalCursor(before, typ, sort)
+		if err != nil {
+			return nil, NewErrInvalidPageCursorParameter("page[before]", before)
+		}
+
+		beforeRes = res
+	}
+
+	all := Range(c, ids, filter, sort, uint(c.Len()), 0)
+
+	start, end := 0, all.Len()
+
+	if afterRes != nil {
+		for start < end && !cursorLess(sort, afterRes, all.At(start)) {
+			start++
+		}
+	}
+
+	if beforeRes != nil {
+		for end > start && !cursorLess(sort, all.At(end-1), beforeRes) {
+			end--
+		}
+	}
+
+	if afterRes == nil && beforeRes != nil {
+		if end-start > int(size) { //nolint:gosec
+			start = end - int(size) //nolint:gosec
+		}
+	} else if end-start > int(size) { //nolint:gosec
+		end = start + int(size) //nolint:gosec
+	}
+
+	for i := start; i < end; i++ {
+		page = append(page, all.At(i))
+	}
+
+	return &page, nil
+}
+
+// cursorRules returns the rules that define the position of a resource, which
+// are the ones up to "id".
+func cursorRules(rules []string) []string {
+	for i, rule := range rules {
+		if strings.TrimPrefix(rule, "-") == "id" {
+			return rules[:i+1]
+		}
+	}
+
+	return append(rules[:len(rules):len(rules)], "id")
+}
+
+// cursorSkeleton is the content of a cursor.
+type cursorSkeleton struct {
+	Rules  []string          `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// unmarshalCursor returns a resource of type typ whose fields hold the values
+// found in cursor, which must have been created with rules.
+func unmarshalCursor(cursor string, typ Type, rules []string) (Resource, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	ske := cursorSkeleton{}
+
+	err = json.Unmarshal(payload, &ske)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Join(ske.Rules, ",") != strings.Join(rules, ",") ||
+		len(ske.Values) != len(rules) {
+		return nil, errors.New("jsonapi: cursor does not match the sorting rules")
+	}
+
+	res := &SoftResource{Type: &Type{Name: typ.Name}}
+
+	for i, rule := range rules {
+		name := strings.TrimPrefix(rule, "-")
+
+		if name == "id" {
+			var id string
+
+			err = json.Unmarshal(ske.Values[i], &id)
+			if err != nil {
+				return nil, err
+			}
+
+			res.SetID(id)
+
+			continue
+		}
+
+		attr, ok := typ.Attrs[name]
+		if !ok {
+			return nil, fmt.Errorf("jsonapi: %q is not an attribute", name)
+		}
+
+		val, err := attr.UnmarshalToType(ske.Values[i])
+		if err != nil {
+			return nil, err
+		}
+
+		_ = res.Type.AddAttr(attr)
+		res.Set(name, val)
+	}
+
+	return res, nil
+}
+
+// cursorLess reports whether r1 comes before r2 when sorted according to
+// rules.
+func cursorLess(rules []string, r1, r2 Resource) bool {
+	s := sortedResources{
+		rules: rules,
+		col:   Resources{r1, r2},
+	}
+
+	return s.Less(0, 1)
+}
@@ -0,0 +1,148 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeCursor(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "type"}
+	_ = typ.AddAttr(Attr{
+		Name: "attr1",
+		Type: AttrTypeString,
+	})
+	_ = typ.AddAttr(Attr{
+		Name: "attr2",
+		Type: AttrTypeInt,
+	})
+
+	col := &SoftCollection{Type: typ}
+
+	values := []struct {
+		id    string
+		attr1 string
+		attr2 int
+	}{
+		{"res1", "b", 1},
+		{"res2", "a", 2},
+		{"res3", "b", 0},
+		{"res4", "c", 3},
+		{"res5", "a", 2},
+	}
+
+	for _, v := range values {
+		res := &SoftResource{Type: typ}
+		res.SetID(v.id)
+		res.Set("attr1", v.attr1)
+		res.Set("attr2", v.attr2)
+		col.Add(res)
+	}
+
+	// Sorted: res2, res5, res1, res3, res4
+	sort := []string{"attr1", "-attr2", "id"}
+	ids := func(c Collection) []string {
+		ids := []string{}
+		for i := 0; i < c.Len(); i++ {
+			ids = append(ids, c.At(i).Get("id").(string))
+		}
+
+		return ids
+	}
+	cursor := func(id string) string {
+		for i := 0; i < col.Len(); i++ {
+			if col.At(i).Get("id") == id {
+				return MarshalCursor(col.At(i), sort)
+			}
+		}
+
+		return ""
+	}
+
+	tests := []struct {
+		name     string
+		size     uint
+		after    string
+		before   string
+		expected []string
+	}{
+		{
+			name:     "first page",
+			size:     2,
+			expected: []string{"res2", "res5"},
+		}, {
+			name:     "after",
+			size:     2,
+			after:    cursor("res5"),
+			expected: []string{"res1", "res3"},
+		}, {
+			name:     "after last page",
+			size:     2,
+			after:    cursor("res3"),
+			expected: []string{"res4"},
+		}, {
+			name:     "after last resource",
+			size:     2,
+			after:    cursor("res4"),
+			expected: []string{},
+		}, {
+			name:     "before",
+			size:     2,
+			before:   cursor("res3"),
+			expected: []string{"res5", "res1"},
+		}, {
+			name:     "before first page",
+			size:     2,
+			before:   cursor("res5"),
+			expected: []string{"res2"},
+		}, {
+			name:     "after and before",
+			size:     5,
+			after:    cursor("res2"),
+			before:   cursor("res4"),
+			expected: []string{"res5", "res1", "res3"},
+		},
+	}
+
+	for _, test := range tests {
+		rang, err := RangeCursor(col, nil, nil, sort, test.size, test.after, test.before)
+		assert.NoError(err, test.name)
+		assert.Equal(test.expected, ids(rang), test.name)
+	}
+
+	// Rules after id are ignored
+	assert.Equal(
+		MarshalCursor(col.At(0), []string{"attr1", "id"}),
+		MarshalCursor(col.At(0), []string{"attr1", "id", "attr2"}),
+	)
+
+	// Missing id is added
+	assert.Equal(
+		MarshalCursor(col.At(0), []string{"attr1", "id"}),
+		MarshalCursor(col.At(0), []string{"attr1"}),
+	)
+
+	// Empty collection
+	rang, err := RangeCursor(&Resources{}, nil, nil, sort, 2, "abc", "")
+	assert.NoError(err)
+	assert.Equal(0, rang.Len())
+
+	// Invalid cursors
+	for _, cur := range []string{
+		"%%%",
+		"bm90IGpzb24",
+		MarshalCursor(col.At(0), []string{"attr2", "id"}),
+	} {
+		_, err = RangeCursor(col, nil, nil, sort, 2, cur, "")
+		assert.IsType(Error{}, err)
+		assert.Equal("400", err.(Error).Status)
+		assert.Equal("page[after]", err.(Error).Source["parameter"])
+
+		_, err = RangeCursor(col, nil, nil, sort, 2, "", cur)
+		assert.Equal("page[before]", err.(Error).Source["parameter"])
+	}
+}
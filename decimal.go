@@ -0,0 +1,125 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+)
+
+// maxDecimalExponent is the largest absolute value of the exponent of a valid
+// Decimal. Comparing decimals with larger exponents would require huge
+// computations.
+const maxDecimalExponent = 1000
+
+// Decimal is a decimal number represented by its text, like "12.50". Unlike
+// floating-point numbers, it keeps the exact value found in a payload.
+//
+// It is marshaled as a JSON number. The empty string represents 0.
+type Decimal string
+
+// Cmp compares d and d2 and returns -1, 0, or +1 depending on whether d is less
+// than, equal to, or greater than d2. Invalid decimals are considered to be 0.
+func (d Decimal) Cmp(d2 Decimal) int {
+	return d.rat().Cmp(d2.rat())
+}
+
+// IsValid reports whether d is a valid decimal number. It is made of an
+// optional minus sign, digits, an optional fraction and an optional exponent,
+// like a JSON number. The absolute value of the exponent cannot be greater than
+// 1000.
+func (d Decimal) IsValid() bool {
+	s := string(d)
+	i := 0
+
+	digits := func() bool {
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+
+		return i > start
+	}
+
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+
+	// Like in JSON, the integer part cannot have leading zeros.
+	if start := i; !digits() || (s[start] == '0' && i-start > 1) {
+		return false
+	}
+
+	if i < len(s) && s[i] == '.' {
+		i++
+
+		if !digits() {
+			return false
+		}
+	}
+
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+
+		start := i
+		if !digits() {
+			return false
+		}
+
+		exp, err := strconv.Atoi(s[start:i])
+		if err != nil || exp > maxDecimalExponent {
+			return false
+		}
+	}
+
+	return i == len(s)
+}
+
+// MarshalJSON marshals d as a JSON number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if d == "" {
+		return []byte("0"), nil
+	}
+
+	if !d.IsValid() {
+		return nil, errors.New("jsonapi: invalid decimal")
+	}
+
+	return []byte(d), nil
+}
+
+// UnmarshalJSON reads a decimal represented by a JSON number or by a string
+// that holds a number.
+func (d *Decimal) UnmarshalJSON(payload []byte) error {
+	s := string(payload)
+
+	if len(payload) > 0 && payload[0] == '"' {
+		err := json.Unmarshal(payload, &s)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !Decimal(s).IsValid() {
+		return errors.New("jsonapi: invalid decimal")
+	}
+
+	*d = Decimal(s)
+
+	return nil
+}
+
+// rat returns the value of d.
+func (d Decimal) rat() *big.Rat {
+	r := new(big.Rat)
+
+	if d.IsValid() {
+		r.SetString(string(d))
+	}
+
+	return r
+}
@@ -0,0 +1,82 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimal(t *testing.T) {
+	assert := assert.New(t)
+
+	// Validity
+	valid := []Decimal{"0", "-1", "12.50", "1e10", "-1.5E-3", "0.07", "1e1000", "1E-0001000"}
+	for _, d := range valid {
+		assert.True(d.IsValid(), d)
+	}
+
+	invalid := []Decimal{
+		"", "-", "1.", ".5", "1e", "1.2.3", "+1", "abc", "1 ", "007", "-01",
+		"1e1001", "1e-1001", "1e9999999", "1e99999999999999999999",
+	}
+	for _, d := range invalid {
+		assert.False(d.IsValid(), d)
+	}
+
+	// Comparison
+	assert.Equal(0, Decimal("1.50").Cmp("1.5"))
+	assert.Equal(-1, Decimal("9.99").Cmp("10"))
+	assert.Equal(1, Decimal("1e2").Cmp("99.5"))
+	assert.Equal(0, Decimal("").Cmp("0"))
+
+	// Marshaling
+	pl, err := json.Marshal(Decimal("12.50"))
+	assert.NoError(err)
+	assert.Equal("12.50", string(pl))
+
+	pl, err = json.Marshal(Decimal(""))
+	assert.NoError(err)
+	assert.Equal("0", string(pl))
+
+	_, err = json.Marshal(Decimal("abc"))
+	assert.Error(err)
+
+	// Unmarshaling
+	var d Decimal
+
+	err = json.Unmarshal([]byte("12.50"), &d)
+	assert.NoError(err)
+	assert.Equal(Decimal("12.50"), d)
+
+	err = json.Unmarshal([]byte(`"-0.1"`), &d)
+	assert.NoError(err)
+	assert.Equal(Decimal("-0.1"), d)
+
+	err = json.Unmarshal([]byte(`"abc"`), &d)
+	assert.Error(err)
+
+	err = json.Unmarshal([]byte("true"), &d)
+	assert.Error(err)
+
+	err = json.Unmarshal([]byte("1e9999999"), &d)
+	assert.EqualError(err, "jsonapi: invalid decimal")
+
+	err = json.Unmarshal([]byte(`"007"`), &d)
+	assert.EqualError(err, "jsonapi: invalid decimal")
+
+	// Round trip
+	for _, s := range []string{`"-0.50"`, `"1E+2"`, "12.50"} {
+		var d Decimal
+
+		err = json.Unmarshal([]byte(s), &d)
+		assert.NoError(err, s)
+
+		pl, err = json.Marshal(d)
+		assert.NoError(err, s)
+		assert.Equal(strings.Trim(s, `"`), string(pl))
+	}
+}
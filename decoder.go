@@ -0,0 +1,429 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A Decoder reads JSON:API documents from an input stream.
+//
+// Unlike UnmarshalDocument, which requires the whole payload, a Decoder reads
+// the resources of the primary data and the included member one at a time and
+// hands them over as soon as they are read and validated against the schema.
+//
+// The limits are checked as the input is read. A limit set to 0 means there
+// is no limit.
+type Decoder struct {
+	// MaxBytes is the maximum number of bytes read from the input.
+	MaxBytes int64
+
+	// MaxResources is the maximum number of resources in a document,
+	// including the included resources.
+	MaxResources int
+
+	// MaxDepth is the maximum nesting depth of the objects and arrays
+	// of a document. The top-level object is at depth 1.
+	MaxDepth int
+
+	dec    *json.Decoder
+	schema *Schema
+}
+
+// NewDecoder returns a new decoder that reads from r the documents described
+// by schema.
+func NewDecoder(r io.Reader, schema *Schema) *Decoder {
+	d := &Decoder{
+		schema: schema,
+	}
+
+	d.dec = json.NewDecoder(&decoderReader{r: r, d: d})
+	d.dec.UseNumber()
+
+	return d
+}
+
+// Decode reads the next document from the input.
+//
+// fn is called for each resource found in the primary data and the included
+// member, in the order they are read, along with the JSON pointer of the
+// resource (like "/data/2" or "/included/0"). If fn returns an error, the
+// decoding stops and the error is returned.
+//
+// The returned document holds the top-level members other than the resources.
+// If the primary data is a collection, Data is an empty collection. If it is a
+// single resource, Data also holds it. Included is always empty.
+//
+// The full linkage of the included resources is checked once the document is
+// read, like it is by UnmarshalDocument, which means the included resources
+// have already been passed to fn when the violations are returned as Errors.
+//
+// The errors about the content of the document are returned like they are by
+// UnmarshalDocument, with a source pointer. Exceeding a limit returns a 413
+// error, except for MaxDepth which returns a 400 error.
+func (d *Decoder) Decode(fn func(res Resource, pointer string) error) (*Document, error) {
+	doc := &Document{
+		Included:  []Resource{},
+		Resources: map[string]map[string]struct{}{},
+		Links:     map[string]Link{},
+		RelData:   map[string][]string{},
+		Meta:      map[string]any{},
+	}
+	ds := &decoderState{
+		Decoder: d,
+		u: &unmarshaler{
+			schema:      d.schema,
+			strictTypes: true,
+			relData:     doc.RelData,
+		},
+		lc: newLinkageChecker(doc.RelData),
+		fn: fn,
+	}
+
+	err := ds.expectDelim('{', "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = ds.checkDepth(1, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		key, _ := tok.(string)
+		ptr := "/" + escapeJSONPointer(key)
+
+		switch key {
+		case "data":
+			doc.Data, err = ds.data()
+		case "included":
+			err = ds.included()
+		case "errors":
+			err = ds.value(&doc.Errors, ptr)
+		case "links":
+			err = ds.value(&doc.Links, ptr)
+		case "meta":
+			err = ds.value(&doc.Meta, ptr)
+		case "jsonapi":
+			err = ds.value(&doc.JSONAPI, ptr)
+		default:
+			err = ds.value(&json.RawMessage{}, ptr)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Closing brace
+	_, err = d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if errs := ds.lc.check(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	return doc, nil
+}
+
+// decoderState holds the state of a Decoder while it decodes a document.
+type decoderState struct {
+	*Decoder
+
+	u     *unmarshaler
+	lc    *linkageChecker
+	fn    func(Resource, string) error
+	count int
+}
+
+// data reads the primary data.
+func (ds *decoderState) data() (any, error) {
+	tok, err := ds.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case nil:
+		return nil, nil
+	case json.Delim('['):
+		return &Resources{}, ds.resources("/data")
+	case json.Delim('{'):
+		// The opening brace is already consumed, so the members are
+		// read one by one to rebuild the resource object.
+		err = ds.checkDepth(2, "/data")
+		if err != nil {
+			return nil, err
+		}
+
+		obj := map[string]json.RawMessage{}
+
+		for ds.dec.More() {
+			tok, err := ds.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			key, _ := tok.(string)
+			raw := json.RawMessage{}
+
+			err = ds.value(&raw, "/data/"+escapeJSONPointer(key))
+			if err != nil {
+				return nil, err
+			}
+
+			obj[key] = raw
+		}
+
+		_, err = ds.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		raw, _ := json.Marshal(obj)
+
+		res, err := ds.resource(raw, "/data")
+		if err != nil {
+			return nil, err
+		}
+
+		ds.lc.addPrimary(res)
+
+		return res, nil
+	default:
+		return nil, withPointer(NewErrMissingDataMember(), "/data")
+	}
+}
+
+// included reads the included resources.
+func (ds *decoderState) included() error {
+	err := ds.expectDelim('[', "/included")
+	if err != nil {
+		return err
+	}
+
+	return ds.resources("/included")
+}
+
+// resources reads the resources of the array found at pointer, whose opening
+// bracket is already consumed.
+func (ds *decoderState) resources(pointer string) error {
+	err := ds.checkDepth(2, pointer)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; ds.dec.More(); i++ {
+		ptr := pointer + "/" + strconv.Itoa(i)
+		raw := json.RawMessage{}
+
+		err = ds.value(&raw, ptr)
+		if err != nil {
+			return err
+		}
+
+		var res Resource
+
+		res, err = ds.resource(raw, ptr)
+		if err != nil {
+			return err
+		}
+
+		if pointer == "/included" {
+			ds.lc.addIncluded(res, i)
+		} else {
+			ds.lc.addPrimary(res)
+		}
+	}
+
+	// Closing bracket
+	_, err = ds.dec.Token()
+
+	return err
+}
+
+// resource unmarshals the resource object found in raw at pointer and passes
+// it to the callback.
+func (ds *decoderState) resource(raw []byte, pointer string) (Resource, error) {
+	ds.count++
+
+	if ds.MaxResources > 0 && ds.count > ds.MaxResources {
+		e := NewErrPayloadTooLarge()
+		e.Detail = fmt.Sprintf("The payload contains more than %d resources.", ds.MaxResources)
+		e.Source["pointer"] = pointer
+
+		return nil, e
+	}
+
+	res, err := ds.u.resource(raw, pointer, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if ds.fn != nil {
+		err = ds.fn(res, pointer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// value decodes the next value into v. The value is found at pointer, which
+// is used to compute its depth.
+//
+// The value is read token by token, so exceeding the maximum depth is
+// detected before the rest of the value is read.
+func (ds *decoderState) value(v any, pointer string) error {
+	raw, err := ds.rawValue(pointer)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(raw, v)
+	if err != nil {
+		return withPointer(NewErrBadRequest("Invalid JSON", err.Error()), pointer)
+	}
+
+	return nil
+}
+
+// rawValue reads the next value, found at pointer, and returns its JSON
+// encoding. An error is returned as soon as the value exceeds the maximum
+// depth.
+func (ds *decoderState) rawValue(pointer string) (json.RawMessage, error) {
+	buf := &bytes.Buffer{}
+
+	// The number of values read in each of the objects and arrays
+	// being read, keys included. Whether a container is an object is
+	// kept to know which separator precedes a value.
+	type container struct {
+		obj bool
+		n   int
+	}
+
+	stack := []container{}
+
+	for {
+		tok, err := ds.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		closing := tok == json.Delim('}') || tok == json.Delim(']')
+
+		if len(stack) > 0 && !closing {
+			c := &stack[len(stack)-1]
+
+			switch {
+			case c.obj && c.n%2 == 1:
+				buf.WriteByte(':')
+			case c.n > 0:
+				buf.WriteByte(',')
+			}
+
+			c.n++
+		}
+
+		switch tok := tok.(type) {
+		case json.Delim:
+			buf.WriteRune(rune(tok))
+
+			if closing {
+				stack = stack[:len(stack)-1]
+				break
+			}
+
+			stack = append(stack, container{obj: tok == '{'})
+
+			err = ds.checkDepth(pointerDepth(pointer)+len(stack), pointer)
+			if err != nil {
+				return nil, err
+			}
+		case json.Number:
+			buf.WriteString(string(tok))
+		default:
+			b, _ := json.Marshal(tok)
+			buf.Write(b)
+		}
+
+		if len(stack) == 0 {
+			return buf.Bytes(), nil
+		}
+	}
+}
+
+// expectDelim reads the next token and returns an error if it is not delim.
+func (ds *decoderState) expectDelim(delim json.Delim, pointer string) error {
+	tok, err := ds.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if tok != delim {
+		e := NewErrBadRequest(
+			"Invalid JSON",
+			fmt.Sprintf("The value is expected to start with %q.", delim),
+		)
+		e.Source["pointer"] = pointer
+
+		return e
+	}
+
+	return nil
+}
+
+// checkDepth returns an error if depth exceeds the maximum depth.
+func (ds *decoderState) checkDepth(depth int, pointer string) error {
+	if ds.MaxDepth > 0 && depth > ds.MaxDepth {
+		e := NewErrBadRequest(
+			"Payload too deep",
+			fmt.Sprintf("The payload is nested deeper than %d levels.", ds.MaxDepth),
+		)
+		e.Source["pointer"] = pointer
+
+		return e
+	}
+
+	return nil
+}
+
+// decoderReader reads from r and returns an error once more than MaxBytes
+// bytes are read.
+type decoderReader struct {
+	r io.Reader
+	d *Decoder
+	n int64
+}
+
+func (r *decoderReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+
+	if r.d.MaxBytes > 0 && r.n > r.d.MaxBytes {
+		e := NewErrPayloadTooLarge()
+		e.Detail = fmt.Sprintf("The payload is larger than %d bytes.", r.d.MaxBytes)
+
+		return 0, e
+	}
+
+	return n, err
+}
+
+// pointerDepth returns the number of objects and arrays that contain the value
+// found at pointer.
+func pointerDepth(pointer string) int {
+	return strings.Count(pointer, "/")
+}
@@ -0,0 +1,255 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoder(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+
+	payload := `{
+		"data": [
+			{"type": "articles", "id": "a1", "attributes": {"title": "One"}},
+			{
+				"type": "articles",
+				"id": "a2",
+				"attributes": {"title": "Two"},
+				"relationships": {
+					"author": {"data": {"type": "users", "id": "u1"}}
+				}
+			}
+		],
+		"included": [
+			{"type": "users", "id": "u1", "attributes": {"name": "Alice"}}
+		],
+		"links": {"self": "/articles"},
+		"meta": {"total": 2},
+		"jsonapi": {"version": "1.0"}
+	}`
+
+	expected, err := UnmarshalDocument([]byte(payload), schema)
+	assert.NoError(err)
+
+	resources := []Resource{}
+	pointers := []string{}
+
+	dec := NewDecoder(strings.NewReader(payload), schema)
+
+	doc, err := dec.Decode(func(res Resource, pointer string) error {
+		resources = append(resources, res)
+		pointers = append(pointers, pointer)
+
+		return nil
+	})
+	assert.NoError(err)
+
+	assert.Equal([]string{"/data/0", "/data/1", "/included/0"}, pointers)
+	assert.Equal(expected.Data.(Collection).At(0), resources[0])
+	assert.Equal(expected.Data.(Collection).At(1), resources[1])
+	assert.Equal(expected.Included[0], resources[2])
+	assert.Equal(&Resources{}, doc.Data)
+	assert.Empty(doc.Included)
+	assert.Equal(map[string]Link{"self": {HRef: "/articles"}}, doc.Links)
+	assert.Equal(2.0, doc.Meta["total"])
+	assert.Equal(JSONAPI{Version: "1.0"}, doc.JSONAPI)
+
+	// Single resource
+	payload = `{"data": {"type": "users", "id": "u1", "attributes": {"name": "Alice"}}}`
+	expected, _ = UnmarshalDocument([]byte(payload), schema)
+
+	doc, err = NewDecoder(strings.NewReader(payload), schema).Decode(nil)
+	assert.NoError(err)
+	assert.Equal(expected.Data, doc.Data)
+
+	// Null data
+	doc, err = NewDecoder(strings.NewReader(`{"data": null}`), schema).Decode(nil)
+	assert.NoError(err)
+	assert.Nil(doc.Data)
+
+	// Errors
+	payload = `{"errors": [{"status": "404", "title": "Not Found"}]}`
+
+	doc, err = NewDecoder(strings.NewReader(payload), schema).Decode(nil)
+	assert.NoError(err)
+	assert.Len(doc.Errors, 1)
+	assert.Equal("404", doc.Errors[0].Status)
+
+	// Several documents
+	dec = NewDecoder(strings.NewReader(`{"data": null} {"data": []}`), schema)
+
+	doc, err = dec.Decode(nil)
+	assert.NoError(err)
+	assert.Nil(doc.Data)
+
+	doc, err = dec.Decode(nil)
+	assert.NoError(err)
+	assert.Equal(&Resources{}, doc.Data)
+
+	// Callback error
+	payload = `{"data": [{"type": "users", "id": "u1"}, {"type": "users", "id": "u2"}]}`
+	count := 0
+
+	_, err = NewDecoder(strings.NewReader(payload), schema).Decode(
+		func(res Resource, pointer string) error {
+			count++
+
+			return errors.New("stop")
+		},
+	)
+	assert.EqualError(err, "stop")
+	assert.Equal(1, count)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+
+	tests := []struct {
+		name         string
+		payload      string
+		maxBytes     int64
+		maxResources int
+		maxDepth     int
+		status       string
+		pointer      any
+	}{
+		{
+			name:    "not an object",
+			payload: `[]`,
+			status:  "400",
+			pointer: "",
+		}, {
+			name:    "invalid data",
+			payload: `{"data": "abc"}`,
+			status:  "400",
+			pointer: "/data",
+		}, {
+			name:    "included is not an array",
+			payload: `{"included": {}}`,
+			status:  "400",
+			pointer: "/included",
+		}, {
+			name:    "unknown type",
+			payload: `{"data": [{"type": "users", "id": "u1"}, {"type": "unknown", "id": "u2"}]}`,
+			status:  "400",
+			pointer: "/data/1/type",
+		}, {
+			name: "unknown attribute",
+			payload: `{"included": [
+				{"type": "users", "id": "u1", "attributes": {"unknown": 1}}
+			]}`,
+			status:  "400",
+			pointer: "/included/0/attributes/unknown",
+		}, {
+			name:    "invalid meta",
+			payload: `{"meta": []}`,
+			status:  "400",
+			pointer: "/meta",
+		}, {
+			name:     "too many bytes",
+			payload:  `{"data": [{"type": "users", "id": "u1"}]}` + strings.Repeat(" ", 1000),
+			maxBytes: 20,
+			status:   "413",
+			pointer:  nil,
+		}, {
+			name: "too many resources",
+			payload: `{
+				"data": [{"type": "users", "id": "u1"}, {"type": "users", "id": "u2"}],
+				"included": [{"type": "users", "id": "u3"}]
+			}`,
+			maxResources: 2,
+			status:       "413",
+			pointer:      "/included/0",
+		}, {
+			name:     "too deep in data",
+			payload:  `{"data": [{"type": "users", "id": "u1", "meta": {"a": {}}}]}`,
+			maxDepth: 4,
+			status:   "400",
+			pointer:  "/data/0",
+		}, {
+			name:     "too deep in resource",
+			payload:  `{"data": {"type": "users", "id": "u1", "meta": {"a": {}}}}`,
+			maxDepth: 3,
+			status:   "400",
+			pointer:  "/data/meta",
+		}, {
+			name:     "too deep in meta",
+			payload:  `{"meta": {"a": [[]]}}`,
+			maxDepth: 3,
+			status:   "400",
+			pointer:  "/meta",
+		},
+	}
+
+	for _, test := range tests {
+		dec := NewDecoder(strings.NewReader(test.payload), schema)
+		dec.MaxBytes = test.maxBytes
+		dec.MaxResources = test.maxResources
+		dec.MaxDepth = test.maxDepth
+
+		_, err := dec.Decode(nil)
+
+		e, ok := err.(Error)
+		if assert.True(ok, test.name) {
+			assert.Equal(test.status, e.Status, test.name)
+			assert.Equal(test.pointer, e.Source["pointer"], test.name)
+		}
+	}
+
+	// Limits that are not exceeded
+	dec := NewDecoder(strings.NewReader(`{"data": [{"type": "users", "id": "u1"}]}`), schema)
+	dec.MaxBytes = 100
+	dec.MaxResources = 1
+	dec.MaxDepth = 3
+
+	_, err := dec.Decode(nil)
+	assert.NoError(err)
+
+	// A deep value is rejected before it is fully read
+	n := 1 << 20
+	r := strings.NewReader(`{"meta": ` + strings.Repeat("[", n) + strings.Repeat("]", n) + `}`)
+	dec = NewDecoder(r, schema)
+	dec.MaxDepth = 3
+
+	_, err = dec.Decode(nil)
+
+	e, ok := err.(Error)
+	if assert.True(ok) {
+		assert.Equal("400", e.Status)
+		assert.Equal("/meta", e.Source["pointer"])
+	}
+
+	assert.Greater(r.Len(), n)
+
+	// Syntax error
+	_, err = NewDecoder(strings.NewReader(`{"data": [}`), schema).Decode(nil)
+	assert.Error(err)
+
+	// Unlinked included resources
+	payload := `{
+		"data": {
+			"type": "articles",
+			"id": "a1",
+			"relationships": {
+				"author": {"data": null},
+				"related": {"data": []}
+			}
+		},
+		"included": [{"type": "users", "id": "u1"}, {"type": "users", "id": "u2"}]
+	}`
+
+	_, err = NewDecoder(strings.NewReader(payload), schema).Decode(nil)
+	assert.Equal(Errors{
+		withPointer(NewErrUnlinkedIncludedResource("users", "u1"), "/included/0"),
+		withPointer(NewErrUnlinkedIncludedResource("users", "u2"), "/included/1"),
+	}, err)
+}
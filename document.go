@@ -1,9 +1,8 @@
 package jsonapi
 
 import (
-	"encoding/json"
-	"errors"
-	"sort"
+	"bytes"
+	"strconv"
 )
 
 // A Document represents a JSON:API document.
@@ -22,7 +21,8 @@ type Document struct {
 	RelData map[string][]string
 
 	// Top-level members
-	Meta Meta
+	Meta    Meta
+	JSONAPI JSONAPI
 
 	// Errors
 	Errors []Error
@@ -31,6 +31,44 @@ type Document struct {
 	PrePath string
 }
 
+// JSONAPI is the top-level jsonapi object of a document.
+//
+// When a document is marshaled, Version defaults to "1.0", or to "1.1" if the
+// object has extensions or profiles since they were introduced in that
+// version.
+type JSONAPI struct {
+	Version string `json:"version,omitempty"`
+
+	// Ext and Profile hold the URIs of the extensions and profiles that
+	// apply to the document.
+	Ext     []string `json:"ext,omitempty"`
+	Profile []string `json:"profile,omitempty"`
+
+	Meta Meta `json:"meta,omitempty"`
+}
+
+// CheckExt returns an error if j advertises an extension that is not in ext,
+// which usually holds the extensions negotiated for a request (see
+// Negotiator). The source pointer of the error indicates the extension.
+func (j JSONAPI) CheckExt(ext []string) error {
+	for i, uri := range j.Ext {
+		found := false
+
+		for _, e := range ext {
+			found = found || e == uri
+		}
+
+		if !found {
+			return withPointer(
+				NewErrUnappliedExtension(uri),
+				"/jsonapi/ext/"+strconv.Itoa(i),
+			)
+		}
+	}
+
+	return nil
+}
+
 // Include adds res to the set of resources to be included under the included
 // top-level field.
 //
@@ -75,183 +113,160 @@ func (d *Document) Include(res Resource) {
 
 // MarshalDocument marshals a document according to the JSON:API speficication.
 //
+// If the primary data is a paginated collection (page[size] is set), the
+// first, prev, next, and last links are added, unless doc.Links already
+// contains one of them. The last link requires the collection to know its
+// total (see TotalHolder), in which case the total and the number of pages
+// are also added to the meta object as "total" and "pages". Members already
+// found in doc.Links or doc.Meta are not replaced.
+//
 // Both doc and url must not be nil.
 func MarshalDocument(doc *Document, url *URL) ([]byte, error) {
-	var err error
-
-	// Data
-	var data json.RawMessage
-
-	switch d := doc.Data.(type) {
-	case Resource:
-		data = MarshalResource(
-			d,
-			doc.PrePath,
-			url.Params.Fields[d.GetType().Name],
-			doc.RelData,
-		)
-	case Collection:
-		data = MarshalCollection(
-			d,
-			doc.PrePath,
-			url.Params.Fields,
-			doc.RelData,
-		)
-	case Identifier:
-		data, err = json.Marshal(d)
-	case Identifiers:
-		data, err = json.Marshal(d)
-	default:
-		if doc.Data != nil {
-			err = errors.New("data contains an unknown type")
-		} else if len(doc.Errors) == 0 {
-			data = []byte("null")
-		}
-	}
-
-	// Data
-	var errors json.RawMessage
-	if len(doc.Errors) > 0 {
-		// Errors
-		errors, err = json.Marshal(doc.Errors)
-	}
+	buf := &bytes.Buffer{}
 
+	err := NewEncoder(buf).Encode(doc, url)
 	if err != nil {
 		return []byte{}, err
 	}
 
-	// Included
-	var inclusions []*json.RawMessage
-
-	if len(doc.Included) > 0 {
-		sort.Slice(doc.Included, func(i, j int) bool {
-			return doc.Included[i].Get("id").(string) < doc.Included[j].Get("id").(string)
-		})
-
-		if len(data) > 0 {
-			for key := range doc.Included {
-				typ := doc.Included[key].GetType().Name
-				raw := MarshalResource(
-					doc.Included[key],
-					doc.PrePath,
-					url.Params.Fields[typ],
-					doc.RelData,
-				)
-				rawm := json.RawMessage(raw)
-				inclusions = append(inclusions, &rawm)
-			}
-		}
-	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalDocument reads a payload to build and return a Document object.
+//
+// The full linkage of the included resources is checked (see
+// CheckFullLinkage).
+//
+// schema must not be nil.
+func UnmarshalDocument(payload []byte, schema *Schema) (*Document, error) {
+	u := &unmarshaler{schema: schema}
 
-	// Marshaling
-	plMap := map[string]any{}
+	return u.document(payload)
+}
 
-	if len(errors) > 0 {
-		plMap["errors"] = errors
-	} else if len(data) > 0 {
-		plMap["data"] = data
+// UnmarshalDocumentAll is like UnmarshalDocument, but it does not stop at the
+// first problem found in the primary data or the included resources.
+//
+// If the payload contains problems, the returned error is of type Errors and
+// holds one Error per problem, each with a source pointer (like
+// "/data/attributes/title"). It can be used as is for the errors of a
+// document.
+//
+// schema must not be nil.
+func UnmarshalDocumentAll(payload []byte, schema *Schema) (*Document, error) {
+	u := &unmarshaler{schema: schema, collect: true}
 
-		if len(inclusions) > 0 {
-			plMap["included"] = inclusions
-		}
+	doc, err := u.document(payload)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(doc.Meta) > 0 {
-		plMap["meta"] = doc.Meta
+	if err = u.err(); err != nil {
+		return nil, err
 	}
 
-	links := doc.Links
+	return doc, nil
+}
 
-	if url != nil {
-		if links == nil {
-			links = map[string]Link{}
+// addPagination adds the pagination links of the page described by cont, which
+// is found at url, to links, as well as its total and number of pages to meta
+// if they are known. Nothing is added if the collection is not paginated.
+//
+// Both the page number and the cursor strategies are supported. With cursors,
+// the prev and next links are only omitted when the page is empty since cont
+// does not tell whether there are more resources.
+func addPagination(links map[string]Link, meta Meta, cont pageContent, url *URL, prepath string) {
+	page := url.Params.Page
+
+	size, ok := page["size"].(int)
+	if !ok || size <= 0 {
+		return
+	}
+
+	total := cont.total
+
+	if total >= 0 {
+		if _, ok := meta["total"]; !ok {
+			meta["total"] = total
 		}
 
-		links["self"] = Link{
-			HRef: doc.PrePath + url.String(),
+		if _, ok := meta["pages"]; !ok {
+			meta["pages"] = (total + size - 1) / size
 		}
 	}
 
-	if links != nil {
-		plMap["links"] = links
+	for _, name := range []string{"first", "prev", "next", "last"} {
+		if _, ok := links[name]; ok {
+			return
+		}
 	}
 
-	plMap["jsonapi"] = map[string]string{"version": "1.0"}
+	_, after := page["after"]
+	_, before := page["before"]
 
-	return json.Marshal(plMap)
-}
+	if after || before {
+		links["first"] = pageLink(url, prepath, map[string]any{"size": size})
 
-// UnmarshalDocument reads a payload to build and return a Document object.
-//
-// schema must not be nil.
-func UnmarshalDocument(payload []byte, schema *Schema) (*Document, error) {
-	doc := &Document{
-		Included:  []Resource{},
-		Resources: map[string]map[string]struct{}{},
-		Links:     map[string]Link{},
-		RelData:   map[string][]string{},
-		Meta:      map[string]any{},
-	}
-	ske := &payloadSkeleton{}
+		if cont.len > 0 {
+			rules := url.Params.SortingRules
 
-	// Unmarshal
-	err := json.Unmarshal(payload, ske)
-	if err != nil {
-		return nil, err
+			links["prev"] = pageLink(url, prepath, map[string]any{
+				"size":   size,
+				"before": MarshalCursor(cont.first, rules),
+			})
+			links["next"] = pageLink(url, prepath, map[string]any{
+				"size":  size,
+				"after": MarshalCursor(cont.last, rules),
+			})
+		}
+
+		return
 	}
 
-	// Data
-	switch {
-	case len(ske.Data) > 0:
-		switch {
-		case ske.Data[0] == '{':
-			// Resource
-			res, err := UnmarshalResource(ske.Data, schema)
-			if err != nil {
-				return nil, err
-			}
+	num, _ := page["number"].(int)
+	numLink := func(num int) Link {
+		return pageLink(url, prepath, map[string]any{"size": size, "number": num})
+	}
 
-			doc.Data = res
-		case ske.Data[0] == '[':
-			col, err := UnmarshalCollection(ske.Data, schema)
-			if err != nil {
-				return nil, err
-			}
+	links["first"] = numLink(0)
 
-			doc.Data = col
-		case string(ske.Data) == "null":
-			doc.Data = nil
-		default:
-			// TODO Not exactly the right error
-			return nil, NewErrMissingDataMember()
-		}
-	case len(ske.Errors) > 0:
-		doc.Errors = ske.Errors
+	if num > 0 {
+		links["prev"] = numLink(num - 1)
 	}
 
-	// Included
-	if len(ske.Included) > 0 {
-		incs := make([]Identifier, len(ske.Included))
-
-		for i, rawInc := range ske.Included {
-			err = json.Unmarshal(rawInc, &incs[i])
-			if err != nil {
-				return nil, err
-			}
+	if total >= 0 {
+		last := 0
+		if total > 0 {
+			last = (total - 1) / size
 		}
 
-		for i := range incs {
-			res, err := UnmarshalResource(ske.Included[i], schema)
-			if err != nil {
-				return nil, err
-			}
-
-			doc.Included = append(doc.Included, res)
+		if num < last {
+			links["next"] = numLink(num + 1)
 		}
+
+		links["last"] = numLink(last)
+	} else if cont.len >= size {
+		links["next"] = numLink(num + 1)
 	}
+}
 
-	// Meta
-	doc.Meta = ske.Meta
+// pageContent describes the resources of a page: how many there are, the first
+// and last ones, and the total number of resources in the collection, which is
+// negative if unknown.
+type pageContent struct {
+	len         int
+	first, last Resource
+	total       int
+}
 
-	return doc, nil
+// pageLink returns a link to the page defined by page of the collection found
+// at url.
+func pageLink(url *URL, prepath string, page map[string]any) Link {
+	params := *url.Params
+	params.Page = page
+
+	u := *url
+	u.Params = &params
+
+	return Link{HRef: prepath + u.String()}
 }
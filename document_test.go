@@ -3,6 +3,7 @@ package jsonapi_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"path/filepath"
 	"strings"
@@ -322,6 +323,147 @@ func TestMarshalDocument(t *testing.T) {
 	}
 }
 
+func TestMarshalDocumentPagination(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+	typ := schema.GetType("users")
+
+	newCol := func(total int, ids ...string) Collection {
+		col := &SoftCollection{Type: &typ}
+		col.SetTotal(total)
+
+		for _, id := range ids {
+			res := &SoftResource{Type: &typ}
+			res.SetID(id)
+			col.Add(res)
+		}
+
+		return col
+	}
+
+	tests := []struct {
+		name          string
+		url           string
+		col           Collection
+		links         map[string]Link
+		expectedLinks map[string]string
+		expectedMeta  map[string]any
+	}{
+		{
+			name: "not paginated",
+			url:  "/users",
+			col:  newCol(3, "u1", "u2", "u3"),
+			expectedLinks: map[string]string{
+				"self": "",
+			},
+		}, {
+			name: "middle page with total",
+			url:  "/users?page[size]=2&page[number]=1",
+			col:  newCol(5, "u3", "u4"),
+			expectedLinks: map[string]string{
+				"self":  "page[number]=1&page[size]=2",
+				"first": "page[number]=0&page[size]=2",
+				"prev":  "page[number]=0&page[size]=2",
+				"next":  "page[number]=2&page[size]=2",
+				"last":  "page[number]=2&page[size]=2",
+			},
+			expectedMeta: map[string]any{"total": 5.0, "pages": 3.0},
+		}, {
+			name: "last page with total",
+			url:  "/users?page[size]=2&page[number]=2",
+			col:  newCol(5, "u5"),
+			expectedLinks: map[string]string{
+				"self":  "page[number]=2&page[size]=2",
+				"first": "page[number]=0&page[size]=2",
+				"prev":  "page[number]=1&page[size]=2",
+				"last":  "page[number]=2&page[size]=2",
+			},
+			expectedMeta: map[string]any{"total": 5.0, "pages": 3.0},
+		}, {
+			name: "empty collection with total",
+			url:  "/users?page[size]=2",
+			col:  newCol(0),
+			expectedLinks: map[string]string{
+				"self":  "page[size]=2",
+				"first": "page[number]=0&page[size]=2",
+				"last":  "page[number]=0&page[size]=2",
+			},
+			expectedMeta: map[string]any{"total": 0.0, "pages": 0.0},
+		}, {
+			name: "full page without total",
+			url:  "/users?page[size]=2",
+			col:  newCol(-1, "u1", "u2"),
+			expectedLinks: map[string]string{
+				"self":  "page[size]=2",
+				"first": "page[number]=0&page[size]=2",
+				"next":  "page[number]=1&page[size]=2",
+			},
+		}, {
+			name: "partial page without total",
+			url:  "/users?page[size]=2&page[number]=3",
+			col:  newCol(-1, "u1"),
+			expectedLinks: map[string]string{
+				"self":  "page[number]=3&page[size]=2",
+				"first": "page[number]=0&page[size]=2",
+				"prev":  "page[number]=2&page[size]=2",
+			},
+		}, {
+			name: "cursors",
+			url:  "/users?page[size]=2&page[after]=abc",
+			col:  newCol(-1, "u1", "u2"),
+			expectedLinks: map[string]string{
+				"self":  "page[after]=abc&page[size]=2",
+				"first": "page[size]=2",
+				"prev":  "page[before]=",
+				"next":  "page[after]=",
+			},
+		}, {
+			name: "links provided",
+			url:  "/users?page[size]=2",
+			col:  newCol(5, "u1", "u2"),
+			links: map[string]Link{
+				"next": {HRef: "/next"},
+			},
+			expectedLinks: map[string]string{
+				"self": "page[size]=2",
+				"next": "/next",
+			},
+			expectedMeta: map[string]any{"total": 5.0, "pages": 3.0},
+		},
+	}
+
+	for _, test := range tests {
+		url, err := NewURLFromRaw(schema, test.url)
+		assert.NoError(err, test.name)
+
+		doc := &Document{Data: test.col, Links: test.links}
+
+		pl, err := MarshalDocument(doc, url)
+		assert.NoError(err, test.name)
+
+		out := struct {
+			Links map[string]string `json:"links"`
+			Meta  map[string]any    `json:"meta"`
+		}{}
+		assert.NoError(json.Unmarshal(pl, &out), test.name)
+
+		assert.Len(out.Links, len(test.expectedLinks), test.name)
+
+		for name, query := range test.expectedLinks {
+			assert.Contains(out.Links, name, test.name)
+			assert.Contains(
+				strings.NewReplacer("%5B", "[", "%5D", "]").Replace(out.Links[name]),
+				query,
+				test.name,
+			)
+		}
+
+		assert.Equal(test.expectedMeta, out.Meta, test.name)
+		assert.Len(doc.Links, len(test.links), test.name)
+	}
+}
+
 func TestMarshalInvalidDocuments(t *testing.T) {
 	// TODO Describe how this test suite works
 	// Setup
@@ -501,6 +643,42 @@ func TestUnmarshalDocument(t *testing.T) {
 		}
 	})
 
+	t.Run("local ids", func(t *testing.T) {
+		assert := assert.New(t)
+
+		payload := []byte(`{
+			"data": {
+				"type": "mocktype",
+				"lid": "local1",
+				"relationships": {
+					"to-1": {"data": {"type": "mocktype", "lid": "local2"}},
+					"to-x": {"data": [{"type": "mocktype", "lid": "local2"}]}
+				}
+			},
+			"included": [{"type": "mocktype", "lid": "local2"}]
+		}`)
+
+		doc, err := UnmarshalDocument(payload, schema)
+		assert.NoError(err)
+
+		res := doc.Data.(Resource)
+		assert.Equal("", res.Get("id"))
+		assert.Equal("local1", res.(LIDHolder).GetLID())
+		assert.Equal("local2", res.Get("to-1"))
+		assert.Equal([]string{"local2"}, res.Get("to-x"))
+		assert.Equal("local2", doc.Included[0].(LIDHolder).GetLID())
+
+		// The local IDs are part of the marshaled document
+		url, _ := NewURLFromRaw(schema, "/mocktype")
+		doc.RelData = map[string][]string{}
+
+		payload, err = MarshalDocument(doc, url)
+		assert.NoError(err)
+		assert.Contains(string(payload), `"lid":"local1"`)
+		assert.Contains(string(payload), `"lid":"local2"`)
+		assert.NotContains(string(payload), `"id":""`)
+	})
+
 	t.Run("errors (Unmarshal)", func(t *testing.T) {
 		assert := assert.New(t)
 
@@ -523,6 +701,44 @@ func TestUnmarshalDocument(t *testing.T) {
 		assert.Equal(doc.Data, doc2.Data)
 	})
 
+	t.Run("error source pointers (Unmarshal)", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tests := []struct {
+			payload string
+			pointer string
+		}{
+			{
+				payload: `{"data":"invaliddata"}`,
+				pointer: "/data",
+			}, {
+				payload: `{"data":[{"id":"1","type":"mocktype"},{"id":true}]}`,
+				pointer: "/data/1",
+			}, {
+				payload: `{"data":{"id":"1","type":"mocktype","attributes":{"a/b":1}}}`,
+				pointer: "/data/attributes/a~1b",
+			}, {
+				payload: `{"data":{"id":"1","type":"mocktype","attributes":{"int8":"abc"}}}`,
+				pointer: "/data/attributes/int8",
+			}, {
+				payload: `{"data":{"type":"mocktype","relationships":{"to-1":{"data":0}}}}`,
+				pointer: "/data/relationships/to-1/data",
+			}, {
+				payload: `{"data":null,"included":[{"id":"1","type":"mocktype"},{"attributes":1}]}`,
+				pointer: "/included/1",
+			},
+		}
+
+		for _, test := range tests {
+			_, err := UnmarshalDocument([]byte(test.payload), schema)
+
+			var e Error
+			if assert.True(errors.As(err, &e), test.payload) {
+				assert.Equal(test.pointer, e.Source["pointer"], test.payload)
+			}
+		}
+	})
+
 	t.Run("invalid payloads (Unmarshal)", func(t *testing.T) {
 		assert := assert.New(t)
 
@@ -592,6 +808,72 @@ func TestUnmarshalDocument(t *testing.T) {
 	})
 }
 
+func TestDocumentJSONAPI(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+	url, _ := NewURLFromRaw(schema, "/users")
+
+	tests := []struct {
+		name     string
+		jsonapi  JSONAPI
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: `{"version":"1.0"}`,
+		}, {
+			name:     "meta",
+			jsonapi:  JSONAPI{Meta: Meta{"key": "value"}},
+			expected: `{"version":"1.0","meta":{"key":"value"}}`,
+		}, {
+			name:     "profile",
+			jsonapi:  JSONAPI{Profile: []string{"https://example.com/profile"}},
+			expected: `{"version":"1.1","profile":["https://example.com/profile"]}`,
+		}, {
+			name: "all members",
+			jsonapi: JSONAPI{
+				Version: "1.2",
+				Ext:     []string{AtomicExt},
+				Profile: []string{"https://example.com/profile"},
+				Meta:    Meta{"key": "value"},
+			},
+			expected: `{
+				"version": "1.2",
+				"ext": ["https://jsonapi.org/ext/atomic"],
+				"profile": ["https://example.com/profile"],
+				"meta": {"key": "value"}
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		payload, err := MarshalDocument(&Document{Data: &Resources{}, JSONAPI: test.jsonapi}, url)
+		assert.NoError(err, test.name)
+
+		ske := map[string]json.RawMessage{}
+		_ = json.Unmarshal(payload, &ske)
+		assert.JSONEq(test.expected, string(ske["jsonapi"]), test.name)
+
+		doc, err := UnmarshalDocument(payload, schema)
+		assert.NoError(err, test.name)
+
+		expected := test.jsonapi
+		_ = json.Unmarshal(ske["jsonapi"], &expected)
+		assert.Equal(expected, doc.JSONAPI, test.name)
+	}
+
+	// CheckExt
+	jsonapi := JSONAPI{Ext: []string{AtomicExt, "https://example.com/ext"}}
+
+	assert.NoError(jsonapi.CheckExt([]string{"https://example.com/ext", AtomicExt}))
+	assert.Equal(
+		withPointer(NewErrUnappliedExtension("https://example.com/ext"), "/jsonapi/ext/1"),
+		jsonapi.CheckExt([]string{AtomicExt}),
+	)
+	assert.NoError(JSONAPI{}.CheckExt(nil))
+}
+
 func newResource(typ *Type, id string) Resource {
 	res := &SoftResource{}
 	res.SetType(typ)
@@ -599,3 +881,94 @@ func newResource(typ *Type, id string) Resource {
 
 	return res
 }
+
+func TestUnmarshalDocumentAll(t *testing.T) {
+	// Setup
+	typ, _ := BuildType(mocktype{})
+	typ.NewFunc = func() Resource {
+		return Wrap(&mocktype{})
+	}
+	schema := &Schema{Types: []Type{typ}}
+
+	t.Run("valid document", func(t *testing.T) {
+		assert := assert.New(t)
+
+		payload := []byte(`{
+			"data": [{"type": "mocktype", "id": "id1", "attributes": {"str": "abc"}}],
+			"included": [{"type": "mocktype", "id": "id2"}]
+		}`)
+
+		doc, err := UnmarshalDocumentAll(payload, schema)
+		assert.NoError(err)
+		assert.Equal(1, doc.Data.(Collection).Len())
+		assert.Equal("abc", doc.Data.(Collection).At(0).Get("str"))
+		assert.Len(doc.Included, 1)
+	})
+
+	t.Run("invalid document", func(t *testing.T) {
+		assert := assert.New(t)
+
+		payload := []byte(`{
+			"data": [{
+				"type": "mocktype",
+				"id": "id1",
+				"attributes": {
+					"str": 1,
+					"int": "not an int",
+					"unknown": "abc"
+				},
+				"relationships": {
+					"to-1": {"data": "not an identifier"},
+					"unknown": {"data": null}
+				}
+			}, {
+				"type": "unknown",
+				"id": "id2"
+			}],
+			"included": [
+				{"type": "mocktype", "id": "id3", "attributes": {"bool": "yes"}},
+				"not a resource"
+			]
+		}`)
+
+		_, err := UnmarshalDocumentAll(payload, schema)
+
+		var errs Errors
+		assert.True(errors.As(err, &errs))
+
+		pointers := []string{}
+		for _, e := range errs {
+			assert.Equal("400", e.Status)
+			pointers = append(pointers, e.Source["pointer"].(string))
+		}
+
+		assert.Equal([]string{
+			"/data/0/attributes/int",
+			"/data/0/attributes/str",
+			"/data/0/attributes/unknown",
+			"/data/0/relationships/to-1/data",
+			"/data/0/relationships/unknown",
+			"/data/1/type",
+			"/included/0/attributes/bool",
+			"/included/1",
+		}, pointers)
+
+		// Only the first error is returned otherwise
+		_, err = UnmarshalDocument(payload, schema)
+		assert.EqualError(
+			err,
+			"400 Bad Request: The field value is invalid for the expected type.",
+		)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := UnmarshalDocumentAll([]byte(`{`), schema)
+
+		var errs Errors
+		assert.True(errors.As(err, &errs))
+		assert.Len(errs, 1)
+		assert.Equal("", errs[0].Source["pointer"])
+	})
+}
@@ -0,0 +1,305 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+)
+
+// A ResourceIterator provides the resources of a collection one at a time.
+//
+// Next returns the next resource, or io.EOF when there are no more resources.
+// It can be used as the primary data of a document written by an Encoder. If
+// it also implements TotalHolder, the total is used like it would be for a
+// Collection.
+type ResourceIterator interface {
+	Next() (Resource, error)
+}
+
+// An Encoder writes JSON:API documents to an output stream.
+//
+// Unlike MarshalDocument, which holds the whole payload in memory, an Encoder
+// marshals and writes the resources of the primary data one at a time.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes doc to the stream. The output is identical to the payload
+// returned by MarshalDocument, which also means no newline is added.
+//
+// The primary data can also be a ResourceIterator, in which case the resources
+// are pulled until it returns io.EOF. Any other error stops the encoding.
+//
+// The full linkage of the included resources is checked with CheckFullLinkage
+// before they are written, and the violations are returned as Errors.
+//
+// Since the document is written as it is built, a partial document might have
+// been written when an error is returned.
+func (e *Encoder) Encode(doc *Document, url *URL) error {
+	var (
+		data []byte
+		it   ResourceIterator
+		err  error
+	)
+
+	lc := newLinkageChecker(doc.RelData)
+
+	switch d := doc.Data.(type) {
+	case Resource:
+		lc.addPrimary(d)
+
+		data = MarshalResource(
+			d,
+			doc.PrePath,
+			url.Params.Fields[d.GetType().Name],
+			doc.RelData,
+		)
+	case Collection:
+		it = &collectionIterator{col: d}
+	case ResourceIterator:
+		it = d
+	case Identifier:
+		data, err = json.Marshal(d)
+	case Identifiers:
+		data, err = json.Marshal(d)
+	default:
+		if doc.Data != nil {
+			err = errors.New("data contains an unknown type")
+		} else if len(doc.Errors) == 0 {
+			data = []byte("null")
+		}
+	}
+
+	// Errors
+	var errs []byte
+	if len(doc.Errors) > 0 {
+		errs, err = json.Marshal(doc.Errors)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	w := &encoderWriter{w: e.w}
+	cont := pageContent{total: -1}
+
+	w.writeString("{")
+
+	if len(errs) > 0 {
+		w.writeString(`"errors":`)
+		w.write(errs)
+	} else if len(data) > 0 || it != nil {
+		w.writeString(`"data":`)
+
+		if it != nil {
+			cont, err = e.encodeCollection(w, it, lc, doc, url)
+			if err != nil {
+				return err
+			}
+		} else {
+			w.write(data)
+		}
+
+		if len(doc.Included) > 0 {
+			sort.Slice(doc.Included, func(i, j int) bool {
+				return doc.Included[i].Get("id").(string) < doc.Included[j].Get("id").(string)
+			})
+
+			for i, inc := range doc.Included {
+				lc.addIncluded(inc, i)
+			}
+
+			if errs := lc.check(); len(errs) > 0 {
+				return errs
+			}
+
+			w.writeString(`,"included":[`)
+
+			for i, inc := range doc.Included {
+				if i > 0 {
+					w.writeString(",")
+				}
+
+				w.write(MarshalResource(
+					inc,
+					doc.PrePath,
+					url.Params.Fields[inc.GetType().Name],
+					doc.RelData,
+				))
+			}
+
+			w.writeString("]")
+		}
+	}
+
+	jsonapi := doc.JSONAPI
+
+	switch {
+	case jsonapi.Version != "":
+	case len(jsonapi.Ext) > 0 || len(jsonapi.Profile) > 0:
+		jsonapi.Version = "1.1"
+	default:
+		jsonapi.Version = "1.0"
+	}
+
+	pl, err := json.Marshal(jsonapi)
+	if err != nil {
+		return err
+	}
+
+	w.writeString(`,"jsonapi":`)
+	w.write(pl)
+
+	// Links and meta
+	meta := doc.Meta
+	links := doc.Links
+
+	if url != nil {
+		meta = Meta{}
+		for k, v := range doc.Meta {
+			meta[k] = v
+		}
+
+		links = map[string]Link{}
+		for k, v := range doc.Links {
+			links[k] = v
+		}
+
+		if it != nil && url.IsCol {
+			addPagination(links, meta, cont, url, doc.PrePath)
+		}
+
+		links["self"] = Link{
+			HRef: doc.PrePath + url.String(),
+		}
+	}
+
+	if links != nil {
+		pl, err := json.Marshal(links)
+		if err != nil {
+			return err
+		}
+
+		w.writeString(`,"links":`)
+		w.write(pl)
+	}
+
+	if len(meta) > 0 {
+		pl, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+
+		w.writeString(`,"meta":`)
+		w.write(pl)
+	}
+
+	w.writeString("}")
+
+	return w.err
+}
+
+// encodeCollection writes the resources provided by it as a JSON array and
+// returns a description of them. The resources are added to lc as primary
+// data.
+func (e *Encoder) encodeCollection(
+	w *encoderWriter,
+	it ResourceIterator,
+	lc *linkageChecker,
+	doc *Document,
+	url *URL,
+) (pageContent, error) {
+	cont := pageContent{total: -1}
+
+	if th, ok := it.(TotalHolder); ok {
+		cont.total = th.Total()
+	}
+
+	w.writeString("[")
+
+	for w.err == nil {
+		res, err := it.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return cont, err
+		}
+
+		if cont.len > 0 {
+			w.writeString(",")
+		} else {
+			cont.first = res
+		}
+
+		cont.last = res
+		cont.len++
+
+		lc.addPrimary(res)
+
+		w.write(MarshalResource(
+			res,
+			doc.PrePath,
+			url.Params.Fields[res.GetType().Name],
+			doc.RelData,
+		))
+	}
+
+	w.writeString("]")
+
+	return cont, w.err
+}
+
+// encoderWriter writes to w until an error occurs, after which nothing is
+// written and err holds the error.
+type encoderWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *encoderWriter) write(b []byte) {
+	if w.err == nil {
+		_, w.err = w.w.Write(b)
+	}
+}
+
+func (w *encoderWriter) writeString(s string) {
+	if w.err == nil {
+		_, w.err = io.WriteString(w.w, s)
+	}
+}
+
+// collectionIterator is a ResourceIterator over the resources of col. It
+// reports the total of col if it is a TotalHolder.
+type collectionIterator struct {
+	col Collection
+	i   int
+}
+
+// Next implements ResourceIterator.
+func (c *collectionIterator) Next() (Resource, error) {
+	if c.i >= c.col.Len() {
+		return nil, io.EOF
+	}
+
+	c.i++
+
+	return c.col.At(c.i - 1), nil
+}
+
+// Total implements TotalHolder.
+func (c *collectionIterator) Total() int {
+	if th, ok := c.col.(TotalHolder); ok {
+		return th.Total()
+	}
+
+	return -1
+}
+
+// SetTotal implements TotalHolder. It does nothing.
+func (c *collectionIterator) SetTotal(int) {}
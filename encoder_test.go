@@ -0,0 +1,166 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoder(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+	typ := schema.GetType("users")
+
+	col := &SoftCollection{Type: &typ}
+	col.SetTotal(10)
+
+	for i := 0; i < 3; i++ {
+		res := &SoftResource{Type: &typ}
+		res.SetID("u" + strconv.Itoa(i))
+		res.Set("name", "<name "+strconv.Itoa(i)+">")
+		col.Add(res)
+	}
+
+	artType := schema.GetType("articles")
+	article := &SoftResource{Type: &artType}
+	article.SetID("a1")
+	article.Set("title", "Title")
+	article.Set("author", "u0")
+	article.Set("related", []string{"a2"})
+
+	related := &SoftResource{Type: &artType}
+	related.SetID("a2")
+	related.Set("author", "u2")
+
+	colURL, _ := NewURLFromRaw(schema, "/users?page[size]=3&page[number]=1")
+	resURL, _ := NewURLFromRaw(schema, "/articles/a1?include=author")
+
+	tests := []struct {
+		name string
+		doc  *Document
+		url  *URL
+	}{
+		{
+			name: "collection",
+			doc: &Document{
+				Data:  col,
+				Meta:  Meta{"key": "value"},
+				Links: map[string]Link{"about": {HRef: "/about"}},
+			},
+			url: colURL,
+		}, {
+			name: "empty collection",
+			doc:  &Document{Data: &Resources{}},
+			url:  colURL,
+		}, {
+			name: "resource with inclusions",
+			doc: &Document{
+				Data:     article,
+				Included: []Resource{col.At(2), related, col.At(0)},
+				RelData:  map[string][]string{"articles": {"author", "related"}},
+			},
+			url: resURL,
+		}, {
+			name: "null data",
+			doc:  &Document{},
+			url:  resURL,
+		}, {
+			name: "identifiers",
+			doc:  &Document{Data: Identifiers{{Type: "users", ID: "u1"}}},
+			url:  resURL,
+		}, {
+			name: "errors",
+			doc:  &Document{Errors: []Error{NewErrNotFound()}},
+		},
+	}
+
+	for _, test := range tests {
+		expected, err := MarshalDocument(test.doc, test.url)
+		assert.NoError(err, test.name)
+
+		buf := &bytes.Buffer{}
+		err = NewEncoder(buf).Encode(test.doc, test.url)
+		assert.NoError(err, test.name)
+		assert.Equal(string(expected), buf.String(), test.name)
+	}
+
+	// Iterator
+	expected, _ := MarshalDocument(&Document{Data: col}, colURL)
+
+	buf := &bytes.Buffer{}
+	err := NewEncoder(buf).Encode(&Document{Data: &iterator{col: col}}, colURL)
+	assert.NoError(err)
+	assert.Equal(string(expected), buf.String())
+
+	// Iterator error
+	err = NewEncoder(&bytes.Buffer{}).Encode(
+		&Document{Data: &iterator{col: col, err: errors.New("iterator error")}},
+		colURL,
+	)
+	assert.EqualError(err, "iterator error")
+
+	// Writer error
+	err = NewEncoder(badWriter{}).Encode(&Document{Data: col}, colURL)
+	assert.EqualError(err, "writer error")
+
+	// Unlinked included resource
+	err = NewEncoder(&bytes.Buffer{}).Encode(
+		&Document{
+			Data:     article,
+			Included: []Resource{col.At(1)},
+			RelData:  map[string][]string{"articles": {"author", "related"}},
+		},
+		resURL,
+	)
+	assert.Equal(
+		Errors{withPointer(NewErrUnlinkedIncludedResource("users", "u1"), "/included/0")},
+		err,
+	)
+
+	// Unknown data
+	err = NewEncoder(&bytes.Buffer{}).Encode(&Document{Data: "data"}, colURL)
+	assert.EqualError(err, "data contains an unknown type")
+}
+
+// iterator is a ResourceIterator over col that returns err instead of io.EOF
+// if it is not nil.
+type iterator struct {
+	col *SoftCollection
+	i   int
+	err error
+}
+
+func (it *iterator) Next() (Resource, error) {
+	if it.i >= it.col.Len() {
+		if it.err != nil {
+			return nil, it.err
+		}
+
+		return nil, io.EOF
+	}
+
+	it.i++
+
+	return it.col.At(it.i - 1), nil
+}
+
+func (it *iterator) Total() int {
+	return it.col.Total()
+}
+
+func (it *iterator) SetTotal(total int) {
+	it.col.SetTotal(total)
+}
+
+type badWriter struct{}
+
+func (badWriter) Write([]byte) (int, error) {
+	return 0, errors.New("writer error")
+}
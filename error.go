@@ -2,9 +2,11 @@ package jsonapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // An Error represents an error object from the JSON:API specification.
@@ -96,6 +98,20 @@ func (e Error) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m)
 }
 
+// Errors is a list of Error objects that can be used as an error.
+type Errors []Error
+
+// Error returns the string representations of the errors separated by
+// semicolons.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
 // NewErrBadRequest (400) returns the corresponding error.
 func NewErrBadRequest(title, detail string) Error {
 	e := NewError()
@@ -146,6 +162,21 @@ func NewErrInvalidPageSizeParameter(badPageSize string) Error {
 	return e
 }
 
+// NewErrInvalidPageCursorParameter (400) returns the corresponding error.
+//
+// param is the name of the parameter, like page[after] or page[before].
+func NewErrInvalidPageCursorParameter(param, badCursor string) Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusBadRequest)
+	e.Title = "Invalid page cursor parameter"
+	e.Detail = "The page cursor parameter is not a valid cursor for this collection."
+	e.Source["parameter"] = param
+	e.Meta["bad-page-cursor"] = badCursor
+
+	return e
+}
+
 // NewErrInvalidFieldValueInBody (400) returns the corresponding error.
 func NewErrInvalidFieldValueInBody(field string, badValue string, typ string) Error {
 	e := NewError()
@@ -160,6 +191,22 @@ func NewErrInvalidFieldValueInBody(field string, badValue string, typ string) Er
 	return e
 }
 
+// NewErrConstraintViolationInBody (422) returns the corresponding error.
+//
+// constraint is the name of the violated constraint as used in the api tag of
+// attributes, like "required" or "max".
+func NewErrConstraintViolationInBody(field, constraint, detail string) Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusUnprocessableEntity)
+	e.Title = "Constraint violation in body"
+	e.Detail = detail
+	e.Meta["field"] = field
+	e.Meta["constraint"] = constraint
+
+	return e
+}
+
 // NewErrDuplicateFieldInFieldsParameter (400) returns the corresponding error.
 func NewErrDuplicateFieldInFieldsParameter(typ string, field string) Error {
 	e := NewError()
@@ -180,6 +227,7 @@ func NewErrMissingDataMember() Error {
 	e.Status = strconv.Itoa(http.StatusBadRequest)
 	e.Title = "Missing data member"
 	e.Detail = "Missing data top-level member in payload."
+	e.Source["pointer"] = ""
 
 	return e
 }
@@ -191,7 +239,6 @@ func NewErrUnknownFieldInBody(typ, field string) Error {
 	e.Status = strconv.Itoa(http.StatusBadRequest)
 	e.Title = "Unknown field in body"
 	e.Detail = fmt.Sprintf("%q is not a known field.", field)
-	e.Source["pointer"] = "" // TODO
 	e.Meta["unknown-field"] = field
 	e.Meta["type"] = typ
 
@@ -253,6 +300,69 @@ func NewErrUnknownTypeInURL(typ string) Error {
 	return e
 }
 
+// NewErrUnknownTypeInBody (400) returns the corresponding error.
+func NewErrUnknownTypeInBody(typ string) Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusBadRequest)
+	e.Title = "Unknown type in body"
+	e.Detail = fmt.Sprintf("%q is not a known type.", typ)
+	e.Meta["unknown-type"] = typ
+
+	return e
+}
+
+// NewErrUnappliedExtension (400) returns the corresponding error.
+func NewErrUnappliedExtension(ext string) Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusBadRequest)
+	e.Title = "Unapplied extension"
+	e.Detail = fmt.Sprintf("The extension %q is not applied to the request.", ext)
+	e.Meta["extension"] = ext
+
+	return e
+}
+
+// NewErrDuplicateIncludedResource (400) returns the corresponding error.
+func NewErrDuplicateIncludedResource(typ, id string) Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusBadRequest)
+	e.Title = "Duplicate included resource"
+	e.Detail = fmt.Sprintf("The resource %q of type %q is included more than once.", id, typ)
+
+	return e
+}
+
+// NewErrIncludedPrimaryResource (400) returns the corresponding error.
+func NewErrIncludedPrimaryResource(typ, id string) Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusBadRequest)
+	e.Title = "Included primary resource"
+	e.Detail = fmt.Sprintf(
+		"The resource %q of type %q is part of the primary data and cannot be included.",
+		id, typ,
+	)
+
+	return e
+}
+
+// NewErrUnlinkedIncludedResource (400) returns the corresponding error.
+func NewErrUnlinkedIncludedResource(typ, id string) Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusBadRequest)
+	e.Title = "Unlinked included resource"
+	e.Detail = fmt.Sprintf(
+		"The resource %q of type %q is not linked to the primary data.",
+		id, typ,
+	)
+
+	return e
+}
+
 // NewErrUnknownFieldInFilterParameter (400) returns the corresponding error.
 func NewErrUnknownFieldInFilterParameter(field string) Error {
 	e := NewError()
@@ -351,6 +461,39 @@ func NewErrNotFound() Error {
 	return e
 }
 
+// NewErrMethodNotAllowed (405) returns the corresponding error.
+func NewErrMethodNotAllowed(method string) Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusMethodNotAllowed)
+	e.Title = "Method not allowed"
+	e.Detail = fmt.Sprintf("%q is not allowed on this URI.", method)
+	e.Meta["method"] = method
+
+	return e
+}
+
+// NewErrNotAcceptable (406) returns the corresponding error.
+func NewErrNotAcceptable() Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusNotAcceptable)
+	e.Title = "Not acceptable"
+
+	return e
+}
+
+// NewErrConflict (409) returns the corresponding error.
+func NewErrConflict(detail string) Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusConflict)
+	e.Title = "Conflict"
+	e.Detail = detail
+
+	return e
+}
+
 // NewErrPayloadTooLarge (413) returns the corresponding error.
 func NewErrPayloadTooLarge() Error {
 	e := NewError()
@@ -431,3 +574,24 @@ func NewErrNotImplemented() Error {
 
 	return e
 }
+
+// withPointer returns err with pointer prepended to its source pointer if err
+// is an Error. Otherwise, err is returned unchanged.
+func withPointer(err error, pointer string) error {
+	var e Error
+
+	if !errors.As(err, &e) {
+		return err
+	}
+
+	source := map[string]any{}
+	for k, v := range e.Source {
+		source[k] = v
+	}
+
+	prev, _ := source["pointer"].(string)
+	source["pointer"] = pointer + prev
+	e.Source = source
+
+	return e
+}
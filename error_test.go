@@ -81,6 +81,21 @@ func TestError(t *testing.T) {
 	}
 }
 
+func TestErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	var err error = Errors{
+		NewErrNotFound(),
+		NewErrBadRequest("Bad request", "Invalid parameter."),
+	}
+
+	assert.EqualError(
+		err,
+		"404 Not Found: The URI does not exist.; 400 Bad Request: Invalid parameter.",
+	)
+	assert.Equal("", Errors{}.Error())
+}
+
 func TestErrorConstructors(t *testing.T) {
 	assert := assert.New(t)
 
@@ -135,6 +150,13 @@ func TestErrorConstructors(t *testing.T) {
 			}(),
 			expected: "400 Bad Request: " +
 				"The field value is invalid for the expected type.",
+		}, {
+			name: "NewErrConstraintViolationInBody",
+			err: func() Error {
+				e := NewErrConstraintViolationInBody("field", "required", `"field" is required.`)
+				return e
+			}(),
+			expected: `422 Unprocessable Entity: "field" is required.`,
 		}, {
 			name: "NewErrDuplicateFieldInFieldsParameter",
 			err: func() Error {
@@ -185,6 +207,45 @@ func TestErrorConstructors(t *testing.T) {
 				return e
 			}(),
 			expected: "400 Bad Request: \"type\" is not a known type.",
+		}, {
+			name: "NewErrUnknownTypeInBody",
+			err: func() Error {
+				e := NewErrUnknownTypeInBody("type")
+				return e
+			}(),
+			expected: "400 Bad Request: \"type\" is not a known type.",
+		}, {
+			name: "NewErrUnappliedExtension",
+			err: func() Error {
+				e := NewErrUnappliedExtension("https://example.com/ext")
+				return e
+			}(),
+			expected: "400 Bad Request: The extension \"https://example.com/ext\" is not " +
+				"applied to the request.",
+		}, {
+			name: "NewErrDuplicateIncludedResource",
+			err: func() Error {
+				e := NewErrDuplicateIncludedResource("type", "id")
+				return e
+			}(),
+			expected: "400 Bad Request: " +
+				"The resource \"id\" of type \"type\" is included more than once.",
+		}, {
+			name: "NewErrIncludedPrimaryResource",
+			err: func() Error {
+				e := NewErrIncludedPrimaryResource("type", "id")
+				return e
+			}(),
+			expected: "400 Bad Request: The resource \"id\" of type \"type\" " +
+				"is part of the primary data and cannot be included.",
+		}, {
+			name: "NewErrUnlinkedIncludedResource",
+			err: func() Error {
+				e := NewErrUnlinkedIncludedResource("type", "id")
+				return e
+			}(),
+			expected: "400 Bad Request: " +
+				"The resource \"id\" of type \"type\" is not linked to the primary data.",
 		}, {
 			name: "NewErrUnknownFieldInFilterParameter",
 			err: func() Error {
@@ -241,6 +302,27 @@ func TestErrorConstructors(t *testing.T) {
 				return e
 			}(),
 			expected: "404 Not Found: The URI does not exist.",
+		}, {
+			name: "NewErrMethodNotAllowed",
+			err: func() Error {
+				e := NewErrMethodNotAllowed("PUT")
+				return e
+			}(),
+			expected: "405 Method Not Allowed: \"PUT\" is not allowed on this URI.",
+		}, {
+			name: "NewErrNotAcceptable",
+			err: func() Error {
+				e := NewErrNotAcceptable()
+				return e
+			}(),
+			expected: "406 Not Acceptable: Not acceptable",
+		}, {
+			name: "NewErrConflict",
+			err: func() Error {
+				e := NewErrConflict("The resource already exists.")
+				return e
+			}(),
+			expected: "409 Conflict: The resource already exists.",
 		}, {
 			name: "NewErrPayloadTooLarge",
 			err: func() Error {
@@ -2,6 +2,7 @@ package jsonapi
 
 import (
 	"encoding/json"
+	"reflect"
 	"sort"
 	"time"
 )
@@ -68,11 +69,16 @@ func (f *Filter) IsAllowed(res Resource) bool {
 		val = res.Get(f.Field)
 	}
 
+	// Relationships are filtered by the IDs of the related resources.
 	if rel, ok := res.Rels()[f.Field]; ok {
-		if rel.ToOne {
-			val = res.Get(f.Field).(string)
+		ids := relIdentifiers(res, rel).IDs()
+
+		if !rel.ToOne {
+			val = ids
+		} else if len(ids) > 0 {
+			val = ids[0]
 		} else {
-			val = res.Get(f.Field).([]string)
+			val = ""
 		}
 	}
 
@@ -134,6 +140,12 @@ func checkVal(op string, rval, cval any) bool {
 		return checkTime(op, rval, cval.(time.Time))
 	case []byte:
 		return checkBytes(op, rval, cval.([]byte))
+	case float32:
+		return checkFloat(op, float64(rval), float64(cval.(float32)))
+	case float64:
+		return checkFloat(op, rval, cval.(float64))
+	case Decimal:
+		return checkDecimal(op, rval, cval.(Decimal))
 	case *string:
 		if rval == nil || cval.(*string) == nil {
 			switch op {
@@ -316,10 +328,92 @@ func checkVal(op string, rval, cval any) bool {
 		}
 
 		return checkBytes(op, *rval, *cval.(*[]byte))
+	case *float32:
+		if rval == nil || cval.(*float32) == nil {
+			switch op {
+			case "=":
+				return rval == cval.(*float32)
+			case "!=":
+				return rval != cval.(*float32)
+			default:
+				return false
+			}
+		}
+
+		return checkFloat(op, float64(*rval), float64(*cval.(*float32)))
+	case *float64:
+		if rval == nil || cval.(*float64) == nil {
+			switch op {
+			case "=":
+				return rval == cval.(*float64)
+			case "!=":
+				return rval != cval.(*float64)
+			default:
+				return false
+			}
+		}
+
+		return checkFloat(op, *rval, *cval.(*float64))
+	case *Decimal:
+		if rval == nil || cval.(*Decimal) == nil {
+			switch op {
+			case "=":
+				return rval == cval.(*Decimal)
+			case "!=":
+				return rval != cval.(*Decimal)
+			default:
+				return false
+			}
+		}
+
+		return checkDecimal(op, *rval, *cval.(*Decimal))
+	case *[]string:
+		if rval == nil || cval.(*[]string) == nil {
+			switch op {
+			case "=":
+				return rval == cval.(*[]string)
+			case "!=":
+				return rval != cval.(*[]string)
+			default:
+				return false
+			}
+		}
+
+		return checkSlice(op, *rval, *cval.(*[]string))
+	case *[]float64:
+		if rval == nil || cval.(*[]float64) == nil {
+			switch op {
+			case "=":
+				return rval == cval.(*[]float64)
+			case "!=":
+				return rval != cval.(*[]float64)
+			default:
+				return false
+			}
+		}
+
+		return checkNumbers(op, *rval, *cval.(*[]float64))
+	case *map[string]any:
+		if rval == nil || cval.(*map[string]any) == nil {
+			switch op {
+			case "=":
+				return rval == cval.(*map[string]any)
+			case "!=":
+				return rval != cval.(*map[string]any)
+			default:
+				return false
+			}
+		}
+
+		return checkObject(op, *rval, *cval.(*map[string]any))
 	case []string:
 		return checkSlice(op, rval, cval.([]string))
+	case []float64:
+		return checkNumbers(op, rval, cval.([]float64))
+	case map[string]any:
+		return checkObject(op, rval, cval.(map[string]any))
 	default:
-		return false
+		return checkCustom(op, rval, cval)
 	}
 }
 
@@ -465,10 +559,38 @@ func checkBytes(op string, rval, cval []byte) bool {
 	}
 }
 
+func checkFloat(op string, rval, cval float64) bool {
+	switch op {
+	case "=":
+		return rval == cval
+	case "!=":
+		return rval != cval
+	case "<":
+		return rval < cval
+	case "<=":
+		return rval <= cval
+	case ">":
+		return rval > cval
+	case ">=":
+		return rval >= cval
+	default:
+		return false
+	}
+}
+
+func checkDecimal(op string, rval, cval Decimal) bool {
+	return checkInt(op, int64(rval.Cmp(cval)), 0)
+}
+
 func checkSlice(op string, rval, cval []string) bool {
 	equal := false
 
 	if len(rval) == len(cval) {
+		// The values are sorted in copies to leave the
+		// resource untouched.
+		rval = append([]string(nil), rval...)
+		cval = append([]string(nil), cval...)
+
 		sort.Strings(rval)
 		sort.Strings(cval)
 
@@ -492,6 +614,58 @@ func checkSlice(op string, rval, cval []string) bool {
 	}
 }
 
+func checkNumbers(op string, rval, cval []float64) bool {
+	equal := len(rval) == len(cval)
+
+	for i := 0; equal && i < len(rval); i++ {
+		equal = rval[i] == cval[i]
+	}
+
+	switch op {
+	case "=":
+		return equal
+	case "!=":
+		return !equal
+	default:
+		return false
+	}
+}
+
+func checkObject(op string, rval, cval map[string]any) bool {
+	switch op {
+	case "=":
+		return reflect.DeepEqual(rval, cval)
+	case "!=":
+		return !reflect.DeepEqual(rval, cval)
+	default:
+		return false
+	}
+}
+
+// checkCustom compares values of a registered attribute type, or pointers to
+// such values.
+func checkCustom(op string, rval, cval any) bool {
+	ct, v, null, ok := customValue(rval)
+	if !ok || reflect.TypeOf(rval) != reflect.TypeOf(cval) {
+		return false
+	}
+
+	_, v2, null2, _ := customValue(cval)
+
+	if null || null2 {
+		switch op {
+		case "=":
+			return null == null2
+		case "!=":
+			return null != null2
+		default:
+			return false
+		}
+	}
+
+	return checkInt(op, int64(ct.codec.CompareAttr(v, v2)), 0)
+}
+
 func checkIn(id string, ids []string) bool {
 	for i := range ids {
 		if id == ids[i] {
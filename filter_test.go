@@ -775,6 +775,96 @@ func TestFilterResource(t *testing.T) {
 		{rval: ptr([]byte{1}), op: ">=", cval: ptr([]byte{1}), expected: true},
 		{rval: ptr([]byte{1}), op: ">=", cval: ptr([]byte{2}), expected: false},
 
+		// float32
+		{rval: float32(1.5), op: "=", cval: float32(1.5), expected: true},
+		{rval: float32(1.5), op: "!=", cval: float32(1.5), expected: false},
+		{rval: float32(1.5), op: "<", cval: float32(2), expected: true},
+		{rval: float32(1.5), op: ">=", cval: float32(2), expected: false},
+
+		// float64
+		{rval: 1.5, op: "=", cval: 1.5, expected: true},
+		{rval: 1.5, op: "=", cval: 2.5, expected: false},
+		{rval: 1.5, op: "!=", cval: 2.5, expected: true},
+		{rval: 1.5, op: "<", cval: 2.5, expected: true},
+		{rval: 1.5, op: "<=", cval: 1.5, expected: true},
+		{rval: 1.5, op: ">", cval: 2.5, expected: false},
+		{rval: 1.5, op: ">=", cval: 0.5, expected: true},
+		{rval: 1.5, op: "invalid", cval: 1.5, expected: false},
+
+		// Decimal
+		{rval: Decimal("1.50"), op: "=", cval: Decimal("1.5"), expected: true},
+		{rval: Decimal("1.50"), op: "!=", cval: Decimal("1.5"), expected: false},
+		{rval: Decimal("1.50"), op: "<", cval: Decimal("1.51"), expected: true},
+		{rval: Decimal("1.50"), op: "<=", cval: Decimal("1e-1"), expected: false},
+		{rval: Decimal("-2"), op: ">", cval: Decimal("-10"), expected: true},
+		{rval: Decimal("-2"), op: ">=", cval: Decimal("-2.0"), expected: true},
+		{rval: Decimal("1"), op: "invalid", cval: Decimal("1"), expected: false},
+
+		// []string
+		{rval: []string{"a", "b"}, op: "=", cval: []string{"b", "a"}, expected: true},
+		{rval: []string{"a", "b"}, op: "!=", cval: []string{"a"}, expected: true},
+		{rval: []string{"a", "b"}, op: "<", cval: []string{"a", "c"}, expected: false},
+
+		// []float64
+		{rval: []float64{1, 2}, op: "=", cval: []float64{1, 2}, expected: true},
+		{rval: []float64{1, 2}, op: "=", cval: []float64{2, 1}, expected: false},
+		{rval: []float64{1, 2}, op: "!=", cval: []float64{1}, expected: true},
+		{rval: []float64{1, 2}, op: "<", cval: []float64{1, 3}, expected: false},
+
+		// map[string]any
+		{
+			rval:     map[string]any{"a": []any{"b"}},
+			op:       "=",
+			cval:     map[string]any{"a": []any{"b"}},
+			expected: true,
+		},
+		{
+			rval:     map[string]any{"a": []any{"b"}},
+			op:       "!=",
+			cval:     map[string]any{"a": "b"},
+			expected: true,
+		},
+		{rval: map[string]any{}, op: "<", cval: map[string]any{}, expected: false},
+
+		// *float32
+		{rval: nilptr("float32"), op: "=", cval: nilptr("float32"), expected: true},
+		{rval: ptr(float32(1)), op: "=", cval: nilptr("float32"), expected: false},
+		{rval: ptr(float32(1)), op: "<", cval: ptr(float32(2)), expected: true},
+
+		// *float64
+		{rval: nilptr("float64"), op: "=", cval: nilptr("float64"), expected: true},
+		{rval: nilptr("float64"), op: "!=", cval: ptr(1.5), expected: true},
+		{rval: nilptr("float64"), op: "<", cval: ptr(1.5), expected: false},
+		{rval: ptr(1.5), op: ">", cval: ptr(0.5), expected: true},
+
+		// *Decimal
+		{rval: nilptr("Decimal"), op: "=", cval: nilptr("Decimal"), expected: true},
+		{rval: nilptr("Decimal"), op: "=", cval: ptr(Decimal("1")), expected: false},
+		{rval: ptr(Decimal("1.0")), op: "=", cval: ptr(Decimal("1")), expected: true},
+
+		// *[]string
+		{rval: nilptr("[]string"), op: "=", cval: nilptr("[]string"), expected: true},
+		{rval: ptr([]string{"a"}), op: "=", cval: nilptr("[]string"), expected: false},
+		{rval: ptr([]string{"a"}), op: "=", cval: ptr([]string{"a"}), expected: true},
+
+		// *[]float64
+		{rval: nilptr("[]float64"), op: "!=", cval: nilptr("[]float64"), expected: false},
+		{rval: ptr([]float64{1}), op: "!=", cval: ptr([]float64{2}), expected: true},
+
+		// *map[string]any
+		{
+			rval:     nilptr("map[string]any"),
+			op:       "=",
+			cval:     nilptr("map[string]any"),
+			expected: true,
+		},
+		{
+			rval:     ptr(map[string]any{"a": "b"}),
+			op:       "=",
+			cval:     ptr(map[string]any{"a": "b"}),
+			expected: true,
+		},
+
 		// Invalid type
 		{rval: func() {}, op: "=", cval: func() {}, expected: false},
 	}
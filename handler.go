@@ -0,0 +1,907 @@
+package jsonapi
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// A Handler is an http.Handler that serves a JSON:API described by a schema.
+//
+// Each type of the schema is backed by a Store found in Stores under the name
+// of the type. The handler routes the request according to its URL (collection,
+// resource, related resources or relationship), applies the query parameters,
+// and writes a document with the appropriate status code. Errors are reported
+// as error documents.
+//
+// When a two-way relationship is modified, the inverse relationships of the
+// related resources are updated accordingly.
+type Handler struct {
+	Schema *Schema
+	Stores map[string]Store
+
+	// PrePath is prepended to the links found in the documents. It
+	// usually represents a scheme and a domain name.
+	PrePath string
+
+	// NewID returns the ID of a new resource when the client does not
+	// provide one. A random UUID is generated if NewID is nil.
+	NewID func() string
+
+	// Extensions and Profiles hold the URIs of the extensions and
+	// profiles that are supported. The applied ones are reported in the
+	// Content-Type header and the jsonapi object of the responses. See
+	// Negotiator.
+	Extensions []string
+	Profiles   []string
+
+	// CursorPagination makes the collections that are requested with
+	// page[size] paginated with cursors (page[after] and page[before])
+	// instead of page numbers. Cursors are always used when the request
+	// contains one, regardless of this field.
+	CursorPagination bool
+}
+
+// NewHandler returns a *Handler that serves schema using the given stores.
+//
+// stores maps type names to the Store that manages the resources of that type.
+func NewHandler(schema *Schema, stores map[string]Store) *Handler {
+	return &Handler{
+		Schema: schema,
+		Stores: stores,
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		status int
+		doc    *Document
+		url    *URL
+		body   []byte
+	)
+
+	n := &Negotiator{Extensions: h.Extensions, Profiles: h.Profiles}
+
+	ext, profile, err := n.Negotiate(r)
+	if err == nil {
+		url, body, err = h.parse(r, ext)
+	}
+
+	if err == nil {
+		status, doc, err = h.route(r.Method, url, body)
+	}
+
+	if err != nil {
+		status, doc = errorDocument(err)
+	} else if doc != nil {
+		doc.JSONAPI = JSONAPI{Ext: ext, Profile: profile}
+	}
+
+	h.write(w, status, doc, url)
+}
+
+// parse reads the body and the URL of the request. The body must not advertise
+// extensions other than ext in its jsonapi object.
+func (h *Handler) parse(r *http.Request, ext []string) (*URL, []byte, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(body) > 0 {
+		ske := struct {
+			JSONAPI JSONAPI `json:"jsonapi"`
+		}{}
+
+		// Invalid bodies are reported when the data is read.
+		_ = json.Unmarshal(body, &ske)
+
+		err = ske.JSONAPI.CheckExt(ext)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	su, err := NewSimpleURL(r.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	url, err := NewURL(h.Schema, su)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return url, body, nil
+}
+
+// route calls the method that handles the combination of the method and the
+// kind of URL.
+func (h *Handler) route(method string, url *URL, body []byte) (int, *Document, error) {
+	switch {
+	case url.RelKind == "self":
+		switch method {
+		case http.MethodGet:
+			return h.getRelationship(url)
+		case http.MethodPatch:
+			return h.updateRelationship(url, body)
+		case http.MethodPost, http.MethodDelete:
+			return h.updateToManyRelationship(method, url, body)
+		}
+	case url.RelKind == "related":
+		if method == http.MethodGet {
+			return h.getRelated(url)
+		}
+	case url.IsCol:
+		switch method {
+		case http.MethodGet:
+			return h.getCollection(url)
+		case http.MethodPost:
+			return h.createResource(url, body)
+		}
+	default:
+		switch method {
+		case http.MethodGet:
+			return h.getResource(url)
+		case http.MethodPatch:
+			return h.updateResource(url, body)
+		case http.MethodDelete:
+			return h.deleteResource(url)
+		}
+	}
+
+	return 0, nil, NewErrMethodNotAllowed(method)
+}
+
+func (h *Handler) getCollection(url *URL) (int, *Document, error) {
+	store, err := h.store(url.ResType)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if url.Params.FilterLabel != "" {
+		return 0, nil, NewErrUnknownFilterParameterLabel(url.Params.FilterLabel)
+	}
+
+	col, links, err := h.rangeCollection(url, store, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	doc := &Document{Data: col, Links: links}
+
+	err = h.include(doc, url.Params)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, doc, nil
+}
+
+func (h *Handler) getResource(url *URL) (int, *Document, error) {
+	res, err := h.resource(url.ResType, url.ResID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	doc := &Document{Data: res}
+
+	err = h.include(doc, url.Params)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, doc, nil
+}
+
+func (h *Handler) getRelated(url *URL) (int, *Document, error) {
+	parent, err := h.resource(url.BelongsToFilter.Type, url.BelongsToFilter.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if url.Rel.Polymorphic() {
+		return h.getPolymorphicRelated(url, parent)
+	}
+
+	store, err := h.store(url.Rel.ToType)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	doc := &Document{}
+
+	if url.Rel.ToOne {
+		if id := parent.Get(url.Rel.FromName).(string); id != "" {
+			res, err := store.Resource(id)
+			if err != nil {
+				return 0, nil, err
+			}
+
+			if res != nil {
+				doc.Data = res
+			}
+		}
+	} else {
+		ids := parent.Get(url.Rel.FromName).([]string)
+
+		if len(ids) == 0 {
+			doc.Data = &Resources{}
+		} else {
+			col, links, err := h.rangeCollection(url, store, ids)
+			if err != nil {
+				return 0, nil, err
+			}
+
+			doc.Data = col
+			doc.Links = links
+		}
+	}
+
+	err = h.include(doc, url.Params)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, doc, nil
+}
+
+// getPolymorphicRelated is like getRelated for polymorphic relationships.
+//
+// Each related resource is fetched from the store of its type, so the
+// collection is never paginated.
+func (h *Handler) getPolymorphicRelated(url *URL, parent Resource) (int, *Document, error) {
+	col := &Resources{}
+
+	for _, iden := range sortIdentifiers(relIdentifiers(parent, url.Rel)) {
+		store, err := h.store(iden.Type)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		res, err := store.Resource(iden.ID)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if res != nil {
+			*col = append(*col, res)
+		}
+	}
+
+	doc := &Document{Data: col}
+
+	if url.Rel.ToOne {
+		doc.Data = nil
+		if col.Len() > 0 {
+			doc.Data = col.At(0)
+		}
+	}
+
+	err := h.include(doc, url.Params)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, doc, nil
+}
+
+func (h *Handler) getRelationship(url *URL) (int, *Document, error) {
+	parent, err := h.resource(url.BelongsToFilter.Type, url.BelongsToFilter.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	doc := &Document{}
+	idens := relIdentifiers(parent, url.Rel)
+
+	if url.Rel.ToOne {
+		if len(idens) > 0 {
+			doc.Data = idens[0]
+		}
+	} else {
+		doc.Data = sortIdentifiers(idens)
+	}
+
+	return http.StatusOK, doc, nil
+}
+
+func (h *Handler) createResource(url *URL, body []byte) (int, *Document, error) {
+	store, err := h.store(url.ResType)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	data, iden, err := unmarshalPrimaryData(body, url.ResType)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	res, err := UnmarshalResource(data, h.Schema)
+	if err != nil {
+		return 0, nil, withPointer(err, "/data")
+	}
+
+	if iden.ID == "" {
+		res.Set("id", h.newID())
+	} else {
+		existing, err := store.Resource(iden.ID)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if existing != nil {
+			return 0, nil, NewErrConflict(
+				fmt.Sprintf("A resource with ID %q already exists.", iden.ID),
+			)
+		}
+	}
+
+	err = store.Insert(res)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = h.relSync().resource(nil, res)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	created, err := h.resource(url.ResType, res.Get("id").(string))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	copyLID(res, created)
+
+	return http.StatusCreated, &Document{Data: created}, nil
+}
+
+func (h *Handler) updateResource(url *URL, body []byte) (int, *Document, error) {
+	store, err := h.store(url.ResType)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	data, iden, err := unmarshalPrimaryData(body, url.ResType)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if iden.ID != url.ResID {
+		return 0, nil, withPointer(NewErrConflict(
+			fmt.Sprintf("The ID %q does not match the URL.", iden.ID),
+		), "/data/id")
+	}
+
+	res, err := UnmarshalPartialResource(data, h.Schema)
+	if err != nil {
+		return 0, nil, withPointer(err, "/data")
+	}
+
+	old, err := h.resource(url.ResType, url.ResID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = store.Update(res)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = h.relSync().resource(old, res)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	updated, err := h.resource(url.ResType, url.ResID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, &Document{Data: updated}, nil
+}
+
+func (h *Handler) deleteResource(url *URL) (int, *Document, error) {
+	store, err := h.store(url.ResType)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	old, err := h.resource(url.ResType, url.ResID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = store.Delete(url.ResID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = h.relSync().resource(old, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+func (h *Handler) updateRelationship(url *URL, body []byte) (int, *Document, error) {
+	store, err := h.store(url.BelongsToFilter.Type)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	parent, err := h.resource(url.BelongsToFilter.Type, url.BelongsToFilter.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	idens, err := h.unmarshalLinkage(body, url.Rel)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = url.Rel.checkWrite(len(idens), idens.IDs(), false)
+	if err != nil {
+		return 0, nil, withPointer(err, "/data")
+	}
+
+	err = setRel(store, url.BelongsToFilter.Type, url.BelongsToFilter.ID, url.Rel, idens)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = h.syncRel(url, relIdentifiers(parent, url.Rel), idens)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+func (h *Handler) updateToManyRelationship(method string, url *URL, body []byte) (int, *Document, error) {
+	if url.Rel.ToOne {
+		return 0, nil, NewErrMethodNotAllowed(method)
+	}
+
+	store, err := h.store(url.BelongsToFilter.Type)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	parent, err := h.resource(url.BelongsToFilter.Type, url.BelongsToFilter.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	idens, err := h.unmarshalLinkage(body, url.Rel)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	oldIdens := relIdentifiers(parent, url.Rel)
+	newIdens := removeIdentifiers(oldIdens, idens)
+
+	if method == http.MethodPost {
+		newIdens = addIdentifiers(oldIdens, idens)
+	}
+
+	err = url.Rel.checkWrite(len(newIdens), newIdens.IDs(), false)
+	if err != nil {
+		return 0, nil, withPointer(err, "/data")
+	}
+
+	id := url.BelongsToFilter.ID
+
+	switch {
+	case url.Rel.Polymorphic():
+		err = setRel(store, url.BelongsToFilter.Type, id, url.Rel, newIdens)
+	case method == http.MethodPost:
+		err = store.AddToMany(id, url.Rel.FromName, idens.IDs())
+	default:
+		err = store.RemoveFromMany(id, url.Rel.FromName, idens.IDs())
+	}
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = h.syncRel(url, oldIdens, newIdens)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// include adds to doc the resources found by following the inclusion paths of
+// params, starting from the primary data.
+func (h *Handler) include(doc *Document, params *Params) error {
+	return ResolveIncludes(doc, params, FetchFromStores(h.Stores))
+}
+
+// write marshals doc and writes it to w with the given status code.
+//
+// Only the status code is written if doc is nil.
+func (h *Handler) write(w http.ResponseWriter, status int, doc *Document, url *URL) {
+	if doc == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	doc.PrePath = h.PrePath
+
+	if res, ok := doc.Data.(Resource); ok && status == http.StatusCreated {
+		w.Header().Set("Location", buildSelfLink(res, h.PrePath))
+	}
+
+	pl, err := MarshalDocument(doc, url)
+	if err != nil {
+		status, doc = errorDocument(err)
+		pl, _ = MarshalDocument(doc, nil)
+	}
+
+	w.Header().Set("Content-Type", ContentType(doc.JSONAPI.Ext, doc.JSONAPI.Profile))
+	w.WriteHeader(status)
+	_, _ = w.Write(pl)
+}
+
+// rangeCollection returns the collection described by url from store,
+// restricted to ids if not nil.
+//
+// If the collection is paginated with cursors, the cursor of each resource is
+// set in its meta under page.cursor when possible and the first, prev, and
+// next links are returned. prev and next are omitted when there is no such
+// page.
+func (h *Handler) rangeCollection(
+	url *URL,
+	store Store,
+	ids []string,
+) (Collection, map[string]Link, error) {
+	page := url.Params.Page
+	after, before := pageCursor(page, "after"), pageCursor(page, "before")
+	size, sized := page["size"].(int)
+
+	if after == "" && before == "" && (!h.CursorPagination || !sized) {
+		col, err := store.Range(ids, url.Params)
+
+		return col, nil, err
+	}
+
+	// One more resource is requested to know whether there is another page
+	// in the direction of the pagination.
+	params := *url.Params
+	params.Page = map[string]any{}
+
+	for k, v := range page {
+		params.Page[k] = v
+	}
+
+	if sized && size >= 0 {
+		params.Page["size"] = size + 1
+	}
+
+	col, err := store.Range(ids, &params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	more := sized && size >= 0 && col.Len() > size
+	if more {
+		extra := col.At(col.Len() - 1)
+		if after == "" && before != "" {
+			extra = col.At(0)
+		}
+
+		col = withoutResource(col, extra.Get("id").(string))
+	}
+
+	rules := url.Params.SortingRules
+	cursorLink := func(key, cursor string) Link {
+		page := map[string]any{}
+		if sized {
+			page["size"] = size
+		}
+
+		if key != "" {
+			page[key] = cursor
+		}
+
+		return pageLink(url, h.PrePath, page)
+	}
+
+	links := map[string]Link{
+		"first": cursorLink("", ""),
+	}
+
+	if col.Len() > 0 {
+		first := MarshalCursor(col.At(0), rules)
+		last := MarshalCursor(col.At(col.Len()-1), rules)
+
+		if after != "" || (before != "" && more) {
+			links["prev"] = cursorLink("before", first)
+		}
+
+		if before != "" || more {
+			links["next"] = cursorLink("after", last)
+		}
+	}
+
+	for i := 0; i < col.Len(); i++ {
+		if mh, ok := col.At(i).(MetaHolder); ok {
+			// The meta values of the resource are not modified since
+			// they might be shared with the store.
+			meta := Meta{}
+			for k, v := range mh.Meta() {
+				meta[k] = v
+			}
+
+			meta["page"] = map[string]any{"cursor": MarshalCursor(col.At(i), rules)}
+			mh.SetMeta(meta)
+		}
+	}
+
+	return col, links, nil
+}
+
+// withoutResource returns col without the resource identified by id.
+//
+// A *SoftCollection is modified in place so it keeps its total.
+func withoutResource(col Collection, id string) Collection {
+	if sc, ok := col.(*SoftCollection); ok {
+		sc.Remove(id)
+
+		return sc
+	}
+
+	rest := &Resources{}
+
+	for i := 0; i < col.Len(); i++ {
+		if col.At(i).Get("id").(string) != id {
+			*rest = append(*rest, col.At(i))
+		}
+	}
+
+	return rest
+}
+
+// store returns the store associated with the given type.
+func (h *Handler) store(typ string) (Store, error) {
+	return storeFor(h.Stores, typ)
+}
+
+// resource returns the resource of the given type and ID, or a not found error
+// if it does not exist.
+func (h *Handler) resource(typ, id string) (Resource, error) {
+	store, err := h.store(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := store.Resource(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		return nil, NewErrNotFound()
+	}
+
+	return res, nil
+}
+
+// unmarshalLinkage reads the resource linkage found in body and returns the
+// identifiers after making sure they are of a type targeted by rel.
+func (h *Handler) unmarshalLinkage(body []byte, rel Rel) (Identifiers, error) {
+	ske := payloadSkeleton{}
+
+	err := json.Unmarshal(body, &ske)
+	if err != nil {
+		return nil, NewErrBadRequest("Invalid JSON", err.Error())
+	}
+
+	if len(ske.Data) == 0 {
+		return nil, NewErrMissingDataMember()
+	}
+
+	var idens Identifiers
+
+	switch {
+	case string(ske.Data) == "null" && rel.ToOne:
+		return Identifiers{}, nil
+	case rel.ToOne:
+		iden, err := UnmarshalIdentifier(ske.Data, h.Schema)
+		if err != nil {
+			return nil, withPointer(
+				NewErrBadRequest("Invalid resource linkage", err.Error()),
+				"/data",
+			)
+		}
+
+		idens = Identifiers{iden}
+	default:
+		idens, err = UnmarshalIdentifiers(ske.Data, h.Schema)
+		if err != nil {
+			return nil, withPointer(
+				NewErrBadRequest("Invalid resource linkage", err.Error()),
+				"/data",
+			)
+		}
+	}
+
+	for i, iden := range idens {
+		if !rel.Targets(iden.Type) {
+			pointer := "/data/type"
+			if !rel.ToOne {
+				pointer = "/data/" + strconv.Itoa(i) + "/type"
+			}
+
+			return nil, withPointer(NewErrConflict(
+				fmt.Sprintf("Type %q does not match relationship %q.", iden.Type, rel.FromName),
+			), pointer)
+		}
+
+		idens[i] = Identifier{Type: iden.Type, ID: iden.ID}
+	}
+
+	return idens, nil
+}
+
+// relSync returns a relSync that updates the inverse relationships through the
+// stores of the handler.
+func (h *Handler) relSync() relSync {
+	return relSync{
+		schema: h.Schema,
+		target: storeRelTarget(h.Stores),
+	}
+}
+
+// syncRel updates the inverse relationships after the relationship of url went
+// from oldIdens to newIdens.
+func (h *Handler) syncRel(url *URL, oldIdens, newIdens Identifiers) error {
+	return h.relSync().rel(
+		url.BelongsToFilter.Type,
+		url.BelongsToFilter.ID,
+		url.Rel.FromName,
+		oldIdens,
+		newIdens,
+	)
+}
+
+func (h *Handler) newID() string {
+	if h.NewID != nil {
+		return h.NewID()
+	}
+
+	return generateID()
+}
+
+// unmarshalPrimaryData returns the primary data of the document found in body
+// and its identifier after checking that its type is typ.
+func unmarshalPrimaryData(body []byte, typ string) ([]byte, Identifier, error) {
+	ske := payloadSkeleton{}
+	iden := Identifier{}
+
+	err := json.Unmarshal(body, &ske)
+	if err != nil {
+		return nil, iden, NewErrBadRequest("Invalid JSON", err.Error())
+	}
+
+	if len(ske.Data) == 0 || ske.Data[0] != '{' {
+		return nil, iden, NewErrMissingDataMember()
+	}
+
+	err = json.Unmarshal(ske.Data, &iden)
+	if err != nil {
+		return nil, iden, NewErrBadRequest(
+			"Invalid JSON",
+			"The provided JSON body could not be read.",
+		)
+	}
+
+	if iden.Type != typ {
+		return nil, iden, withPointer(NewErrConflict(
+			fmt.Sprintf("Type %q does not match the URL.", iden.Type),
+		), "/data/type")
+	}
+
+	return ske.Data, iden, nil
+}
+
+// errorDocument returns a document that holds err and the status code that
+// should be used.
+//
+// An internal server error is reported if err is not of type Error or Errors.
+// The status code of the first error is used if err holds many errors.
+func errorDocument(err error) (int, *Document) {
+	var (
+		e    Error
+		errs Errors
+	)
+
+	switch {
+	case errors.As(err, &errs) && len(errs) > 0:
+	case errors.As(err, &e):
+		errs = Errors{e}
+	default:
+		errs = Errors{NewErrInternalServerError()}
+	}
+
+	status, _ := strconv.Atoi(errs[0].Status)
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	return status, &Document{Errors: errs}
+}
+
+// copyLID sets the local ID of dst to the one of src if both can hold a local
+// ID.
+func copyLID(src, dst Resource) {
+	if s, ok := src.(LIDHolder); ok {
+		if d, ok := dst.(LIDHolder); ok {
+			d.SetLID(s.GetLID())
+		}
+	}
+}
+
+// addIDs returns the union of ids and newIDs.
+func addIDs(ids, newIDs []string) []string {
+	result := append([]string{}, ids...)
+
+	for _, id := range newIDs {
+		found := false
+
+		for _, id2 := range result {
+			if id == id2 {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
+
+// removeIDs returns ids without the IDs found in oldIDs.
+func removeIDs(ids, oldIDs []string) []string {
+	result := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		found := false
+
+		for _, id2 := range oldIDs {
+			if id == id2 {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
+
+// generateID returns a random (version 4) UUID.
+func generateID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,685 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	schema := newHandlerSchema()
+
+	stores := map[string]Store{}
+	for i := range schema.Types {
+		stores[schema.Types[i].Name] = NewMemoryStore(schema.Types[i])
+	}
+
+	handler := NewHandler(schema, stores)
+	handler.NewID = func() string { return "a1" }
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		status   int
+		location string
+		payload  string
+	}{
+		{
+			name:     "create user",
+			method:   "POST",
+			url:      "/users",
+			body:     `{"data":{"type":"users","id":"u1","attributes":{"name":"Alice"}}}`,
+			status:   201,
+			location: "/users/u1",
+		}, {
+			name:   "create user with existing id",
+			method: "POST",
+			url:    "/users",
+			body:   `{"data":{"type":"users","id":"u1","attributes":{"name":"Bob"}}}`,
+			status: 409,
+		}, {
+			name:   "create article with wrong type",
+			method: "POST",
+			url:    "/articles",
+			body:   `{"data":{"type":"users","attributes":{"name":"Bob"}}}`,
+			status: 409,
+		}, {
+			name:   "create article without data",
+			method: "POST",
+			url:    "/articles",
+			body:   `{}`,
+			status: 400,
+		}, {
+			name:   "create article",
+			method: "POST",
+			url:    "/articles",
+			body: `{
+				"data": {
+					"type": "articles",
+					"attributes": {"title": "Title"},
+					"relationships": {
+						"author": {"data": {"type": "users", "id": "u1"}}
+					}
+				}
+			}`,
+			status:   201,
+			location: "/articles/a1",
+		}, {
+			name:   "get inverse relationship",
+			method: "GET",
+			url:    "/users/u1/relationships/articles",
+			status: 200,
+			payload: `{
+				"data": [{"id": "a1", "type": "articles"}],
+				"jsonapi": {"version": "1.0"},
+				"links": {
+					"self": "/users/u1/relationships/articles` +
+				`?fields%5Barticles%5D=author%2Crelated%2Ctitle&sort=title%2Cid"
+				}
+			}`,
+		}, {
+			name:   "get collection",
+			method: "GET",
+			url:    "/articles?fields[articles]=title",
+			status: 200,
+			payload: `{
+				"data": [{
+					"attributes": {"title": "Title"},
+					"id": "a1",
+					"links": {"self": "/articles/a1"},
+					"type": "articles"
+				}],
+				"jsonapi": {"version": "1.0"},
+				"links": {
+					"self": "/articles?fields%5Barticles%5D=title&sort=title%2Cid"
+				}
+			}`,
+		}, {
+			name:   "get resource with inclusion",
+			method: "GET",
+			url:    "/articles/a1?fields[articles]=author&fields[users]=name&include=author",
+			status: 200,
+			payload: `{
+				"data": {
+					"id": "a1",
+					"links": {"self": "/articles/a1"},
+					"relationships": {
+						"author": {
+							"data": {"id": "u1", "type": "users"},
+							"links": {
+								"related": "/articles/a1/author",
+								"self": "/articles/a1/relationships/author"
+							}
+						}
+					},
+					"type": "articles"
+				},
+				"included": [{
+					"attributes": {"name": "Alice"},
+					"id": "u1",
+					"links": {"self": "/users/u1"},
+					"type": "users"
+				}],
+				"jsonapi": {"version": "1.0"},
+				"links": {
+					"self": "/articles/a1?fields%5Barticles%5D=author&fields%5Busers%5D=name"
+				}
+			}`,
+		}, {
+			name:   "get unknown resource",
+			method: "GET",
+			url:    "/articles/a2",
+			status: 404,
+		}, {
+			name:   "update resource",
+			method: "PATCH",
+			url:    "/articles/a1?fields[articles]=title,author",
+			body:   `{"data":{"type":"articles","id":"a1","attributes":{"title":"New"}}}`,
+			status: 200,
+			payload: `{
+				"data": {
+					"attributes": {"title": "New"},
+					"id": "a1",
+					"links": {"self": "/articles/a1"},
+					"relationships": {
+						"author": {
+							"links": {
+								"related": "/articles/a1/author",
+								"self": "/articles/a1/relationships/author"
+							}
+						}
+					},
+					"type": "articles"
+				},
+				"jsonapi": {"version": "1.0"},
+				"links": {
+					"self": "/articles/a1?fields%5Barticles%5D=author%2Ctitle"
+				}
+			}`,
+		}, {
+			name:   "update resource with wrong id",
+			method: "PATCH",
+			url:    "/articles/a1",
+			body:   `{"data":{"type":"articles","id":"a2","attributes":{"title":"New"}}}`,
+			status: 409,
+		}, {
+			name:   "get related resource",
+			method: "GET",
+			url:    "/articles/a1/author?fields[users]=name",
+			status: 200,
+			payload: `{
+				"data": {
+					"attributes": {"name": "Alice"},
+					"id": "u1",
+					"links": {"self": "/users/u1"},
+					"type": "users"
+				},
+				"jsonapi": {"version": "1.0"},
+				"links": {
+					"self": "/articles/a1/author?fields%5Busers%5D=name"
+				}
+			}`,
+		}, {
+			name:   "get to-one relationship",
+			method: "GET",
+			url:    "/articles/a1/relationships/author",
+			status: 200,
+			payload: `{
+				"data": {"id": "u1", "type": "users"},
+				"jsonapi": {"version": "1.0"},
+				"links": {
+					"self": "/articles/a1/relationships/author?fields%5Busers%5D=articles%2Cname"
+				}
+			}`,
+		}, {
+			name:   "add to to-one relationship",
+			method: "POST",
+			url:    "/articles/a1/relationships/author",
+			body:   `{"data":{"type":"users","id":"u1"}}`,
+			status: 405,
+		}, {
+			name:   "clear to-one relationship",
+			method: "PATCH",
+			url:    "/articles/a1/relationships/author",
+			body:   `{"data":null}`,
+			status: 204,
+		}, {
+			name:   "get emptied inverse relationship",
+			method: "GET",
+			url:    "/users/u1/relationships/articles",
+			status: 200,
+			payload: `{
+				"data": [],
+				"jsonapi": {"version": "1.0"},
+				"links": {
+					"self": "/users/u1/relationships/articles` +
+				`?fields%5Barticles%5D=author%2Crelated%2Ctitle&sort=title%2Cid"
+				}
+			}`,
+		}, {
+			name:   "get empty to-one related resource",
+			method: "GET",
+			url:    "/articles/a1/author?fields[users]=name",
+			status: 200,
+			payload: `{
+				"data": null,
+				"jsonapi": {"version": "1.0"},
+				"links": {
+					"self": "/articles/a1/author?fields%5Busers%5D=name"
+				}
+			}`,
+		}, {
+			name:   "add to to-many relationship",
+			method: "POST",
+			url:    "/articles/a1/relationships/related",
+			body:   `{"data":[{"type":"articles","id":"a1"},{"type":"articles","id":"a3"}]}`,
+			status: 204,
+		}, {
+			name:   "add wrong type to to-many relationship",
+			method: "POST",
+			url:    "/articles/a1/relationships/related",
+			body:   `{"data":[{"type":"users","id":"u1"}]}`,
+			status: 409,
+		}, {
+			name:   "remove from to-many relationship",
+			method: "DELETE",
+			url:    "/articles/a1/relationships/related",
+			body:   `{"data":[{"type":"articles","id":"a3"}]}`,
+			status: 204,
+		}, {
+			name:   "get to-many relationship",
+			method: "GET",
+			url:    "/articles/a1/relationships/related",
+			status: 200,
+			payload: `{
+				"data": [{"id": "a1", "type": "articles"}],
+				"jsonapi": {"version": "1.0"},
+				"links": {
+					"self": "/articles/a1/relationships/related` +
+				`?fields%5Barticles%5D=author%2Crelated%2Ctitle&sort=title%2Cid"
+				}
+			}`,
+		}, {
+			name:   "get to-many related resources",
+			method: "GET",
+			url:    "/articles/a1/related?fields[articles]=title",
+			status: 200,
+			payload: `{
+				"data": [{
+					"attributes": {"title": "New"},
+					"id": "a1",
+					"links": {"self": "/articles/a1"},
+					"type": "articles"
+				}],
+				"jsonapi": {"version": "1.0"},
+				"links": {
+					"self": "/articles/a1/related?fields%5Barticles%5D=title&sort=title%2Cid"
+				}
+			}`,
+		}, {
+			name:   "method not allowed",
+			method: "PUT",
+			url:    "/articles",
+			status: 405,
+		}, {
+			name:   "unknown type",
+			method: "GET",
+			url:    "/unknown",
+			status: 400,
+		}, {
+			name:   "delete resource",
+			method: "DELETE",
+			url:    "/articles/a1",
+			status: 204,
+		}, {
+			name:   "get deleted resource",
+			method: "GET",
+			url:    "/articles/a1",
+			status: 404,
+		},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		req := httptest.NewRequest(test.method, test.url, bytes.NewBufferString(test.body))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(test.status, rec.Code, test.name)
+		assert.Equal(test.location, rec.Header().Get("Location"), test.name)
+
+		if test.status != 204 {
+			assert.Equal(
+				"application/vnd.api+json",
+				rec.Header().Get("Content-Type"),
+				test.name,
+			)
+		}
+
+		if test.payload != "" {
+			assert.JSONEq(test.payload, rec.Body.String(), test.name)
+		}
+
+		if test.status >= 400 {
+			assert.True(strings.HasPrefix(rec.Body.String(), `{"errors":[`), test.name)
+		}
+	}
+}
+
+func TestHandlerMissingStore(t *testing.T) {
+	assert := assert.New(t)
+
+	handler := NewHandler(newHandlerSchema(), map[string]Store{})
+
+	req := httptest.NewRequest("GET", "/articles", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(500, rec.Code)
+	assert.Contains(rec.Body.String(), `No store is defined for type \"articles\".`)
+}
+
+func TestHandlerRelConstraints(t *testing.T) {
+	schema := newConstrainedPostSchema()
+
+	stores := map[string]Store{}
+	for i := range schema.Types {
+		stores[schema.Types[i].Name] = NewMemoryStore(schema.Types[i])
+	}
+
+	handler := NewHandler(schema, stores)
+
+	tests := []struct {
+		name               string
+		method             string
+		url                string
+		body               string
+		status             int
+		expectedConstraint string
+	}{
+		{
+			name:   "create person",
+			method: "POST",
+			url:    "/people",
+			body:   `{"data":{"type":"people","id":"p1","attributes":{"name":"Alice"}}}`,
+			status: 201,
+		}, {
+			name:   "create post without author",
+			method: "POST",
+			url:    "/posts",
+			body: `{"data":{"type":"posts","id":"po1","relationships":{` +
+				`"editors":{"data":[{"type":"people","id":"p1"}]}}}}`,
+			status:             422,
+			expectedConstraint: "required",
+		}, {
+			name:   "create post",
+			method: "POST",
+			url:    "/posts",
+			body: `{"data":{"type":"posts","id":"po1","relationships":{` +
+				`"author":{"data":{"type":"people","id":"p1"}},` +
+				`"editors":{"data":[{"type":"people","id":"p1"}]}}}}`,
+			status: 201,
+		}, {
+			name:               "update immutable relationship",
+			method:             "PATCH",
+			url:                "/posts/po1/relationships/author",
+			body:               `{"data":{"type":"people","id":"p1"}}`,
+			status:             422,
+			expectedConstraint: "immutable",
+		}, {
+			name:               "empty required relationship",
+			method:             "PATCH",
+			url:                "/posts/po1/relationships/editors",
+			body:               `{"data":[]}`,
+			status:             422,
+			expectedConstraint: "required",
+		}, {
+			name:               "remove last resource of required relationship",
+			method:             "DELETE",
+			url:                "/posts/po1/relationships/editors",
+			body:               `{"data":[{"type":"people","id":"p1"}]}`,
+			status:             422,
+			expectedConstraint: "required",
+		}, {
+			name:   "add to relationship",
+			method: "POST",
+			url:    "/posts/po1/relationships/tags",
+			body:   `{"data":[{"type":"tags","id":"t1"},{"type":"tags","id":"t2"}]}`,
+			status: 204,
+		}, {
+			name:               "add too many resources to relationship",
+			method:             "POST",
+			url:                "/posts/po1/relationships/tags",
+			body:               `{"data":[{"type":"tags","id":"t3"}]}`,
+			status:             422,
+			expectedConstraint: "max",
+		}, {
+			name:               "set relationship to invalid ID",
+			method:             "PATCH",
+			url:                "/posts/po1/relationships/tags",
+			body:               `{"data":[{"type":"tags","id":"go"}]}`,
+			status:             422,
+			expectedConstraint: "pattern",
+		},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		req := httptest.NewRequest(test.method, test.url, bytes.NewBufferString(test.body))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(test.status, rec.Code, test.name)
+
+		if test.expectedConstraint != "" {
+			assert.Contains(
+				rec.Body.String(),
+				`"constraint":"`+test.expectedConstraint+`"`,
+				test.name,
+			)
+		}
+	}
+
+	assert := assert.New(t)
+
+	post, _ := stores["posts"].Resource("po1")
+	assert.Equal("p1", post.Get("author"))
+	assert.Equal([]string{"p1"}, post.Get("editors"))
+	assert.Equal([]string{"t1", "t2"}, post.Get("tags"))
+
+	// Atomic operations
+	ops, err := UnmarshalOperations([]byte(`{
+		"atomic:operations": [{
+			"op": "remove",
+			"ref": {"type": "posts", "id": "po1", "relationship": "tags"},
+			"data": [{"type": "tags", "id": "t1"}]
+		}, {
+			"op": "remove",
+			"ref": {"type": "posts", "id": "po1", "relationship": "editors"},
+			"data": [{"type": "people", "id": "p1"}]
+		}]
+	}`), schema)
+	assert.NoError(err)
+
+	_, err = handler.ExecuteOperations(ops)
+	if e, ok := err.(Error); assert.True(ok) {
+		assert.Equal("required", e.Meta["constraint"])
+		assert.Equal("/atomic:operations/1/data", e.Source["pointer"])
+	}
+
+	post, _ = stores["posts"].Resource("po1")
+	assert.Equal([]string{"t1", "t2"}, post.Get("tags"))
+}
+
+func TestHandlerContentNegotiation(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+
+	stores := map[string]Store{}
+	for i := range schema.Types {
+		stores[schema.Types[i].Name] = NewMemoryStore(schema.Types[i])
+	}
+
+	handler := NewHandler(schema, stores)
+	handler.Profiles = []string{"https://example.com/profile"}
+
+	// Applied profile
+	req := httptest.NewRequest("GET", "/articles", nil)
+	req.Header.Set("Accept", MediaType+`; profile="https://example.com/profile"`)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(200, rec.Code)
+	assert.Equal(
+		MediaType+`; profile="https://example.com/profile"`,
+		rec.Header().Get("Content-Type"),
+	)
+	assert.Contains(
+		rec.Body.String(),
+		`"jsonapi":{"version":"1.1","profile":["https://example.com/profile"]}`,
+	)
+
+	// Unsupported extension
+	req = httptest.NewRequest(
+		"POST",
+		"/users",
+		strings.NewReader(`{"data":{"type":"users","id":"u1"}}`),
+	)
+	req.Header.Set("Content-Type", MediaType+`; ext="`+AtomicExt+`"`)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(415, rec.Code)
+	assert.Equal(MediaType, rec.Header().Get("Content-Type"))
+
+	// Extension advertised by the document only
+	req = httptest.NewRequest(
+		"POST",
+		"/users",
+		strings.NewReader(`{
+			"data": {"type": "users", "id": "u1"},
+			"jsonapi": {"version": "1.1", "ext": ["`+AtomicExt+`"]}
+		}`),
+	)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(400, rec.Code)
+	assert.Contains(rec.Body.String(), `"pointer":"/jsonapi/ext/0"`)
+
+	// Not acceptable
+	req = httptest.NewRequest("GET", "/articles", nil)
+	req.Header.Set("Accept", MediaType+"; charset=utf-8")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(406, rec.Code)
+}
+
+func TestHandlerCursorPagination(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+
+	stores := map[string]Store{}
+	for i := range schema.Types {
+		stores[schema.Types[i].Name] = NewMemoryStore(schema.Types[i])
+	}
+
+	for _, id := range []string{"u1", "u2", "u3"} {
+		typ := schema.GetType("users")
+		res := &SoftResource{Type: &typ}
+		res.SetID(id)
+		res.Set("name", "name-"+id)
+		res.SetMeta(Meta{"x": 1.0})
+		assert.NoError(stores["users"].Insert(res))
+	}
+
+	handler := NewHandler(schema, stores)
+	handler.PrePath = "https://example.org"
+	handler.CursorPagination = true
+
+	get := func(url string) Document {
+		req := httptest.NewRequest("GET", url, nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+		assert.Equal(200, rec.Code, url)
+
+		doc, err := UnmarshalDocument(rec.Body.Bytes(), schema)
+		assert.NoError(err, url)
+
+		return *doc
+	}
+	ids := func(doc Document) []string {
+		ids := []string{}
+		col := doc.Data.(Collection)
+
+		for i := 0; i < col.Len(); i++ {
+			ids = append(ids, col.At(i).Get("id").(string))
+		}
+
+		return ids
+	}
+	link := func(doc Document, name string) string {
+		return strings.TrimPrefix(doc.Links[name].HRef, "https://example.org")
+	}
+
+	// First page
+	doc := get("/users?page[size]=2")
+	assert.Equal([]string{"u1", "u2"}, ids(doc))
+	assert.NotContains(doc.Links, "prev")
+	assert.Contains(link(doc, "first"), "page%5Bsize%5D=2")
+	assert.NotContains(link(doc, "first"), "page%5Bafter%5D")
+
+	cursor := doc.Data.(Collection).At(1).(MetaHolder).Meta()["page"]
+	assert.Equal(
+		map[string]any{"cursor": MarshalCursor(
+			doc.Data.(Collection).At(1),
+			[]string{"name", "id"},
+		)},
+		cursor,
+	)
+
+	// Last page
+	doc = get(link(doc, "next"))
+	assert.Equal([]string{"u3"}, ids(doc))
+	assert.NotContains(doc.Links, "next")
+
+	// Back to the first page
+	doc = get(link(doc, "prev"))
+	assert.Equal([]string{"u1", "u2"}, ids(doc))
+	assert.NotContains(doc.Links, "prev")
+	assert.Contains(link(doc, "next"), "page%5Bafter%5D=")
+
+	// The cursors are not kept in the meta values of the stored resources
+	doc = get("/users/u1")
+	assert.Equal(Meta{"x": 1.0}, doc.Data.(MetaHolder).Meta())
+
+	// Invalid cursor
+	req := httptest.NewRequest("GET", "/users?page[after]=abc", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+	assert.Equal(400, rec.Code)
+	assert.Contains(rec.Body.String(), `"parameter":"page[after]"`)
+
+	// Page numbers are used without CursorPagination
+	handler.CursorPagination = false
+
+	doc = get("/users?page[size]=2&page[number]=1")
+	assert.Equal([]string{"u3"}, ids(doc))
+	assert.NotContains(doc.Links, "next")
+}
+
+func newHandlerSchema() *Schema {
+	schema := &Schema{}
+
+	articles := Type{Name: "articles"}
+	_ = articles.AddAttr(Attr{
+		Name: "title",
+		Type: AttrTypeString,
+	})
+	_ = schema.AddType(articles)
+
+	users := Type{Name: "users"}
+	_ = users.AddAttr(Attr{
+		Name: "name",
+		Type: AttrTypeString,
+	})
+	_ = schema.AddType(users)
+
+	_ = schema.AddTwoWayRel(Rel{
+		FromType: "articles",
+		FromName: "author",
+		ToOne:    true,
+		ToType:   "users",
+		ToName:   "articles",
+		FromOne:  false,
+	})
+	_ = schema.AddRel("articles", Rel{
+		FromType: "articles",
+		FromName: "related",
+		ToOne:    false,
+		ToType:   "articles",
+	})
+
+	return schema
+}
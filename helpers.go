@@ -42,7 +42,7 @@ func Check(v any) error {
 	for i := 0; i < value.NumField(); i++ {
 		sf := value.Type().Field(i)
 
-		if sf.Tag.Get("api") == "attr" {
+		if strings.Split(sf.Tag.Get("api"), ",")[0] == "attr" {
 			isValid := false
 
 			switch sf.Type.String() {
@@ -53,13 +53,25 @@ func Check(v any) error {
 				"bool",
 				"time.Time",
 				"[]uint8",
+				"float32", "float64",
+				"jsonapi.Decimal",
+				"[]string", "[]float64",
+				"map[string]interface {}",
 				"*string",
 				"*int", "*int8", "*int16", "*int32", "*int64",
 				"*uint", "*uint8", "*uint16", "*uint32", "*uint64",
 				"*bool",
 				"*time.Time",
-				"*[]uint8":
+				"*[]uint8",
+				"*float32", "*float64",
+				"*jsonapi.Decimal",
+				"*[]string", "*[]float64",
+				"*map[string]interface {}":
 				isValid = true
+			default:
+				// Registered attribute types
+				typ := attrTypes.find(strings.TrimPrefix(sf.Type.String(), "*"))
+				isValid = typ != AttrTypeInvalid
 			}
 
 			if !isValid {
@@ -69,6 +81,11 @@ func Check(v any) error {
 					resType,
 				)
 			}
+
+			err := parseAttrTag(&Attr{}, sf)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -79,7 +96,7 @@ func Check(v any) error {
 		if strings.HasPrefix(sf.Tag.Get("api"), "rel,") {
 			s := strings.Split(sf.Tag.Get("api"), ",")
 
-			if len(s) < 2 || len(s) > 3 {
+			if len(s) < 2 {
 				return fmt.Errorf(
 					"jsonapi: api tag of relationship %q of struct %q is invalid",
 					sf.Name,
@@ -87,12 +104,27 @@ func Check(v any) error {
 				)
 			}
 
-			if sf.Type.String() != "string" && sf.Type.String() != "[]string" {
+			err := parseRelTag(&Rel{}, sf, value.Type().Name())
+			if err != nil {
+				return err
+			}
+
+			polymorphic := strings.Contains(s[1], "|")
+
+			switch ft := sf.Type.String(); {
+			case !polymorphic && ft != "string" && ft != "[]string":
 				return fmt.Errorf(
 					"jsonapi: relationship %q of type %q is not string or []string",
 					sf.Name,
 					resType,
 				)
+			case polymorphic && ft != "jsonapi.Identifier" && ft != "jsonapi.Identifiers":
+				return fmt.Errorf(
+					"jsonapi: "+
+						"polymorphic relationship %q of type %q is not Identifier or Identifiers",
+					sf.Name,
+					resType,
+				)
 			}
 		}
 	}
@@ -132,13 +164,17 @@ func BuildType(v any) (Type, error) {
 		jsonTag := fs.Tag.Get("json")
 		apiTag := fs.Tag.Get("api")
 
-		if apiTag == "attr" {
+		if strings.Split(apiTag, ",")[0] == "attr" {
 			fieldType, null := GetAttrType(fs.Type.String())
-			typ.Attrs[jsonTag] = Attr{
+			attr := Attr{
 				Name:     jsonTag,
 				Type:     fieldType,
 				Nullable: null,
 			}
+
+			// The tag was already checked by Check.
+			_ = parseAttrTag(&attr, fs)
+			typ.Attrs[jsonTag] = attr
 		}
 	}
 
@@ -149,25 +185,22 @@ func BuildType(v any) (Type, error) {
 		fs := val.Type().Field(i)
 		jsonTag := fs.Tag.Get("json")
 		relTag := strings.Split(fs.Tag.Get("api"), ",")
-		invName := ""
-
-		if len(relTag) == 3 {
-			invName = relTag[2]
-		}
 
 		toOne := true
-		if fs.Type.String() == "[]string" {
+		if ft := fs.Type.String(); ft == "[]string" || ft == "jsonapi.Identifiers" {
 			toOne = false
 		}
 
 		if relTag[0] == "rel" {
-			typ.Rels[jsonTag] = Rel{
+			rel := Rel{
 				FromName: jsonTag,
 				ToOne:    toOne,
-				ToType:   relTag[1],
-				ToName:   invName,
 				FromType: typ.Name,
 			}
+
+			// The tag was already checked by Check.
+			_ = parseRelTag(&rel, fs, val.Type().Name())
+			typ.Rels[jsonTag] = rel
 		}
 	}
 
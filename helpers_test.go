@@ -26,7 +26,8 @@ func TestCheck(t *testing.T) {
 	err = Check(invalidRelAPITag{})
 	assert.EqualError(
 		err,
-		"jsonapi: api tag of relationship \"Rel\" of struct \"invalidRelAPITag\" is invalid",
+		"jsonapi: api tag of relationship \"Rel\" of struct \"invalidRelAPITag\" is invalid: "+
+			"unknown option \"is\"",
 	)
 
 	err = Check(invalidReType{})
@@ -37,9 +37,39 @@ func (i Identifiers) IDs() []string {
 }
 
 // Identifier represents a resource's type and ID.
+//
+// LID is the local ID of a resource created by the client. It is only used when
+// the resource has not been assigned an ID yet.
 type Identifier struct {
 	ID   string `json:"id"`
 	Type string `json:"type"`
+	LID  string `json:"lid,omitempty"`
+}
+
+// MarshalJSON returns a JSON representation of the identifier.
+//
+// The ID is omitted if it is empty and a local ID is defined.
+func (i Identifier) MarshalJSON() ([]byte, error) {
+	m := map[string]string{"type": i.Type}
+
+	if i.ID != "" || i.LID == "" {
+		m["id"] = i.ID
+	}
+
+	if i.LID != "" {
+		m["lid"] = i.LID
+	}
+
+	return json.Marshal(m)
+}
+
+// idOrLID returns the ID of the identifier, or its local ID if it has no ID.
+func (i Identifier) idOrLID() string {
+	if i.ID == "" {
+		return i.LID
+	}
+
+	return i.ID
 }
 
 // UnmarshalIdentifier reads a payload where the main data is one identifier to
@@ -55,7 +85,7 @@ func UnmarshalIdentifier(payload []byte, schema *Schema) (Identifier, error) {
 	}
 
 	switch {
-	case iden.ID == "":
+	case iden.ID == "" && iden.LID == "":
 		return Identifier{}, errors.New("identifier has no ID")
 	case iden.Type == "":
 		return Identifier{}, errors.New("identifier has no type")
@@ -61,6 +61,30 @@ func TestUnmarshalIdentifiers(t *testing.T) {
 		assert.EqualError(err, "identifier has no ID")
 	})
 
+	t.Run("identifier with local ID", func(t *testing.T) {
+		assert := assert.New(t)
+
+		iden := Identifier{
+			Type: "mocktype",
+			LID:  "local1",
+		}
+
+		payload, err := json.Marshal(iden)
+		assert.NoError(err)
+		assert.JSONEq(`{"type":"mocktype","lid":"local1"}`, string(payload))
+
+		iden2, err := UnmarshalIdentifier(payload, schema)
+		assert.NoError(err)
+		assert.Equal(iden, iden2)
+
+		// Both ID and local ID
+		iden.ID = "id1"
+
+		payload, err = json.Marshal(iden)
+		assert.NoError(err)
+		assert.JSONEq(`{"id":"id1","type":"mocktype","lid":"local1"}`, string(payload))
+	})
+
 	t.Run("identifier without type", func(t *testing.T) {
 		assert := assert.New(t)
 
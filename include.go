@@ -0,0 +1,247 @@
+package jsonapi
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A FetchFunc returns the resources of type typ whose IDs are in ids.
+//
+// The IDs that do not match any resource are ignored. The order of the
+// returned resources does not matter.
+type FetchFunc func(typ string, ids []string) ([]Resource, error)
+
+// FetchFromStores returns a FetchFunc that gets the resources from the store
+// of their type found in stores. Each batch of IDs results in a single call
+// to Range.
+//
+// An error is returned if there is no store for the requested type.
+func FetchFromStores(stores map[string]Store) FetchFunc {
+	return func(typ string, ids []string) ([]Resource, error) {
+		if len(ids) == 0 {
+			return nil, nil
+		}
+
+		store, err := storeFor(stores, typ)
+		if err != nil {
+			return nil, err
+		}
+
+		col, err := store.Range(ids, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		res := make([]Resource, 0, col.Len())
+		for i := 0; i < col.Len(); i++ {
+			res = append(res, col.At(i))
+		}
+
+		return res, nil
+	}
+}
+
+// ResolveIncludes adds to doc.Included the resources found along the include
+// paths of params, starting from the primary data of doc. doc.RelData is set
+// so that the relationships along those paths have their data marshaled.
+//
+// The paths are resolved one level at a time. At each level, the IDs of all
+// the resources to fetch are grouped by type, so fetch is called at most once
+// per type per level. A resource is never fetched twice and resources shared
+// by several paths are only included once. The resources that a polymorphic
+// relationship points to are only followed by the paths that start from their
+// type.
+//
+// Nothing happens if doc has no primary data or params has no include paths.
+func ResolveIncludes(doc *Document, params *Params, fetch FetchFunc) error {
+	var roots []Resource
+
+	switch data := doc.Data.(type) {
+	case Resource:
+		roots = append(roots, data)
+	case Collection:
+		for i := 0; i < data.Len(); i++ {
+			roots = append(roots, data.At(i))
+		}
+	}
+
+	if len(roots) == 0 || params == nil || len(params.Include) == 0 {
+		return nil
+	}
+
+	if doc.RelData == nil {
+		doc.RelData = map[string][]string{}
+	}
+
+	level := newIncludeTree(params.Include, roots)
+
+	// fetched maps types to IDs to the fetched resources, which are nil
+	// if they do not exist. The primary data never has to be fetched.
+	fetched := map[string]map[string]Resource{}
+
+	for _, res := range roots {
+		typ := res.GetType().Name
+		if fetched[typ] == nil {
+			fetched[typ] = map[string]Resource{}
+		}
+
+		fetched[typ][res.Get("id").(string)] = res
+	}
+
+	for len(level) > 0 {
+		// IDs to fetch by type
+		batches := map[string][]string{}
+
+		for _, node := range level {
+			for _, res := range node.sources {
+				addRelData(doc.RelData, res.GetType().Name, node.rel.FromName)
+
+				for _, iden := range relIdentifiers(res, node.rel) {
+					if fetched[iden.Type] == nil {
+						fetched[iden.Type] = map[string]Resource{}
+					}
+
+					if _, ok := fetched[iden.Type][iden.ID]; !ok {
+						fetched[iden.Type][iden.ID] = nil
+						batches[iden.Type] = append(batches[iden.Type], iden.ID)
+					}
+				}
+			}
+		}
+
+		types := make([]string, 0, len(batches))
+		for typ := range batches {
+			types = append(types, typ)
+		}
+
+		sort.Strings(types)
+
+		for _, typ := range types {
+			res, err := fetch(typ, batches[typ])
+			if err != nil {
+				return err
+			}
+
+			for _, r := range res {
+				fetched[typ][r.Get("id").(string)] = r
+				doc.Include(r)
+			}
+		}
+
+		// The resources found at this level are the sources of the
+		// next one.
+		var next []*includeNode
+
+		for _, node := range level {
+			targets := []Resource{}
+			seen := map[Identifier]struct{}{}
+
+			for _, res := range node.sources {
+				for _, iden := range relIdentifiers(res, node.rel) {
+					if _, ok := seen[iden]; ok {
+						continue
+					}
+
+					seen[iden] = struct{}{}
+
+					if r := fetched[iden.Type][iden.ID]; r != nil {
+						targets = append(targets, r)
+					}
+				}
+			}
+
+			// The targets of a polymorphic relationship are only the
+			// sources of the children that start from their type.
+			for _, child := range node.children {
+				child.sources = resourcesOfType(targets, child.rel.FromType)
+				next = append(next, child)
+			}
+		}
+
+		level = next
+	}
+
+	return nil
+}
+
+// includeNode is a relationship in a tree of include paths.
+type includeNode struct {
+	rel      Rel
+	children []*includeNode
+
+	// sources are the resources whose relationship rel is followed.
+	sources []Resource
+}
+
+// newIncludeTree merges paths into a tree and returns its first level, whose
+// sources are roots. The paths that share a prefix share the nodes of that
+// prefix.
+func newIncludeTree(paths [][]Rel, roots []Resource) []*includeNode {
+	var level []*includeNode
+
+	for _, path := range paths {
+		nodes := &level
+
+		for _, rel := range path {
+			var node *includeNode
+
+			for _, n := range *nodes {
+				if n.rel.FromType == rel.FromType && n.rel.FromName == rel.FromName {
+					node = n
+					break
+				}
+			}
+
+			if node == nil {
+				node = &includeNode{rel: rel}
+				*nodes = append(*nodes, node)
+			}
+
+			nodes = &node.children
+		}
+	}
+
+	for _, node := range level {
+		node.sources = resourcesOfType(roots, node.rel.FromType)
+	}
+
+	return level
+}
+
+// resourcesOfType returns the resources of resources that are of type typ.
+func resourcesOfType(resources []Resource, typ string) []Resource {
+	result := make([]Resource, 0, len(resources))
+
+	for _, res := range resources {
+		if res.GetType().Name == typ {
+			result = append(result, res)
+		}
+	}
+
+	return result
+}
+
+// addRelData adds the relationship rel of type typ to relData, which lists the
+// relationships whose data is part of a document (see Document.RelData).
+func addRelData(relData map[string][]string, typ, rel string) {
+	for _, name := range relData[typ] {
+		if name == rel {
+			return
+		}
+	}
+
+	relData[typ] = append(relData[typ], rel)
+}
+
+// storeFor returns the store of type typ found in stores, or an error if there
+// is none.
+func storeFor(stores map[string]Store, typ string) (Store, error) {
+	if store, ok := stores[typ]; ok && store != nil {
+		return store, nil
+	}
+
+	e := NewErrInternalServerError()
+	e.Detail = fmt.Sprintf("No store is defined for type %q.", typ)
+
+	return nil, e
+}
@@ -0,0 +1,133 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveIncludes(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+	stores := newIncludeStores(schema)
+
+	url, err := NewURLFromRaw(schema, "/articles?include=author.articles,related.author")
+	assert.NoError(err)
+
+	data, err := stores["articles"].Range([]string{"a1", "a2"}, url.Params)
+	assert.NoError(err)
+
+	calls := [][]string{}
+	fetch := FetchFromStores(stores)
+
+	doc := &Document{Data: data}
+
+	err = ResolveIncludes(doc, url.Params, func(typ string, ids []string) ([]Resource, error) {
+		calls = append(calls, append([]string{typ}, ids...))
+
+		return fetch(typ, ids)
+	})
+	assert.NoError(err)
+
+	// One call per type per level, the primary data is not fetched,
+	// and a resource is not fetched twice.
+	assert.Equal([][]string{
+		{"articles", "a3"},
+		{"users", "u1"},
+		{"articles", "a4"},
+		{"users", "u2"},
+	}, calls)
+
+	ids := []string{}
+	for _, res := range doc.Included {
+		ids = append(ids, res.GetType().Name+":"+res.Get("id").(string))
+	}
+
+	sort.Strings(ids)
+	assert.Equal([]string{"articles:a3", "articles:a4", "users:u1", "users:u2"}, ids)
+
+	assert.Equal(map[string][]string{
+		"articles": {"author", "related"},
+		"users":    {"articles"},
+	}, doc.RelData)
+
+	// No include paths
+	doc = &Document{Data: data}
+
+	err = ResolveIncludes(doc, &Params{}, nil)
+	assert.NoError(err)
+	assert.Empty(doc.Included)
+	assert.Nil(doc.RelData)
+
+	// Fetch error
+	failingFetch := func(string, []string) ([]Resource, error) {
+		return nil, errors.New("fetch error")
+	}
+
+	err = ResolveIncludes(&Document{Data: data}, url.Params, failingFetch)
+	assert.EqualError(err, "fetch error")
+
+	// Missing store
+	delete(stores, "users")
+
+	err = ResolveIncludes(&Document{Data: data}, url.Params, FetchFromStores(stores))
+	assert.EqualError(
+		err,
+		`500 Internal Server Error: No store is defined for type "users".`,
+	)
+}
+
+// newIncludeStores returns stores for the types of schema that contain the
+// following resources:
+//
+//	a1 (author: u1, related: a2, a3)
+//	a2 (author: u1)
+//	a3 (author: u2)
+//	a4 (author: u1)
+//	u1, u2
+func newIncludeStores(schema *Schema) map[string]Store {
+	stores := map[string]Store{}
+	for i := range schema.Types {
+		stores[schema.Types[i].Name] = NewMemoryStore(schema.Types[i])
+	}
+
+	articles := []struct {
+		id      string
+		author  string
+		related []string
+	}{
+		{"a1", "u1", []string{"a2", "a3"}},
+		{"a2", "u1", []string{}},
+		{"a3", "u2", []string{}},
+		{"a4", "u1", []string{}},
+	}
+
+	users := map[string][]string{
+		"u1": {"a1", "a2", "a4"},
+		"u2": {"a3"},
+	}
+
+	for _, a := range articles {
+		typ := schema.GetType("articles")
+		res := &SoftResource{Type: &typ}
+		res.SetID(a.id)
+		res.Set("author", a.author)
+		res.Set("related", a.related)
+		_ = stores["articles"].Insert(res)
+	}
+
+	for _, id := range []string{"u1", "u2"} {
+		typ := schema.GetType("users")
+		res := &SoftResource{Type: &typ}
+		res.SetID(id)
+		res.Set("articles", users[id])
+		_ = stores["users"].Insert(res)
+	}
+
+	return stores
+}
@@ -0,0 +1,671 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONSchemaDialect is the JSON Schema dialect of the schemas returned by
+// JSONSchema.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema that describes a resource object of type t
+// as found in a request.
+//
+// The attributes are described according to their types, which includes the
+// ranges of the integer types and the nullability, and according to their
+// constraints (see Attr). The attributes of a registered type are described by
+// its codec if it is an AttrSchemaCodec, otherwise they accept any value. The
+// relationships are described by the shape of their linkage, which includes
+// the bounds of the number of related resources. Unknown members are not
+// allowed.
+//
+// The ID is not required since a client may let the server generate it. No
+// field is required either, which allows partial updates, but required
+// attributes and relationships cannot be null or empty. Attr.WriteOnce and
+// Rel.Immutable are not described since the schema does not tell a new
+// resource from an update.
+func (t Type) JSONSchema() map[string]any {
+	s := t.resourceSchema()
+	s["$schema"] = JSONSchemaDialect
+	s["title"] = t.Name
+
+	return s
+}
+
+// Validate checks that payload is a document whose primary data is a resource
+// object of type t, as described by the schema returned by JSONSchema.
+//
+// Unlike UnmarshalResource, Validate does not stop at the first problem. Every
+// violation is reported as an Error whose source pointer indicates the invalid
+// value, and the violations are returned as Errors, like UnmarshalDocumentAll
+// does. nil is returned if payload is valid.
+func (t Type) Validate(payload []byte) Errors {
+	doc := map[string]any{
+		"type":     "object",
+		"required": []string{"data"},
+		"properties": map[string]any{
+			"data": t.resourceSchema(),
+		},
+	}
+
+	return ValidateJSONSchema(payload, doc)
+}
+
+// ValidateJSONSchema checks that payload is valid according to schema and
+// returns the violations found as errors whose source pointers indicate the
+// invalid values, or nil if there are none.
+//
+// schema is expected to be built like the ones returned by JSONSchema, with
+// lists as []string, []any or []map[string]any. Only the keywords found in
+// those schemas are supported: type, const (strings), enum, required,
+// properties, additionalProperties, items, anyOf, minimum, maximum, minLength,
+// maxLength, pattern, minItems, maxItems, minProperties, maxProperties, format
+// (date-time), contentEncoding (base64), and readOnly, which rejects any value
+// since the payloads are sent by clients. The others are ignored.
+func ValidateJSONSchema(payload []byte, schema map[string]any) Errors {
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+
+	var v any
+
+	err := dec.Decode(&v)
+	if err != nil {
+		return Errors{NewErrBadRequest(
+			"Invalid JSON",
+			"The provided JSON body could not be read.",
+		)}
+	}
+
+	errs := Errors{}
+	validateJSONSchema(&errs, "", v, schema)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// resourceSchema returns the schema of a resource object of type t.
+func (t Type) resourceSchema() map[string]any {
+	attrs := map[string]any{}
+	for _, attr := range t.Attrs {
+		attrs[attr.Name] = attrJSONSchema(attr)
+	}
+
+	rels := map[string]any{}
+	for _, rel := range t.Rels {
+		typeSchema := map[string]any{"const": rel.ToType}
+		if rel.Polymorphic() {
+			typeSchema = map[string]any{"enum": rel.ToTypes}
+		}
+
+		iden := map[string]any{
+			"type":     "object",
+			"required": []string{"type"},
+			"anyOf": []map[string]any{
+				{"required": []string{"id"}},
+				{"required": []string{"lid"}},
+			},
+			"properties": map[string]any{
+				"type": typeSchema,
+				"id":   map[string]any{"type": "string"},
+				"lid":  map[string]any{"type": "string"},
+				"meta": map[string]any{"type": "object"},
+			},
+			"additionalProperties": false,
+		}
+
+		linkage := map[string]any{"type": "array", "items": iden}
+		if rel.ToOne {
+			if !rel.Required {
+				iden["type"] = []string{"object", "null"}
+			}
+
+			linkage = iden
+		} else {
+			relCountSchema(linkage, rel)
+		}
+
+		rels[rel.FromName] = map[string]any{
+			"type":     "object",
+			"required": []string{"data"},
+			"properties": map[string]any{
+				"data":  linkage,
+				"links": map[string]any{"type": "object"},
+				"meta":  map[string]any{"type": "object"},
+			},
+			"additionalProperties": false,
+		}
+	}
+
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"type"},
+		"properties": map[string]any{
+			"type": map[string]any{"const": t.Name},
+			"id":   map[string]any{"type": "string"},
+			"lid":  map[string]any{"type": "string"},
+			"attributes": map[string]any{
+				"type":                 "object",
+				"properties":           attrs,
+				"additionalProperties": false,
+			},
+			"relationships": map[string]any{
+				"type":                 "object",
+				"properties":           rels,
+				"additionalProperties": false,
+			},
+			"links": map[string]any{"type": "object"},
+			"meta":  map[string]any{"type": "object"},
+		},
+		"additionalProperties": false,
+	}
+}
+
+// relCountSchema adds to linkage, the schema of the linkage of the to-many
+// relationship rel, the bounds of the number of related resources.
+func relCountSchema(linkage map[string]any, rel Rel) {
+	min := rel.MinCount
+	if rel.Required && min < 1 {
+		min = 1
+	}
+
+	if min > 0 {
+		linkage["minItems"] = min
+	}
+
+	if rel.MaxCount > 0 {
+		linkage["maxItems"] = rel.MaxCount
+	}
+}
+
+// attrJSONSchema returns the JSON Schema of the values of attr.
+func attrJSONSchema(attr Attr) map[string]any {
+	s := attrValueSchema(attr.Type)
+
+	if attr.Type == AttrTypeTime {
+		s["format"] = "date-time"
+	} else if attr.Type == AttrTypeBytes {
+		s["contentEncoding"] = "base64"
+	}
+
+	nullable := attr.Nullable && !attr.Required
+
+	attrConstraintsSchema(s, attr, nullable)
+
+	if attr.ReadOnly {
+		s["readOnly"] = true
+	}
+
+	if nullable {
+		if typ, ok := s["type"].(string); ok {
+			s["type"] = []string{typ, "null"}
+		}
+	}
+
+	return s
+}
+
+// attrConstraintsSchema adds to s, the schema of the values of attr, the
+// keywords that correspond to the constraints of attr on values. The enum
+// includes null if nullable is true.
+//
+// The length of bytes is not described since it is not the length of their
+// base64 representation.
+func attrConstraintsSchema(s map[string]any, attr Attr, nullable bool) {
+	number := s["type"] == "integer" || s["type"] == "number"
+
+	if number && attr.Min != nil && !math.IsInf(*attr.Min, 0) && !math.IsNaN(*attr.Min) {
+		min, ok := s["minimum"]
+		if !ok || jsonBigFloat(min).Cmp(big.NewFloat(*attr.Min)) < 0 {
+			s["minimum"] = *attr.Min
+		}
+	}
+
+	if number && attr.Max != nil && !math.IsInf(*attr.Max, 0) && !math.IsNaN(*attr.Max) {
+		max, ok := s["maximum"]
+		if !ok || jsonBigFloat(max).Cmp(big.NewFloat(*attr.Max)) > 0 {
+			s["maximum"] = *attr.Max
+		}
+	}
+
+	minKey, maxKey := "", ""
+
+	switch attr.Type {
+	case AttrTypeString:
+		minKey, maxKey = "minLength", "maxLength"
+
+		if attr.Pattern != "" {
+			s["pattern"] = attr.Pattern
+		}
+	case AttrTypeStrings, AttrTypeNumbers:
+		minKey, maxKey = "minItems", "maxItems"
+	case AttrTypeObject:
+		minKey, maxKey = "minProperties", "maxProperties"
+	}
+
+	if minKey != "" && attr.MinLength != nil {
+		s[minKey] = *attr.MinLength
+	}
+
+	if maxKey != "" && attr.MaxLength != nil {
+		s[maxKey] = *attr.MaxLength
+	}
+
+	if len(attr.Enum) > 0 {
+		if enum, ok := attrEnumSchema(attr, s["type"], nullable); ok {
+			s["enum"] = enum
+		}
+	}
+}
+
+// attrEnumSchema returns the values of attr.Enum as JSON values of type typ,
+// plus null if nullable is true. ok is false if typ is not a string, a
+// number, or a boolean.
+func attrEnumSchema(attr Attr, typ any, nullable bool) (enum []any, ok bool) {
+	enum = make([]any, 0, len(attr.Enum)+1)
+
+	for _, e := range attr.Enum {
+		switch {
+		case typ == "string" && attr.Type == AttrTypeString:
+			enum = append(enum, e)
+		case typ == "integer" || typ == "number":
+			// A value that is not a number cannot be accepted.
+			if Decimal(e).IsValid() {
+				enum = append(enum, json.Number(e))
+			}
+		case typ == "boolean":
+			if b, err := strconv.ParseBool(e); err == nil {
+				enum = append(enum, b)
+			}
+		default:
+			return nil, false
+		}
+	}
+
+	if nullable {
+		enum = append(enum, nil)
+	}
+
+	return enum, true
+}
+
+// attrValueSchema returns the type and the bounds of the values of the
+// attribute type typ (see constants and RegisterAttrType) in the form of a
+// schema.
+func attrValueSchema(typ int) map[string]any {
+	integer := func(min, max any) map[string]any {
+		return map[string]any{"type": "integer", "minimum": min, "maximum": max}
+	}
+
+	switch typ {
+	case AttrTypeString, AttrTypeTime, AttrTypeBytes:
+		return map[string]any{"type": "string"}
+	case AttrTypeInt, AttrTypeInt64:
+		return integer(int64(math.MinInt64), int64(math.MaxInt64))
+	case AttrTypeInt8:
+		return integer(math.MinInt8, math.MaxInt8)
+	case AttrTypeInt16:
+		return integer(math.MinInt16, math.MaxInt16)
+	case AttrTypeInt32:
+		return integer(math.MinInt32, math.MaxInt32)
+	case AttrTypeUint, AttrTypeUint64:
+		return integer(0, uint64(math.MaxUint64))
+	case AttrTypeUint8:
+		return integer(0, math.MaxUint8)
+	case AttrTypeUint16:
+		return integer(0, math.MaxUint16)
+	case AttrTypeUint32:
+		return integer(0, int64(math.MaxUint32))
+	case AttrTypeBool:
+		return map[string]any{"type": "boolean"}
+	case AttrTypeFloat32, AttrTypeFloat64, AttrTypeDecimal:
+		return map[string]any{"type": "number"}
+	case AttrTypeStrings:
+		return map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	case AttrTypeNumbers:
+		return map[string]any{"type": "array", "items": map[string]any{"type": "number"}}
+	case AttrTypeObject:
+		return map[string]any{"type": "object"}
+	default:
+		// A registered type is described by its codec if it can,
+		// otherwise any value is accepted.
+		if sc, ok := GetAttrCodec(typ).(AttrSchemaCodec); ok {
+			return copyJSONValue(sc.AttrSchema()).(map[string]any)
+		}
+
+		return map[string]any{}
+	}
+}
+
+// validateJSONSchema appends to errs the violations of schema found in v,
+// which is found at pointer.
+func validateJSONSchema(errs *Errors, pointer string, v any, schema map[string]any) {
+	if schema["readOnly"] == true {
+		*errs = append(*errs, newErrSchemaViolation(pointer, "The value is read-only."))
+
+		return
+	}
+
+	if typ, ok := schema["type"]; ok && !jsonTypeMatches(v, typ) {
+		*errs = append(*errs, newErrSchemaViolation(
+			pointer,
+			fmt.Sprintf("The value must be of type %s.", jsonTypeNames(typ)),
+		))
+
+		return
+	}
+
+	if c, ok := schema["const"]; ok && !jsonEqual(v, c) {
+		*errs = append(*errs, newErrSchemaViolation(
+			pointer,
+			fmt.Sprintf("The value must be %s.", jsonString(c)),
+		))
+	}
+
+	if enum, ok := schema["enum"].([]string); ok && !jsonStringIn(v, enum) {
+		*errs = append(*errs, newErrSchemaViolation(
+			pointer,
+			fmt.Sprintf("The value must be one of %s.", jsonString(enum)),
+		))
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !jsonValueIn(v, enum) {
+		*errs = append(*errs, newErrSchemaViolation(
+			pointer,
+			fmt.Sprintf("The value must be one of %s.", jsonString(enum)),
+		))
+	}
+
+	if anyOf, ok := schema["anyOf"].([]map[string]any); ok {
+		matched := false
+
+		for _, sub := range anyOf {
+			subErrs := Errors{}
+			validateJSONSchema(&subErrs, pointer, v, sub)
+			matched = matched || len(subErrs) == 0
+		}
+
+		if !matched {
+			*errs = append(*errs, newErrSchemaViolation(
+				pointer,
+				"The value does not match any of the allowed forms.",
+			))
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		validateJSONObject(errs, pointer, v, schema)
+	case []any:
+		validateJSONLength(errs, pointer, len(v), schema, "minItems", "maxItems")
+
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i := range v {
+				validateJSONSchema(errs, pointer+"/"+strconv.Itoa(i), v[i], items)
+			}
+		}
+	case json.Number:
+		validateJSONNumber(errs, pointer, v, schema)
+	case string:
+		validateJSONString(errs, pointer, v, schema)
+	}
+}
+
+func validateJSONObject(errs *Errors, pointer string, v map[string]any, schema map[string]any) {
+	validateJSONLength(errs, pointer, len(v), schema, "minProperties", "maxProperties")
+
+	if required, ok := schema["required"].([]string); ok {
+		for _, name := range required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, newErrSchemaViolation(
+					pointer,
+					fmt.Sprintf("The member %q is required.", name),
+				))
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		ptr := pointer + "/" + escapeJSONPointer(name)
+
+		if prop, ok := props[name].(map[string]any); ok {
+			validateJSONSchema(errs, ptr, v[name], prop)
+			continue
+		}
+
+		switch add := schema["additionalProperties"].(type) {
+		case bool:
+			if !add {
+				*errs = append(*errs, newErrSchemaViolation(
+					ptr,
+					fmt.Sprintf("%q is not a known member.", name),
+				))
+			}
+		case map[string]any:
+			validateJSONSchema(errs, ptr, v[name], add)
+		}
+	}
+}
+
+func validateJSONNumber(errs *Errors, pointer string, v json.Number, schema map[string]any) {
+	num, _, err := big.ParseFloat(string(v), 10, 128, big.ToNearestEven)
+	if err != nil {
+		return
+	}
+
+	if min, ok := schema["minimum"]; ok && num.Cmp(jsonBigFloat(min)) < 0 {
+		*errs = append(*errs, newErrSchemaViolation(
+			pointer,
+			fmt.Sprintf("The value must be greater than or equal to %v.", min),
+		))
+	}
+
+	if max, ok := schema["maximum"]; ok && num.Cmp(jsonBigFloat(max)) > 0 {
+		*errs = append(*errs, newErrSchemaViolation(
+			pointer,
+			fmt.Sprintf("The value must be less than or equal to %v.", max),
+		))
+	}
+}
+
+func validateJSONString(errs *Errors, pointer string, v string, schema map[string]any) {
+	validateJSONLength(errs, pointer, utf8.RuneCountInString(v), schema, "minLength", "maxLength")
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		// An invalid pattern is rejected by Type.AddAttr and BuildType,
+		// so the error is ignored. No value matches such a pattern.
+		if matched, _ := regexp.MatchString(pattern, v); !matched {
+			*errs = append(*errs, newErrSchemaViolation(
+				pointer,
+				fmt.Sprintf("The value must match the pattern %q.", pattern),
+			))
+		}
+	}
+
+	if schema["format"] == "date-time" {
+		if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+			*errs = append(*errs, newErrSchemaViolation(
+				pointer,
+				"The value must be a date and time as defined by RFC 3339.",
+			))
+		}
+	}
+
+	if schema["contentEncoding"] == "base64" {
+		if _, err := base64.StdEncoding.DecodeString(v); err != nil {
+			*errs = append(*errs, newErrSchemaViolation(
+				pointer,
+				"The value must be encoded in base64.",
+			))
+		}
+	}
+}
+
+// validateJSONLength appends to errs the violations by n, the length of a
+// value, of the bounds found in schema under minKey and maxKey.
+func validateJSONLength(errs *Errors, pointer string, n int, schema map[string]any, minKey, maxKey string) {
+	if min, ok := schema[minKey].(int); ok && n < min {
+		*errs = append(*errs, newErrSchemaViolation(
+			pointer,
+			fmt.Sprintf("The value must have a length of at least %d.", min),
+		))
+	}
+
+	if max, ok := schema[maxKey].(int); ok && n > max {
+		*errs = append(*errs, newErrSchemaViolation(
+			pointer,
+			fmt.Sprintf("The value must have a length of at most %d.", max),
+		))
+	}
+}
+
+// jsonValueIn reports whether v is equal to one of values, which are strings,
+// numbers, booleans, or null.
+func jsonValueIn(v any, values []any) bool {
+	for _, value := range values {
+		switch value := value.(type) {
+		case nil:
+			if v == nil {
+				return true
+			}
+		case json.Number:
+			if n, ok := v.(json.Number); ok && jsonBigFloat(n).Cmp(jsonBigFloat(value)) == 0 {
+				return true
+			}
+		default:
+			if v == value {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// jsonStringIn reports whether v is a string found in values.
+func jsonStringIn(v any, values []string) bool {
+	if str, ok := v.(string); ok {
+		for _, value := range values {
+			if str == value {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// jsonTypeMatches reports whether v is of the JSON type typ, which is either
+// the name of a type or a list of names.
+func jsonTypeMatches(v any, typ any) bool {
+	var names []string
+
+	switch typ := typ.(type) {
+	case string:
+		names = []string{typ}
+	case []string:
+		names = typ
+	}
+
+	for _, name := range names {
+		ok := false
+
+		switch v := v.(type) {
+		case nil:
+			ok = name == "null"
+		case bool:
+			ok = name == "boolean"
+		case string:
+			ok = name == "string"
+		case []any:
+			ok = name == "array"
+		case map[string]any:
+			ok = name == "object"
+		case json.Number:
+			if name == "number" {
+				ok = true
+			} else if name == "integer" {
+				num, _, err := big.ParseFloat(string(v), 10, 128, big.ToNearestEven)
+				ok = err == nil && num.IsInt()
+			}
+		}
+
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func jsonTypeNames(typ any) string {
+	if names, ok := typ.([]string); ok {
+		return strings.Join(names, " or ")
+	}
+
+	return fmt.Sprint(typ)
+}
+
+// jsonEqual reports whether v, as decoded by ValidateJSONSchema, is equal to
+// the string c.
+func jsonEqual(v any, c any) bool {
+	s, ok := v.(string)
+
+	return ok && s == c
+}
+
+func jsonString(v any) string {
+	b, _ := json.Marshal(v)
+
+	return string(b)
+}
+
+func jsonBigFloat(v any) *big.Float {
+	f, _, _ := big.ParseFloat(fmt.Sprint(v), 10, 128, big.ToNearestEven)
+
+	return f
+}
+
+// escapeJSONPointer escapes name so it can be used as a reference token in a
+// JSON pointer (RFC 6901).
+func escapeJSONPointer(name string) string {
+	name = strings.ReplaceAll(name, "~", "~0")
+
+	return strings.ReplaceAll(name, "/", "~1")
+}
+
+// newErrSchemaViolation (400) returns an error that reports an invalid value
+// found at pointer.
+func newErrSchemaViolation(pointer, detail string) Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusBadRequest)
+	e.Title = "Invalid value in body"
+	e.Detail = detail
+	e.Source["pointer"] = pointer
+
+	return e
+}
@@ -0,0 +1,329 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeJSONSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+
+	payload, err := json.Marshal(schema.GetType("mocktypes1").JSONSchema())
+	assert.NoError(err)
+
+	s := map[string]any{}
+	assert.NoError(json.Unmarshal(payload, &s))
+
+	assert.Equal(JSONSchemaDialect, s["$schema"])
+	assert.Equal("mocktypes1", s["title"])
+	assert.Equal([]any{"type"}, s["required"])
+
+	props := func(v any, keys ...string) map[string]any {
+		for _, key := range keys {
+			v = v.(map[string]any)[key]
+		}
+
+		return v.(map[string]any)
+	}
+
+	attrs := props(s, "properties", "attributes", "properties")
+	assert.Equal(map[string]any{
+		"type":    "integer",
+		"minimum": float64(-128),
+		"maximum": float64(127),
+	}, attrs["int8"])
+	assert.Equal(map[string]any{
+		"type":    "integer",
+		"minimum": float64(0),
+		"maximum": float64(4294967295),
+	}, attrs["uint32"])
+	assert.Equal(map[string]any{
+		"type":   "string",
+		"format": "date-time",
+	}, attrs["time"])
+
+	// Numbers, arrays and objects
+	attrs = props(
+		MustBuildType(mockTypeNumbers{}).JSONSchema(),
+		"properties", "attributes", "properties",
+	)
+	assert.Equal(map[string]any{"type": "number"}, attrs["decimal"])
+	assert.Equal(map[string]any{
+		"type":  "array",
+		"items": map[string]any{"type": "string"},
+	}, attrs["strings"])
+	assert.Equal(map[string]any{"type": "object"}, attrs["object"])
+	assert.Equal([]string{"number", "null"}, props(attrs, "float64ptr")["type"])
+
+	rels := props(s, "properties", "relationships", "properties")
+	assert.Equal(
+		[]any{"object", "null"},
+		props(rels, "to-one", "properties", "data")["type"],
+	)
+	assert.Equal("array", props(rels, "to-many", "properties", "data")["type"])
+
+	// Nullable attributes
+	s = schema.GetType("mocktypes2").JSONSchema()
+	attrs = props(s, "properties", "attributes", "properties")
+	assert.Equal([]string{"string", "null"}, props(attrs, "strptr")["type"])
+}
+
+func TestTypeJSONSchemaConstraints(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := MustBuildType(constrainedType{})
+
+	attrs := typ.JSONSchema()["properties"].(map[string]any)["attributes"].(map[string]any)
+	attrs = attrs["properties"].(map[string]any)
+
+	assert.Equal(map[string]any{
+		"type":      "string",
+		"minLength": 1,
+		"maxLength": 20,
+		"pattern":   "^[A-Z]",
+	}, attrs["title"])
+	assert.Equal(map[string]any{
+		"type": "string",
+		"enum": []any{"draft", "published"},
+	}, attrs["status"])
+	assert.Equal(map[string]any{
+		"type":    []string{"integer", "null"},
+		"minimum": 0.0,
+		"maximum": 100.0,
+	}, attrs["score"])
+	assert.Equal(true, attrs["views"].(map[string]any)["readOnly"])
+	assert.NotContains(attrs["slug"], "readOnly")
+
+	// Validation
+	errs := typ.Validate([]byte(`{
+		"data": {
+			"type": "constrained",
+			"attributes": {
+				"title": "abc",
+				"status": "deleted",
+				"score": 101,
+				"views": 1
+			}
+		}
+	}`))
+
+	details := []string{}
+	for _, err := range errs {
+		details = append(details, err.Source["pointer"].(string)+": "+err.Detail)
+	}
+
+	assert.Equal([]string{
+		`/data/attributes/score: The value must be less than or equal to 100.`,
+		`/data/attributes/status: The value must be one of ["draft","published"].`,
+		`/data/attributes/title: The value must match the pattern "^[A-Z]".`,
+		`/data/attributes/views: The value is read-only.`,
+	}, details)
+
+	errs = typ.Validate([]byte(`{
+		"data": {
+			"type": "constrained",
+			"attributes": {"title": "", "score": null, "slug": "abc"}
+		}
+	}`))
+	assert.Len(errs, 2)
+	assert.Equal("The value must have a length of at least 1.", errs[0].Detail)
+	assert.Equal("/data/attributes/title", errs[1].Source["pointer"])
+
+	// Enums of numbers and booleans accept null if the attribute is nullable
+	typ = MustBuildType(struct {
+		ID    string   `json:"id" api:"enums"`
+		Size  *float64 `json:"size" api:"attr,enum=1.5|3"`
+		Flag  bool     `json:"flag" api:"attr,enum=true"`
+		Items []string `json:"items" api:"attr,minlen=1,maxlen=2"`
+	}{})
+
+	errs = typ.Validate([]byte(`{
+		"data": {
+			"type": "enums",
+			"attributes": {"size": 1.50, "flag": true, "items": ["a"]}
+		}
+	}`))
+	assert.Empty(errs)
+
+	errs = typ.Validate([]byte(`{
+		"data": {
+			"type": "enums",
+			"attributes": {"size": 2, "flag": false, "items": ["a", "b", "c"]}
+		}
+	}`))
+	assert.Len(errs, 3)
+
+	errs = typ.Validate([]byte(`{"data": {"type": "enums", "attributes": {"size": null}}}`))
+	assert.Empty(errs)
+}
+
+func TestTypeJSONSchemaRelConstraints(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := newConstrainedPostSchema().GetType("posts")
+
+	rels := typ.JSONSchema()["properties"].(map[string]any)["relationships"].(map[string]any)
+	data := func(rel string) map[string]any {
+		v := rels["properties"].(map[string]any)[rel].(map[string]any)["properties"]
+
+		return v.(map[string]any)["data"].(map[string]any)
+	}
+
+	assert.Equal("object", data("author")["type"])
+	assert.Equal(1, data("editors")["minItems"])
+	assert.NotContains(data("editors"), "maxItems")
+	assert.Equal(2, data("tags")["maxItems"])
+	assert.NotContains(data("tags"), "minItems")
+
+	// Validation
+	errs := typ.Validate([]byte(`{
+		"data": {
+			"type": "posts",
+			"relationships": {
+				"author": {"data": null},
+				"editors": {"data": []},
+				"tags": {"data": [
+					{"type": "tags", "id": "t1"},
+					{"type": "tags", "id": "t2"},
+					{"type": "tags", "id": "t3"}
+				]}
+			}
+		}
+	}`))
+
+	pointers := []string{}
+	for _, err := range errs {
+		pointers = append(pointers, err.Source["pointer"].(string))
+	}
+
+	assert.Equal([]string{
+		"/data/relationships/author/data",
+		"/data/relationships/editors/data",
+		"/data/relationships/tags/data",
+	}, pointers)
+}
+
+func TestTypeValidate(t *testing.T) {
+	schema := newMockSchema()
+	typ := schema.GetType("mocktypes1")
+
+	tests := []struct {
+		name     string
+		payload  string
+		pointers []string
+	}{
+		{
+			name: "valid",
+			payload: `{
+				"data": {
+					"type": "mocktypes1",
+					"id": "mt1",
+					"attributes": {
+						"str": "abc",
+						"int8": -128,
+						"uint64": 18446744073709551615,
+						"time": "2019-11-19T23:17:01-05:00"
+					},
+					"relationships": {
+						"to-one": {"data": {"type": "mocktypes2", "id": "mt2"}},
+						"to-many": {"data": [{"type": "mocktypes2", "lid": "l1"}]}
+					}
+				},
+				"meta": {}
+			}`,
+			pointers: []string{},
+		}, {
+			name: "empty to-one relationship",
+			payload: `{
+				"data": {
+					"type": "mocktypes1",
+					"relationships": {
+						"to-one": {"data": null}
+					}
+				}
+			}`,
+			pointers: []string{},
+		}, {
+			name:     "missing data",
+			payload:  `{"meta": {}}`,
+			pointers: []string{""},
+		}, {
+			name:     "invalid type",
+			payload:  `{"data": {"type": "mocktypes2", "id": "mt1"}}`,
+			pointers: []string{"/data/type"},
+		}, {
+			name: "many errors",
+			payload: `{
+				"data": {
+					"type": "mocktypes1",
+					"id": 1,
+					"unknown": true,
+					"attributes": {
+						"str": null,
+						"int8": 128,
+						"uint": -1,
+						"int": 1.5,
+						"time": "yesterday",
+						"unknown~/": 0
+					},
+					"relationships": {
+						"to-one": {"data": {"type": "mocktypes2"}},
+						"to-many": {"data": [
+							{"type": "mocktypes1", "id": "mt1"},
+							{"type": "mocktypes2", "id": 2}
+						]},
+						"to-many-from-one": {}
+					}
+				}
+			}`,
+			pointers: []string{
+				"/data/attributes/int",
+				"/data/attributes/int8",
+				"/data/attributes/str",
+				"/data/attributes/time",
+				"/data/attributes/uint",
+				"/data/attributes/unknown~0~1",
+				"/data/id",
+				"/data/relationships/to-many/data/0/type",
+				"/data/relationships/to-many/data/1/id",
+				"/data/relationships/to-many-from-one",
+				"/data/relationships/to-one/data",
+				"/data/unknown",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			errs := typ.Validate([]byte(test.payload))
+
+			pointers := []string{}
+			for _, err := range errs {
+				assert.Equal("400", err.Status)
+				pointers = append(pointers, err.Source["pointer"].(string))
+			}
+
+			assert.Equal(test.pointers, pointers)
+		})
+	}
+
+	// Invalid JSON
+	errs := typ.Validate([]byte(`{`))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "Invalid JSON", errs[0].Title)
+
+	// The violations are returned as Errors
+	errs = typ.Validate([]byte(`{"data": {"type": "mocktypes1"}}`))
+	assert.Nil(t, errs)
+
+	errs = typ.Validate([]byte(`{"meta": {}}`))
+	assert.EqualError(t, errs, `400 Bad Request: The member "data" is required.`)
+}
@@ -0,0 +1,123 @@
+package jsonapi
+
+// A LIDHolder can hold and return a local ID.
+//
+// A local ID (lid) identifies a resource created by the client before the
+// server assigns it an ID. SoftResource and Wrapper implement this interface.
+type LIDHolder interface {
+	GetLID() string
+	SetLID(lid string)
+}
+
+// A LIDResolver maps local IDs to the IDs assigned by the server.
+//
+// Once the resources are created and their IDs are known, the resolver can
+// rewrite the local IDs found in resources, identifiers, and documents.
+// Relationships that point to a resource identified by a local ID are expected
+// to hold the local ID as a value, which is what UnmarshalResource does.
+//
+// The zero value is ready to use.
+type LIDResolver struct {
+	ids map[string]map[string]string
+}
+
+// Add records that the resource of type typ identified by the local ID lid was
+// assigned id.
+func (r *LIDResolver) Add(typ, lid, id string) {
+	if r.ids == nil {
+		r.ids = map[string]map[string]string{}
+	}
+
+	if r.ids[typ] == nil {
+		r.ids[typ] = map[string]string{}
+	}
+
+	r.ids[typ][lid] = id
+}
+
+// AddResource records the ID of res if it has both an ID and a local ID.
+func (r *LIDResolver) AddResource(res Resource) {
+	lh, ok := res.(LIDHolder)
+	if !ok || lh.GetLID() == "" {
+		return
+	}
+
+	if id := res.Get("id").(string); id != "" {
+		r.Add(res.GetType().Name, lh.GetLID(), id)
+	}
+}
+
+// ID returns the ID assigned to the resource of type typ identified by the
+// local ID lid, and whether it is known.
+func (r *LIDResolver) ID(typ, lid string) (string, bool) {
+	id, ok := r.ids[typ][lid]
+
+	return id, ok
+}
+
+// ResolveIdentifier returns iden with its ID set if its local ID is known.
+func (r *LIDResolver) ResolveIdentifier(iden Identifier) Identifier {
+	if iden.ID == "" && iden.LID != "" {
+		if id, ok := r.ID(iden.Type, iden.LID); ok {
+			iden.ID = id
+		}
+	}
+
+	return iden
+}
+
+// ResolveResource sets the ID of res if it is empty and its local ID is known,
+// and replaces the known local IDs found in its relationships.
+func (r *LIDResolver) ResolveResource(res Resource) {
+	if lh, ok := res.(LIDHolder); ok && res.Get("id").(string) == "" {
+		if id, ok := r.ID(res.GetType().Name, lh.GetLID()); ok {
+			res.Set("id", id)
+		}
+	}
+
+	for _, rel := range res.Rels() {
+		idens := relIdentifiers(res, rel)
+
+		for i := range idens {
+			idens[i].ID = r.resolve(idens[i].Type, idens[i].ID)
+		}
+
+		res.Set(rel.FromName, relValue(rel, idens))
+	}
+}
+
+// ResolveDocument resolves the local IDs found in the primary data and the
+// included resources of doc.
+func (r *LIDResolver) ResolveDocument(doc *Document) {
+	switch d := doc.Data.(type) {
+	case Resource:
+		r.ResolveResource(d)
+	case Collection:
+		for i := 0; i < d.Len(); i++ {
+			r.ResolveResource(d.At(i))
+		}
+	case Identifier:
+		doc.Data = r.ResolveIdentifier(d)
+	case Identifiers:
+		idens := make(Identifiers, len(d))
+		for i := range d {
+			idens[i] = r.ResolveIdentifier(d[i])
+		}
+
+		doc.Data = idens
+	}
+
+	for _, res := range doc.Included {
+		r.ResolveResource(res)
+	}
+}
+
+// resolve returns the ID assigned to the resource of type typ if id is one of
+// the known local IDs. Otherwise, id is returned.
+func (r *LIDResolver) resolve(typ, id string) string {
+	if newID, ok := r.ID(typ, id); ok {
+		return newID
+	}
+
+	return id
+}
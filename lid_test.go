@@ -0,0 +1,105 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLIDResolver(t *testing.T) {
+	assert := assert.New(t)
+
+	typ, _ := BuildType(mocktype{})
+
+	newRes := func(lid string) *SoftResource {
+		res := &SoftResource{Type: &typ}
+		res.SetLID(lid)
+
+		return res
+	}
+
+	resolver := &LIDResolver{}
+
+	// Unknown local IDs
+	_, ok := resolver.ID("mocktype", "local1")
+	assert.False(ok)
+
+	resolver.Add("mocktype", "local1", "id1")
+
+	id, ok := resolver.ID("mocktype", "local1")
+	assert.True(ok)
+	assert.Equal("id1", id)
+
+	// Local IDs are scoped by type
+	_, ok = resolver.ID("other", "local1")
+	assert.False(ok)
+
+	// Resource with an ID and a local ID
+	res2 := newRes("local2")
+	res2.SetID("id2")
+	resolver.AddResource(res2)
+
+	id, _ = resolver.ID("mocktype", "local2")
+	assert.Equal("id2", id)
+
+	// Resource without an ID is ignored
+	resolver.AddResource(newRes("local3"))
+
+	_, ok = resolver.ID("mocktype", "local3")
+	assert.False(ok)
+
+	// Identifiers
+	assert.Equal(
+		Identifier{ID: "id1", Type: "mocktype", LID: "local1"},
+		resolver.ResolveIdentifier(Identifier{Type: "mocktype", LID: "local1"}),
+	)
+	assert.Equal(
+		Identifier{Type: "mocktype", LID: "local3"},
+		resolver.ResolveIdentifier(Identifier{Type: "mocktype", LID: "local3"}),
+	)
+
+	// Resources
+	res := newRes("local1")
+	res.Set("to-1", "local2")
+	res.Set("to-x", []string{"local1", "local3", "id4"})
+	resolver.ResolveResource(res)
+
+	assert.Equal("id1", res.GetID())
+	assert.Equal("id2", res.Get("to-1"))
+	assert.Equal([]string{"id1", "local3", "id4"}, res.Get("to-x"))
+
+	// Documents
+	inc := newRes("local2")
+	doc := &Document{
+		Data:     newRes("local1"),
+		Included: []Resource{inc},
+	}
+	resolver.ResolveDocument(doc)
+
+	assert.Equal("id1", doc.Data.(Resource).Get("id"))
+	assert.Equal("id2", inc.GetID())
+
+	col := &SoftCollection{Type: &typ}
+	col.Add(newRes("local1"))
+	doc = &Document{Data: col}
+	resolver.ResolveDocument(doc)
+
+	assert.Equal("id1", col.At(0).Get("id"))
+
+	doc = &Document{
+		Data: Identifiers{
+			{Type: "mocktype", LID: "local1"},
+			{Type: "mocktype", ID: "id4"},
+		},
+	}
+	resolver.ResolveDocument(doc)
+
+	assert.Equal([]string{"id1", "id4"}, doc.Data.(Identifiers).IDs())
+
+	doc = &Document{Data: Identifier{Type: "mocktype", LID: "local2"}}
+	resolver.ResolveDocument(doc)
+
+	assert.Equal("id2", doc.Data.(Identifier).ID)
+}
@@ -5,30 +5,134 @@ import (
 	"strings"
 )
 
-// Link represents a JSON:API links object.
+// Link represents a JSON:API link, which is marshaled as a string if it only
+// has an href, or as a link object otherwise.
 type Link struct {
-	HRef string         `json:"href"`
+	HRef string `json:"href"`
+
+	// Rel is the relation type of the link.
+	Rel string `json:"rel"`
+
+	// DescribedBy is a link to a description document, like a JSON Schema
+	// or an OpenAPI document, of the target.
+	DescribedBy *Link `json:"describedby"`
+
+	// Title is a human-readable label for the target.
+	Title string `json:"title"`
+
+	// Type is the media type of the target.
+	Type string `json:"type"`
+
+	// HRefLang holds the languages of the target. It is marshaled as a
+	// string if it holds a single language.
+	HRefLang []string `json:"hreflang"`
+
 	Meta map[string]any `json:"meta"`
 }
 
 // MarshalJSON builds the JSON representation of a Link object.
 func (l Link) MarshalJSON() ([]byte, error) {
-	if len(l.Meta) > 0 {
-		var err error
+	if l.Rel == "" && l.DescribedBy == nil && l.Title == "" && l.Type == "" &&
+		len(l.HRefLang) == 0 && len(l.Meta) == 0 {
+		return json.Marshal(l.HRef)
+	}
+
+	var err error
+
+	m := map[string]json.RawMessage{}
 
-		m := map[string]json.RawMessage{}
+	m["href"], _ = json.Marshal(l.HRef)
 
-		m["href"], _ = json.Marshal(l.HRef)
+	for name, val := range map[string]string{
+		"rel":   l.Rel,
+		"title": l.Title,
+		"type":  l.Type,
+	} {
+		if val != "" {
+			m[name], _ = json.Marshal(val)
+		}
+	}
+
+	if l.DescribedBy != nil {
+		m["describedby"], err = json.Marshal(l.DescribedBy)
+		if err != nil {
+			return []byte{}, err
+		}
+	}
 
+	switch len(l.HRefLang) {
+	case 0:
+	case 1:
+		m["hreflang"], _ = json.Marshal(l.HRefLang[0])
+	default:
+		m["hreflang"], _ = json.Marshal(l.HRefLang)
+	}
+
+	if len(l.Meta) > 0 {
 		m["meta"], err = json.Marshal(l.Meta)
 		if err != nil {
 			return []byte{}, err
 		}
+	}
+
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON reads a link represented either by a string or by an object.
+func (l *Link) UnmarshalJSON(payload []byte) error {
+	if len(payload) > 0 && payload[0] == '"' {
+		*l = Link{}
+
+		return json.Unmarshal(payload, &l.HRef)
+	}
+
+	lske := struct {
+		HRef        string          `json:"href"`
+		Rel         string          `json:"rel"`
+		DescribedBy *Link           `json:"describedby"`
+		Title       string          `json:"title"`
+		Type        string          `json:"type"`
+		HRefLang    json.RawMessage `json:"hreflang"`
+		Meta        map[string]any  `json:"meta"`
+	}{}
+
+	err := json.Unmarshal(payload, &lske)
+	if err != nil {
+		return err
+	}
+
+	*l = Link{
+		HRef:        lske.HRef,
+		Rel:         lske.Rel,
+		DescribedBy: lske.DescribedBy,
+		Title:       lske.Title,
+		Type:        lske.Type,
+		Meta:        lske.Meta,
+	}
 
-		return json.Marshal(m)
+	// The languages can be a string or an array of strings.
+	switch {
+	case len(lske.HRefLang) == 0 || string(lske.HRefLang) == "null":
+	case lske.HRefLang[0] == '"':
+		l.HRefLang = []string{""}
+		err = json.Unmarshal(lske.HRefLang, &l.HRefLang[0])
+	default:
+		err = json.Unmarshal(lske.HRefLang, &l.HRefLang)
 	}
 
-	return json.Marshal(l.HRef)
+	return err
+}
+
+// A LinkHolder can hold and return links.
+//
+// When a resource that implements this interface is marshaled, its links are
+// added to the self link that is always generated, and replace it if one of
+// them is also named self.
+//
+// Implementations don't have to deeply copy the maps.
+type LinkHolder interface {
+	Links() map[string]Link
+	SetLinks(map[string]Link)
 }
 
 // buildSelfLink builds a URL that points to the resource represented by the
@@ -53,9 +157,20 @@ func buildSelfLink(res Resource, prepath string) string {
 
 // buildRelationshipLinks builds a links object (according to the JSON:API
 // specification) that include both the self and related members.
-func buildRelationshipLinks(res Resource, prepath, rel string) map[string]string {
-	return map[string]string{
-		"self":    buildSelfLink(res, prepath) + "/relationships/" + rel,
-		"related": buildSelfLink(res, prepath) + "/" + rel,
+//
+// If res is a RelMetaHolder, the links of the relationship are added and
+// replace the generated ones.
+func buildRelationshipLinks(res Resource, prepath, rel string) map[string]Link {
+	links := map[string]Link{
+		"self":    {HRef: buildSelfLink(res, prepath) + "/relationships/" + rel},
+		"related": {HRef: buildSelfLink(res, prepath) + "/" + rel},
 	}
+
+	if rm, ok := res.(RelMetaHolder); ok {
+		for name, link := range rm.RelLinks(rel) {
+			links[name] = link
+		}
+	}
+
+	return links
 }
@@ -34,6 +34,32 @@ func TestMarshalLink(t *testing.T) {
 				},
 			},
 			expectedPayload: `{"href":"example.org","meta":{"n":123,"s":"abc"}}`,
+		}, {
+			link: jsonapi.Link{
+				HRef:        "example.org",
+				Rel:         "author",
+				DescribedBy: &jsonapi.Link{HRef: "example.org/schema"},
+				Title:       "Author",
+				Type:        "text/html",
+				HRefLang:    []string{"en"},
+			},
+			expectedPayload: `{"describedby":"example.org/schema","href":"example.org",` +
+				`"hreflang":"en","rel":"author","title":"Author","type":"text/html"}`,
+		}, {
+			link: jsonapi.Link{
+				HRef:     "example.org",
+				HRefLang: []string{"en", "fr"},
+			},
+			expectedPayload: `{"href":"example.org","hreflang":["en","fr"]}`,
+		}, {
+			link: jsonapi.Link{
+				HRef: "example.org",
+				DescribedBy: &jsonapi.Link{
+					HRef: "example.org/schema",
+					Meta: map[string]any{"bad": badMarshaler{}},
+				},
+			},
+			expectedErr: true,
 		}, {
 			link: jsonapi.Link{
 				HRef: "example.org",
@@ -52,6 +78,72 @@ func TestMarshalLink(t *testing.T) {
 	}
 }
 
+func TestUnmarshalLink(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		payload      string
+		expectedLink jsonapi.Link
+		expectedErr  bool
+	}{
+		{
+			payload:      `"example.org"`,
+			expectedLink: jsonapi.Link{HRef: "example.org"},
+		}, {
+			payload: `{"href":"example.org","meta":{"s":"abc"}}`,
+			expectedLink: jsonapi.Link{
+				HRef: "example.org",
+				Meta: map[string]any{"s": "abc"},
+			},
+		}, {
+			payload: `{
+				"href": "example.org",
+				"rel": "author",
+				"describedby": {"href": "example.org/schema", "title": "Schema"},
+				"title": "Author",
+				"type": "text/html",
+				"hreflang": "en"
+			}`,
+			expectedLink: jsonapi.Link{
+				HRef: "example.org",
+				Rel:  "author",
+				DescribedBy: &jsonapi.Link{
+					HRef:  "example.org/schema",
+					Title: "Schema",
+				},
+				Title:    "Author",
+				Type:     "text/html",
+				HRefLang: []string{"en"},
+			},
+		}, {
+			payload: `{"href":"example.org","hreflang":["en","fr"]}`,
+			expectedLink: jsonapi.Link{
+				HRef:     "example.org",
+				HRefLang: []string{"en", "fr"},
+			},
+		}, {
+			payload: `{"href":"example.org","hreflang":1}`,
+			expectedLink: jsonapi.Link{
+				HRef: "example.org",
+			},
+			expectedErr: true,
+		}, {
+			payload:      `null`,
+			expectedLink: jsonapi.Link{},
+		}, {
+			payload:     `123`,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		link := jsonapi.Link{}
+		err := link.UnmarshalJSON([]byte(test.payload))
+		assert.Equal(test.expectedErr, err != nil, test.payload)
+		assert.Equal(test.expectedLink, link, test.payload)
+	}
+}
+
 type badMarshaler struct{}
 
 func (b badMarshaler) MarshalJSON() ([]byte, error) {
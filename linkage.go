@@ -0,0 +1,254 @@
+package jsonapi
+
+import "strconv"
+
+// CheckFullLinkage checks that doc satisfies the full linkage requirement of
+// the JSON:API specification: every included resource must be reachable from
+// the primary data by following relationships. Only the relationships listed
+// in doc.RelData are followed since they are the ones whose data is part of
+// the payload. UnmarshalDocument sets it accordingly.
+//
+// The specification makes an exception for the relationships excluded by
+// sparse fieldsets. Since the fieldsets are not known here, an included
+// resource that is not reachable is accepted if the document contains a type
+// with a relationship to its type that is not part of doc.RelData.
+//
+// An included resource that appears more than once or that is also part of
+// the primary data is reported as well. The violations are returned as Errors
+// whose source pointers indicate the included resources, or nil if there are
+// none.
+//
+// Nothing is checked if the primary data is not a resource or a collection.
+func CheckFullLinkage(doc *Document) error {
+	lc := newLinkageChecker(doc.RelData)
+
+	switch data := doc.Data.(type) {
+	case Resource:
+		lc.addPrimary(data)
+	case Collection:
+		for i := 0; i < data.Len(); i++ {
+			lc.addPrimary(data.At(i))
+		}
+	default:
+		return nil
+	}
+
+	for i, res := range doc.Included {
+		lc.addIncluded(res, i)
+	}
+
+	if errs := lc.check(); len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// linkageChecker checks the full linkage of a compound document.
+//
+// The primary data and the included resources are added one at a time and
+// only their identifiers and the identifiers they point to are kept, which
+// allows documents to be checked while they are streamed. relData is only read
+// by check, so it can be filled while the resources are added.
+type linkageChecker struct {
+	relData map[string][]string
+	types   map[string]Type
+
+	primary  map[string]struct{}
+	roots    []linkageNode
+	included []linkageNode
+	keys     map[string]int
+}
+
+// linkageNode is a resource of the primary data or an included resource.
+type linkageNode struct {
+	typ, id string
+	index   int
+	keys    []string
+	targets linkageTargets
+	dup     bool
+}
+
+// linkageTargets maps the names of the relationships of a resource to the keys
+// of the resources they point to.
+type linkageTargets map[string][]string
+
+func newLinkageChecker(relData map[string][]string) *linkageChecker {
+	return &linkageChecker{
+		relData: relData,
+		types:   map[string]Type{},
+		primary: map[string]struct{}{},
+		keys:    map[string]int{},
+	}
+}
+
+// addPrimary adds a resource of the primary data.
+func (lc *linkageChecker) addPrimary(res Resource) {
+	lc.types[res.GetType().Name] = res.GetType()
+
+	for _, key := range linkageKeys(res) {
+		lc.primary[key] = struct{}{}
+	}
+
+	lc.roots = append(lc.roots, linkageNode{
+		typ:     res.GetType().Name,
+		targets: targets(res),
+	})
+}
+
+// addIncluded adds the included resource found at index i.
+func (lc *linkageChecker) addIncluded(res Resource, i int) {
+	lc.types[res.GetType().Name] = res.GetType()
+
+	node := linkageNode{
+		typ:     res.GetType().Name,
+		id:      res.Get("id").(string),
+		index:   i,
+		keys:    linkageKeys(res),
+		targets: targets(res),
+	}
+
+	if node.id == "" {
+		if l, ok := res.(LIDHolder); ok {
+			node.id = l.GetLID()
+		}
+	}
+
+	for _, key := range node.keys {
+		if _, ok := lc.keys[key]; ok {
+			node.dup = true
+		} else {
+			lc.keys[key] = len(lc.included)
+		}
+	}
+
+	lc.included = append(lc.included, node)
+}
+
+// check returns the violations found in the resources added so far, in the
+// order of the included resources.
+func (lc *linkageChecker) check() Errors {
+	reached := make([]bool, len(lc.included))
+	queue := []int{}
+
+	reach := func(key string) {
+		if n, ok := lc.keys[key]; ok && !reached[n] {
+			reached[n] = true
+			queue = append(queue, n)
+		}
+	}
+
+	for _, node := range lc.roots {
+		lc.follow(node, reach)
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		lc.follow(lc.included[n], reach)
+	}
+
+	errs := Errors{}
+
+	for n, node := range lc.included {
+		var err Error
+
+		switch {
+		case node.dup:
+			err = NewErrDuplicateIncludedResource(node.typ, node.id)
+		case lc.isPrimary(node):
+			err = NewErrIncludedPrimaryResource(node.typ, node.id)
+		case !reached[n] && !lc.omitted(node.typ):
+			err = NewErrUnlinkedIncludedResource(node.typ, node.id)
+		default:
+			continue
+		}
+
+		err.Source["pointer"] = "/included/" + strconv.Itoa(node.index)
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// isPrimary reports whether node is also part of the primary data.
+func (lc *linkageChecker) isPrimary(node linkageNode) bool {
+	for _, key := range node.keys {
+		if _, ok := lc.primary[key]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// omitted reports whether a type found in the document has a relationship to
+// type typ whose data is not part of the document.
+func (lc *linkageChecker) omitted(typ string) bool {
+	for _, t := range lc.types {
+		for _, rel := range t.Rels {
+			if !rel.Targets(typ) {
+				continue
+			}
+
+			found := false
+
+			for _, name := range lc.relData[t.Name] {
+				found = found || name == rel.FromName
+			}
+
+			if !found {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// follow calls fn with the keys of the resources that node points to through
+// the relationships listed in relData.
+func (lc *linkageChecker) follow(node linkageNode, fn func(key string)) {
+	for _, name := range lc.relData[node.typ] {
+		for _, key := range node.targets[name] {
+			fn(key)
+		}
+	}
+}
+
+// targets returns the keys of the resources that res points to.
+func targets(res Resource) linkageTargets {
+	targets := linkageTargets{}
+
+	for _, rel := range res.GetType().Rels {
+		for _, iden := range relIdentifiers(res, rel) {
+			// The value can be either an ID or a local ID.
+			targets[rel.FromName] = append(
+				targets[rel.FromName],
+				iden.Type+" "+iden.ID,
+				iden.Type+" lid:"+iden.ID,
+			)
+		}
+	}
+
+	return targets
+}
+
+// linkageKeys returns the keys that identify res: one for its ID and one for
+// its local ID, if they are set.
+func linkageKeys(res Resource) []string {
+	var keys []string
+
+	typ := res.GetType().Name
+
+	if id := res.Get("id").(string); id != "" {
+		keys = append(keys, typ+" "+id)
+	}
+
+	if l, ok := res.(LIDHolder); ok && l.GetLID() != "" {
+		keys = append(keys, typ+" lid:"+l.GetLID())
+	}
+
+	return keys
+}
@@ -0,0 +1,175 @@
+package jsonapi_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckFullLinkage(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+	artType := schema.GetType("articles")
+	userType := schema.GetType("users")
+
+	article := func(id, author string, related ...string) Resource {
+		res := &SoftResource{Type: &artType}
+		res.SetID(id)
+		res.Set("author", author)
+		res.Set("related", related)
+
+		return res
+	}
+
+	user := func(id string, articles ...string) Resource {
+		res := &SoftResource{Type: &userType}
+		res.SetID(id)
+		res.Set("articles", articles)
+
+		return res
+	}
+
+	local := &SoftResource{Type: &artType}
+	local.SetLID("tmp")
+
+	allRels := map[string][]string{
+		"articles": {"author", "related"},
+		"users":    {"articles"},
+	}
+
+	tests := []struct {
+		name     string
+		doc      *Document
+		expected error
+	}{
+		{
+			name: "no included resources",
+			doc:  &Document{Data: article("a1", "u1")},
+		}, {
+			name: "linked from a resource",
+			doc: &Document{
+				Data:     article("a1", "u1"),
+				Included: []Resource{user("u1")},
+				RelData:  allRels,
+			},
+		}, {
+			name: "linked through included resources",
+			doc: &Document{
+				Data: &Resources{article("a1", "", "a2")},
+				Included: []Resource{
+					user("u1", "a3"),
+					article("a3", ""),
+					article("a2", "u1"),
+				},
+				RelData: allRels,
+			},
+		}, {
+			name: "linked with a local ID",
+			doc: &Document{
+				Data:     article("a1", "", "tmp"),
+				Included: []Resource{local},
+				RelData:  allRels,
+			},
+		}, {
+			name: "relationship without data",
+			doc: &Document{
+				Data:     article("a1", "u1"),
+				Included: []Resource{user("u1")},
+				RelData:  map[string][]string{},
+			},
+		}, {
+			name: "not linked",
+			doc: &Document{
+				Data:     article("a1", "u1"),
+				Included: []Resource{user("u1"), user("u2")},
+				RelData:  allRels,
+			},
+			expected: Errors{
+				withPointer(NewErrUnlinkedIncludedResource("users", "u2"), "/included/1"),
+			},
+		}, {
+			name: "duplicate and primary",
+			doc: &Document{
+				Data:     &Resources{article("a1", "u1", "a1")},
+				Included: []Resource{user("u1"), article("a1", ""), user("u1")},
+				RelData:  allRels,
+			},
+			expected: Errors{
+				withPointer(NewErrIncludedPrimaryResource("articles", "a1"), "/included/1"),
+				withPointer(NewErrDuplicateIncludedResource("users", "u1"), "/included/2"),
+			},
+		}, {
+			name: "identifiers",
+			doc: &Document{
+				Data:     Identifiers{{Type: "users", ID: "u1"}},
+				Included: []Resource{user("u2")},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		err := CheckFullLinkage(test.doc)
+		assert.Equal(test.expected, err, test.name)
+	}
+}
+
+func TestUnmarshalDocumentFullLinkage(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+
+	payload := `{
+		"data": {
+			"type": "articles",
+			"id": "a1",
+			"relationships": {
+				"author": {"data": {"type": "users", "id": "u1"}},
+				"related": {"data": []}
+			}
+		},
+		"included": [
+			{"type": "users", "id": "u1"},
+			{"type": "users", "id": "u2"}
+		]
+	}`
+
+	expected := withPointer(NewErrUnlinkedIncludedResource("users", "u2"), "/included/1")
+
+	_, err := UnmarshalDocument([]byte(payload), schema)
+	assert.Equal(expected, err)
+
+	_, err = NewDecoder(strings.NewReader(payload), schema).Decode(nil)
+	assert.Equal(Errors{expected}, err)
+
+	_, err = UnmarshalDocumentAll([]byte(payload), schema)
+	assert.Equal(Errors{expected}, err)
+
+	// The included resources come first.
+	payload = `{
+		"included": [{"type": "users", "id": "u1"}],
+		"data": [{
+			"type": "articles",
+			"id": "a1",
+			"relationships": {"author": {"data": {"type": "users", "id": "u1"}}}
+		}]
+	}`
+
+	_, err = UnmarshalDocument([]byte(payload), schema)
+	assert.NoError(err)
+
+	_, err = NewDecoder(strings.NewReader(payload), schema).Decode(nil)
+	assert.NoError(err)
+
+	// The relationship could have been excluded by a sparse fieldset.
+	payload = `{
+		"data": [{"type": "articles", "id": "a1", "attributes": {"title": "Title"}}],
+		"included": [{"type": "users", "id": "u1"}]
+	}`
+
+	_, err = UnmarshalDocument([]byte(payload), schema)
+	assert.NoError(err)
+}
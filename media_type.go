@@ -0,0 +1,192 @@
+package jsonapi
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MediaType is the media type of JSON:API documents.
+const MediaType = "application/vnd.api+json"
+
+// A Negotiator negotiates the JSON:API media type of requests and responses.
+//
+// Extensions and Profiles hold the URIs of the extensions and profiles that
+// are supported. The other profiles requested by a client are ignored, as the
+// specification requires.
+type Negotiator struct {
+	Extensions []string
+	Profiles   []string
+}
+
+// Negotiate checks the Content-Type and Accept headers of r and returns the
+// extensions and profiles that apply to the request and its response.
+//
+// A 415 error is returned if the Content-Type header is set to a media type
+// other than MediaType, if it has parameters other than ext and profile, or if
+// it requires an unsupported extension. A request without a Content-Type
+// header is accepted.
+//
+// A 406 error is returned if the Accept header contains the JSON:API media
+// type, but only with parameters other than ext and profile or with
+// unsupported extensions.
+//
+// The applied extensions and profiles are the ones of the Content-Type header
+// if it has any, or else the ones of the first acceptable JSON:API media type
+// of the Accept header.
+func (n *Negotiator) Negotiate(r *http.Request) (ext, profile []string, err error) {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		params, ok := parseMediaType(ct, false)
+		if !ok || !n.supports(params["ext"]) {
+			e := NewErrUnsupportedMediaType()
+			e.Detail = "The media type of the request body is not supported."
+			e.Source["header"] = "Content-Type"
+
+			return nil, nil, e
+		}
+
+		ext, profile = params["ext"], n.profiles(params["profile"])
+	}
+
+	found, acceptable := false, false
+
+	for _, val := range r.Header["Accept"] {
+		for _, part := range strings.Split(val, ",") {
+			params, ok := parseMediaType(part, true)
+			if params == nil {
+				// Another media type
+				continue
+			}
+
+			found = true
+
+			if !ok || !n.supports(params["ext"]) {
+				continue
+			}
+
+			if !acceptable && len(ext) == 0 && len(profile) == 0 {
+				ext, profile = params["ext"], n.profiles(params["profile"])
+			}
+
+			acceptable = true
+		}
+	}
+
+	if found && !acceptable {
+		e := NewErrNotAcceptable()
+		e.Detail = "None of the accepted JSON:API media types is supported."
+		e.Source["header"] = "Accept"
+
+		return nil, nil, e
+	}
+
+	return ext, profile, nil
+}
+
+// NewRequest builds and returns a *Request like the NewRequest function, but
+// the extensions and profiles of n are the supported ones.
+//
+// An error is returned if the jsonapi object of the request document
+// advertises an extension that is not applied (see JSONAPI.CheckExt).
+func (n *Negotiator) NewRequest(r *http.Request, schema *Schema) (*Request, error) {
+	ext, profile, err := n.Negotiate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := newRequest(r, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Doc != nil {
+		err = req.Doc.JSONAPI.CheckExt(ext)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req.Ext = ext
+	req.Profile = profile
+
+	return req, nil
+}
+
+// supports reports whether all the extensions in ext are supported.
+func (n *Negotiator) supports(ext []string) bool {
+	for _, uri := range ext {
+		found := false
+
+		for _, s := range n.Extensions {
+			found = found || s == uri
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// profiles returns the profiles of profile that are supported.
+func (n *Negotiator) profiles(profile []string) []string {
+	var supported []string
+
+	for _, uri := range profile {
+		for _, s := range n.Profiles {
+			if s == uri {
+				supported = append(supported, uri)
+				break
+			}
+		}
+	}
+
+	return supported
+}
+
+// ContentType returns the JSON:API media type with the ext and profile
+// parameters set to the given URIs, if any.
+func ContentType(ext, profile []string) string {
+	params := map[string]string{}
+
+	if len(ext) > 0 {
+		params["ext"] = strings.Join(ext, " ")
+	}
+
+	if len(profile) > 0 {
+		params["profile"] = strings.Join(profile, " ")
+	}
+
+	return mime.FormatMediaType(MediaType, params)
+}
+
+// parseMediaType parses the media type found in s, which is an element of an
+// Accept header if accept is true.
+//
+// If it is the JSON:API media type, the URIs of its ext and profile parameters
+// are returned, along with whether there are no other parameters. Otherwise,
+// nil is returned. The q parameter of an Accept header is ignored, unless it
+// is 0 which makes the media type unacceptable.
+func parseMediaType(s string, accept bool) (map[string][]string, bool) {
+	mt, params, err := mime.ParseMediaType(strings.TrimSpace(s))
+	if err != nil || mt != MediaType {
+		return nil, false
+	}
+
+	uris := map[string][]string{}
+	ok := true
+
+	for name, val := range params {
+		switch name {
+		case "ext", "profile":
+			uris[name] = strings.Fields(val)
+		case "q":
+			ok = ok && accept && strings.Trim(val, "0.") != ""
+		default:
+			ok = false
+		}
+	}
+
+	return uris, ok
+}
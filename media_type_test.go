@@ -0,0 +1,157 @@
+package jsonapi_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiator(t *testing.T) {
+	assert := assert.New(t)
+
+	n := &Negotiator{
+		Extensions: []string{AtomicExt},
+		Profiles:   []string{"https://example.com/profile"},
+	}
+
+	tests := []struct {
+		name            string
+		contentType     string
+		accept          []string
+		expectedExt     []string
+		expectedProfile []string
+		expectedHeader  string
+		expectedStatus  string
+	}{
+		{
+			name: "no headers",
+		}, {
+			name:        "plain media types",
+			contentType: MediaType,
+			accept:      []string{MediaType},
+		}, {
+			name:        "extension in content type",
+			contentType: MediaType + `; ext="` + AtomicExt + `"`,
+			accept:      []string{MediaType},
+			expectedExt: []string{AtomicExt},
+		}, {
+			name:        "unsupported extension in content type",
+			contentType: MediaType + `; ext="https://example.com/ext"`,
+			// The error is about the content type, not the accepted types.
+			accept:         []string{MediaType + "; charset=utf-8"},
+			expectedHeader: "Content-Type",
+			expectedStatus: "415",
+		}, {
+			name:           "unknown parameter in content type",
+			contentType:    MediaType + "; charset=utf-8",
+			expectedHeader: "Content-Type",
+			expectedStatus: "415",
+		}, {
+			name:           "q parameter in content type",
+			contentType:    MediaType + "; q=0.5",
+			expectedHeader: "Content-Type",
+			expectedStatus: "415",
+		}, {
+			name:           "other content type",
+			contentType:    "application/json",
+			expectedHeader: "Content-Type",
+			expectedStatus: "415",
+		}, {
+			name: "profiles",
+			contentType: MediaType +
+				`; profile="https://example.com/other https://example.com/profile"`,
+			expectedProfile: []string{"https://example.com/profile"},
+		}, {
+			name: "first acceptable media type",
+			accept: []string{
+				"text/html, " + MediaType + "; charset=utf-8",
+				MediaType + `; ext="` + AtomicExt + `"; q=0.9, ` + MediaType,
+			},
+			expectedExt: []string{AtomicExt},
+		}, {
+			name:   "other accepted media types",
+			accept: []string{"text/html, */*"},
+		}, {
+			name: "unacceptable media types",
+			accept: []string{
+				MediaType + "; charset=utf-8",
+				MediaType + `; ext="https://example.com/ext", ` + MediaType + "; q=0",
+			},
+			expectedHeader: "Accept",
+			expectedStatus: "406",
+		},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/articles", nil)
+
+		if test.contentType != "" {
+			req.Header.Set("Content-Type", test.contentType)
+		}
+
+		for _, accept := range test.accept {
+			req.Header.Add("Accept", accept)
+		}
+
+		ext, profile, err := n.Negotiate(req)
+
+		if test.expectedStatus == "" {
+			assert.NoError(err, test.name)
+			assert.Equal(test.expectedExt, ext, test.name)
+			assert.Equal(test.expectedProfile, profile, test.name)
+		} else if e, ok := err.(Error); assert.True(ok, test.name) {
+			assert.Equal(test.expectedStatus, e.Status, test.name)
+			assert.Equal(test.expectedHeader, e.Source["header"], test.name)
+		}
+	}
+
+	// Request
+	req := httptest.NewRequest("GET", "/articles", nil)
+	req.Header.Set("Accept", MediaType+`; ext="`+AtomicExt+`"`)
+
+	r, err := n.NewRequest(req, newHandlerSchema())
+	assert.NoError(err)
+	assert.Equal([]string{AtomicExt}, r.Ext)
+
+	_, err = NewRequest(req, newHandlerSchema())
+	assert.EqualError(
+		err,
+		"406 Not Acceptable: None of the accepted JSON:API media types is supported.",
+	)
+
+	// Extension advertised by the document
+	body := `{
+		"data": {"type": "users", "id": "u1"},
+		"jsonapi": {"ext": ["` + AtomicExt + `"]}
+	}`
+
+	req = httptest.NewRequest("POST", "/users", strings.NewReader(body))
+
+	_, err = n.NewRequest(req, newHandlerSchema())
+	assert.Equal(withPointer(NewErrUnappliedExtension(AtomicExt), "/jsonapi/ext/0"), err)
+
+	req = httptest.NewRequest("POST", "/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", MediaType+`; ext="`+AtomicExt+`"`)
+
+	r, err = n.NewRequest(req, newHandlerSchema())
+	assert.NoError(err)
+	assert.Equal([]string{AtomicExt}, r.Doc.JSONAPI.Ext)
+}
+
+func TestContentType(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(MediaType, ContentType(nil, nil))
+	assert.Equal(
+		MediaType+`; ext="https://example.com/a https://example.com/b"`,
+		ContentType([]string{"https://example.com/a", "https://example.com/b"}, nil),
+	)
+	assert.Equal(
+		MediaType+`; ext="https://example.com/a"; profile="https://example.com/p"`,
+		ContentType([]string{"https://example.com/a"}, []string{"https://example.com/p"}),
+	)
+}
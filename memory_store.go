@@ -0,0 +1,330 @@
+package jsonapi
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MemoryStore is a Store that keeps the resources of a type in memory using a
+// SoftCollection.
+//
+// It is safe for concurrent use. The resources it returns are copies, so they
+// can be modified without affecting the stored resources.
+//
+// Like SoftCollection, it is enough for a demo API or test suites, but it is
+// not made for production use.
+type MemoryStore struct {
+	col *SoftCollection
+	mu  sync.RWMutex
+}
+
+// NewMemoryStore returns a new and empty *MemoryStore for resources of type
+// typ.
+//
+// A copy of typ is made, so modifying typ afterwards does not affect the store.
+func NewMemoryStore(typ Type) *MemoryStore {
+	typ = typ.Copy()
+
+	return &MemoryStore{
+		col: &SoftCollection{Type: &typ},
+	}
+}
+
+// GetType returns the type of the resources in the store.
+func (m *MemoryStore) GetType() Type {
+	return m.col.GetType()
+}
+
+// Resource returns a copy of the resource identified by id, or nil if it does
+// not exist.
+func (m *MemoryStore) Resource(id string) (Resource, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := m.resource(id)
+	if res == nil {
+		return nil, nil
+	}
+
+	return res.Copy(), nil
+}
+
+// Range returns copies of the resources arranged according to params.
+//
+// If params does not define a page size, all the resources are returned. The
+// page is defined by cursors (see RangeCursor) if page[after] or page[before]
+// is set, otherwise by page[number].
+//
+// The returned collection is a *SoftCollection that holds the number of
+// resources found before pagination (see TotalHolder).
+func (m *MemoryStore) Range(ids []string, params *Params) (Collection, error) {
+	var (
+		filter *Filter
+		sort   []string
+		page   map[string]any
+	)
+
+	if params != nil {
+		filter = params.Filter
+		sort = params.SortingRules
+		page = params.Page
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	size, num, err := pageSizeAndNumber(page, uint(m.col.Len()))
+	if err != nil {
+		return nil, err
+	}
+
+	typ := m.col.GetType().Copy()
+	col := &SoftCollection{Type: &typ}
+
+	var rang Collection
+
+	after, before := pageCursor(page, "after"), pageCursor(page, "before")
+	if after != "" || before != "" {
+		rang, err = RangeCursor(m.col, ids, filter, sort, size, after, before)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		rang = Range(m.col, ids, filter, sort, size, num)
+	}
+
+	for i := 0; i < rang.Len(); i++ {
+		col.Add(rang.At(i).(*SoftResource).Copy())
+	}
+
+	col.SetTotal(len(filterResources(m.col, ids, filter)))
+
+	return col, nil
+}
+
+// Insert adds a copy of res to the store.
+//
+// An error is returned if a resource with the same ID already exists.
+func (m *MemoryStore) Insert(res Resource) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := res.Get("id").(string)
+
+	if m.resource(id) != nil {
+		return NewErrConflict(fmt.Sprintf("A resource with ID %q already exists.", id))
+	}
+
+	m.col.Add(res)
+
+	// The values are copied so that slices are not shared with res. The
+	// local ID is only meaningful within the request that created it.
+	added := m.col.col[len(m.col.col)-1]
+	added.data = copyData(added.data)
+	added.lid = ""
+
+	return nil
+}
+
+// Update sets the fields of the stored resource to the values found in res.
+//
+// Only the fields defined in res are updated.
+func (m *MemoryStore) Update(res Resource) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := m.resource(res.Get("id").(string))
+	if stored == nil {
+		return NewErrNotFound()
+	}
+
+	// The values are copied so that slices are not shared with res.
+	vals := map[string]any{}
+
+	for _, attr := range res.Attrs() {
+		if _, ok := stored.Type.Attrs[attr.Name]; ok {
+			vals[attr.Name] = res.Get(attr.Name)
+		}
+	}
+
+	for name, v := range copyData(vals) {
+		stored.Set(name, v)
+	}
+
+	for _, rel := range res.Rels() {
+		if _, ok := stored.Type.Rels[rel.FromName]; ok {
+			stored.Set(rel.FromName, copyRelValue(res.Get(rel.FromName)))
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the resource identified by id.
+func (m *MemoryStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.resource(id) == nil {
+		return NewErrNotFound()
+	}
+
+	m.col.Remove(id)
+
+	return nil
+}
+
+// UpdateToOne sets the to-one relationship rel of the resource identified by id
+// to toID.
+func (m *MemoryStore) UpdateToOne(id, rel, toID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.relResource(id, rel, true)
+	if err != nil {
+		return err
+	}
+
+	stored.Set(rel, toID)
+
+	return nil
+}
+
+// UpdateToMany replaces the IDs of the to-many relationship rel of the resource
+// identified by id.
+func (m *MemoryStore) UpdateToMany(id, rel string, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.relResource(id, rel, false)
+	if err != nil {
+		return err
+	}
+
+	stored.Set(rel, addIDs([]string{}, ids))
+
+	return nil
+}
+
+// AddToMany adds ids to the to-many relationship rel of the resource
+// identified by id.
+func (m *MemoryStore) AddToMany(id, rel string, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.relResource(id, rel, false)
+	if err != nil {
+		return err
+	}
+
+	stored.Set(rel, addIDs(stored.Get(rel).([]string), ids))
+
+	return nil
+}
+
+// RemoveFromMany removes ids from the to-many relationship rel of the resource
+// identified by id.
+func (m *MemoryStore) RemoveFromMany(id, rel string, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.relResource(id, rel, false)
+	if err != nil {
+		return err
+	}
+
+	stored.Set(rel, removeIDs(stored.Get(rel).([]string), ids))
+
+	return nil
+}
+
+// resource returns the stored resource identified by id, or nil.
+//
+// The caller must hold the lock.
+func (m *MemoryStore) resource(id string) *SoftResource {
+	if res := m.col.Resource(id, nil); res != nil {
+		return res.(*SoftResource)
+	}
+
+	return nil
+}
+
+// relResource returns the stored resource identified by id after making sure
+// it has a relationship named rel whose kind (to-one or to-many) is toOne.
+//
+// The caller must hold the lock.
+func (m *MemoryStore) relResource(id, rel string, toOne bool) (*SoftResource, error) {
+	stored := m.resource(id)
+	if stored == nil {
+		return nil, NewErrNotFound()
+	}
+
+	if r, ok := stored.Type.Rels[rel]; ok && r.Polymorphic() {
+		return nil, NewErrBadRequest(
+			"Polymorphic relationship",
+			fmt.Sprintf("%q is a polymorphic relationship.", rel),
+		)
+	} else if !ok || r.ToOne != toOne {
+		kind := "to-many"
+		if toOne {
+			kind = "to-one"
+		}
+
+		return nil, NewErrBadRequest(
+			"Invalid relationship",
+			fmt.Sprintf("%q is not a %s relationship.", rel, kind),
+		)
+	}
+
+	return stored, nil
+}
+
+// pageCursor returns the cursor found in page under key, or an empty string.
+func pageCursor(page map[string]any, key string) string {
+	if v, ok := page[key]; ok {
+		return fmt.Sprint(v)
+	}
+
+	return ""
+}
+
+// pageSizeAndNumber reads the size and number of the page found in page.
+//
+// All resources are part of the first page if no size is defined, in which
+// case def is used as the size.
+func pageSizeAndNumber(page map[string]any, def uint) (uint, uint, error) {
+	size, num := def, uint(0)
+
+	if v, ok := page["size"]; ok {
+		n, ok := v.(int)
+		if !ok || n < 0 {
+			return 0, 0, NewErrInvalidPageSizeParameter(fmt.Sprint(v))
+		}
+
+		size = uint(n)
+	}
+
+	if v, ok := page["number"]; ok {
+		n, ok := v.(int)
+		if !ok || n < 0 {
+			return 0, 0, NewErrInvalidPageNumberParameter(fmt.Sprint(v))
+		}
+
+		num = uint(n)
+	}
+
+	return size, num, nil
+}
+
+// copyRelValue returns a copy of the value of a relationship so that slices
+// are not shared.
+func copyRelValue(v any) any {
+	switch v := v.(type) {
+	case []string:
+		return append([]string{}, v...)
+	case Identifiers:
+		return append(Identifiers{}, v...)
+	}
+
+	return v
+}
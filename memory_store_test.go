@@ -0,0 +1,135 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+	"github.com/mfcochauxlaberge/jsonapi/storetest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+func TestMemoryStore(t *testing.T) {
+	storetest.TestStore(t, func(typ Type) Store {
+		return NewMemoryStore(typ)
+	})
+}
+
+func TestMemoryStoreInvalidPage(t *testing.T) {
+	assert := assert.New(t)
+
+	store := NewMemoryStore(storetest.Type())
+
+	_, err := store.Range(nil, &Params{Page: map[string]any{"size": "abc"}})
+	assert.EqualError(
+		err,
+		"400 Bad Request: The page size parameter is not positive integer (including 0).",
+	)
+
+	_, err = store.Range(nil, &Params{Page: map[string]any{"number": -1}})
+	assert.EqualError(
+		err,
+		"400 Bad Request: The page number parameter is not positive integer (including 0).",
+	)
+}
+
+func TestMemoryStoreInvalidRel(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := storetest.Type()
+	store := NewMemoryStore(typ)
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("t1")
+
+	assert.NoError(store.Insert(res))
+
+	err := store.UpdateToOne("t1", "children", "t2")
+	assert.EqualError(err, `400 Bad Request: "children" is not a to-one relationship.`)
+
+	err = store.UpdateToMany("t1", "parent", []string{"t2"})
+	assert.EqualError(err, `400 Bad Request: "parent" is not a to-many relationship.`)
+
+	// Polymorphic relationship
+	typ = MustBuildType(polyComment{})
+	store = NewMemoryStore(typ)
+
+	res = &SoftResource{Type: &typ}
+	res.SetID("c1")
+
+	assert.NoError(store.Insert(res))
+
+	err = store.UpdateToOne("c1", "commentable", "a1")
+	assert.EqualError(err, `400 Bad Request: "commentable" is a polymorphic relationship.`)
+}
+
+func TestMemoryStoreMeta(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := storetest.Type()
+	store := NewMemoryStore(typ)
+	assert.Equal(typ, store.GetType())
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("t1")
+	res.SetMeta(Meta{"key": "value"})
+
+	assert.NoError(store.Insert(res))
+
+	got, err := store.Resource("t1")
+	assert.NoError(err)
+	assert.Equal(Meta{"key": "value"}, got.(MetaHolder).Meta())
+
+	col, err := store.Range(nil, nil)
+	assert.NoError(err)
+	assert.Equal(Meta{"key": "value"}, col.At(0).(MetaHolder).Meta())
+
+	// The meta values are not shared with the store
+	res.Meta()["key"] = "inserted"
+	got.(MetaHolder).Meta()["key"] = "resource"
+	col.At(0).(MetaHolder).Meta()["key"] = "range"
+
+	got, err = store.Resource("t1")
+	assert.NoError(err)
+	assert.Equal(Meta{"key": "value"}, got.(MetaHolder).Meta())
+}
+
+func TestMemoryStoreLinks(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := storetest.Type()
+	store := NewMemoryStore(typ)
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("t1")
+	res.SetLinks(map[string]Link{"about": {HRef: "/about"}})
+
+	assert.NoError(store.Insert(res))
+
+	got, err := store.Resource("t1")
+	assert.NoError(err)
+	assert.Contains(
+		string(MarshalResource(got, "", []string{}, nil)),
+		`"links":{"about":"/about","self":"/things/t1"}`,
+	)
+}
+
+func TestMemoryStoreLID(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := storetest.Type()
+	store := NewMemoryStore(typ)
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("t1")
+	res.SetLID("local1")
+
+	assert.NoError(store.Insert(res))
+
+	// Local IDs are not stored
+	got, err := store.Resource("t1")
+	assert.NoError(err)
+	assert.Equal("", got.(LIDHolder).GetLID())
+}
@@ -64,3 +64,18 @@ type MetaHolder interface {
 	Meta() Meta
 	SetMeta(Meta)
 }
+
+// A RelMetaHolder can hold and return the meta values and the links of the
+// relationships of a resource.
+//
+// When a resource is marshaled, the links of a relationship are added to the
+// self and related links that are always generated, and replace them if they
+// have the same name.
+//
+// Implementations don't have to deeply copy the maps.
+type RelMetaHolder interface {
+	RelMeta(rel string) Meta
+	SetRelMeta(rel string, m Meta)
+	RelLinks(rel string) map[string]Link
+	SetRelLinks(rel string, links map[string]Link)
+}
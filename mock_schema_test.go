@@ -100,3 +100,20 @@ type mockType3 struct {
 	Rel1 string   `json:"rel1" api:"rel,mocktypes1"`
 	Rel2 []string `json:"rel2" api:"rel,mocktypes1"`
 }
+
+// mockTypeNumbers ...
+type mockTypeNumbers struct {
+	ID string `json:"id" api:"mocktypesnumbers"`
+
+	// Attributes
+	Float32    float32         `json:"float32" api:"attr"`
+	Float64    float64         `json:"float64" api:"attr"`
+	Decimal    Decimal         `json:"decimal" api:"attr"`
+	Strings    []string        `json:"strings" api:"attr"`
+	Numbers    []float64       `json:"numbers" api:"attr"`
+	Object     map[string]any  `json:"object" api:"attr"`
+	Float64Ptr *float64        `json:"float64ptr" api:"attr"`
+	DecimalPtr *Decimal        `json:"decimalptr" api:"attr"`
+	StringsPtr *[]string       `json:"stringsptr" api:"attr"`
+	ObjectPtr  *map[string]any `json:"objectptr" api:"attr"`
+}
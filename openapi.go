@@ -0,0 +1,624 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification followed by the
+// documents generated by OpenAPI.
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPIInfo holds the general information about an API described in an
+// OpenAPI document.
+type OpenAPIInfo struct {
+	Title       string
+	Version     string
+	Description string
+
+	// ServerURL is the URL the paths are relative to, for example
+	// "https://example.org/api". The servers are not listed if it is
+	// empty.
+	ServerURL string
+}
+
+// MarshalOpenAPI returns the JSON representation of the OpenAPI document
+// generated by OpenAPI.
+func MarshalOpenAPI(schema *Schema, info OpenAPIInfo) ([]byte, error) {
+	return json.Marshal(OpenAPI(schema, info))
+}
+
+// OpenAPI returns an OpenAPI 3 document that describes the JSON:API served for
+// schema, like the one served by a Handler.
+//
+// Each type gets the endpoints for its collection, its resources, and the
+// related resources and relationships of each relationship. The query
+// parameters, the request and response bodies, and the error documents are
+// described with schemas built from the attributes and relationships of the
+// types.
+//
+// The document is returned as a map so it can be modified before being
+// marshaled.
+func OpenAPI(schema *Schema, info OpenAPIInfo) map[string]any {
+	g := openAPIGenerator{schema: schema}
+
+	infoObj := map[string]any{
+		"title":   info.Title,
+		"version": info.Version,
+	}
+
+	if info.Description != "" {
+		infoObj["description"] = info.Description
+	}
+
+	doc := map[string]any{
+		"openapi": OpenAPIVersion,
+		"info":    infoObj,
+		"paths":   g.paths(),
+		"components": map[string]any{
+			"schemas":    g.schemas(),
+			"parameters": g.parameters(),
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "Error",
+					"content":     openAPIContent(openAPIRef("schemas", "Errors")),
+				},
+			},
+		},
+	}
+
+	if info.ServerURL != "" {
+		doc["servers"] = []map[string]any{{"url": info.ServerURL}}
+	}
+
+	return doc
+}
+
+// openAPIGenerator builds the parts of an OpenAPI document that depend on a
+// schema.
+type openAPIGenerator struct {
+	schema *Schema
+}
+
+func (g openAPIGenerator) paths() map[string]any {
+	paths := map[string]any{}
+
+	for _, typ := range g.schema.Types {
+		name := typ.Name
+		idParam := []map[string]any{openAPIRef("parameters", "id")}
+
+		paths["/"+name] = map[string]any{
+			"get": g.operation(name, name+".list", "Lists the "+name+".",
+				openAPIColParams(),
+				nil,
+				"200", openAPIDocument(openAPIArray(openAPIRef("schemas", name)), true),
+			),
+			"post": g.operation(name, name+".create", "Creates a resource of type "+name+".",
+				nil,
+				openAPIRequest(openAPIRef("schemas", name+".create")),
+				"201", openAPIDocument(openAPIRef("schemas", name), true),
+			),
+		}
+
+		paths["/"+name+"/{id}"] = map[string]any{
+			"parameters": idParam,
+			"get": g.operation(name, name+".get", "Returns a resource of type "+name+".",
+				openAPIResParams(),
+				nil,
+				"200", openAPIDocument(openAPIRef("schemas", name), true),
+			),
+			"patch": g.operation(name, name+".update", "Updates a resource of type "+name+".",
+				nil,
+				openAPIRequest(openAPIRef("schemas", name)),
+				"200", openAPIDocument(openAPIRef("schemas", name), true),
+			),
+			"delete": g.operation(name, name+".delete", "Deletes a resource of type "+name+".",
+				nil,
+				nil,
+				"204", nil,
+			),
+		}
+
+		for _, relName := range sortedRelNames(typ) {
+			rel := typ.Rels[relName]
+			prefix := name + "." + rel.FromName
+
+			var (
+				relatedParams []map[string]any
+				related       map[string]any
+				linkage       map[string]any
+			)
+
+			if rel.ToOne {
+				relatedParams = openAPIResParams()
+				related = openAPINullable(openAPIRelRef(rel, ""))
+				linkage = openAPINullable(openAPIRelRef(rel, ".identifier"))
+			} else {
+				relatedParams = openAPIColParams()
+				related = openAPIArray(openAPIRelRef(rel, ""))
+				linkage = openAPIArray(openAPIRelRef(rel, ".identifier"))
+			}
+
+			paths["/"+name+"/{id}/"+rel.FromName] = map[string]any{
+				"parameters": idParam,
+				"get": g.operation(name, prefix+".get",
+					"Returns the related resources of the "+rel.FromName+" relationship.",
+					relatedParams,
+					nil,
+					"200", openAPIDocument(related, true),
+				),
+			}
+
+			relPath := map[string]any{
+				"parameters": idParam,
+				"get": g.operation(name, prefix+".relationship.get",
+					"Returns the "+rel.FromName+" relationship.",
+					nil,
+					nil,
+					"200", openAPIDocument(linkage, false),
+				),
+				"patch": g.operation(name, prefix+".relationship.update",
+					"Replaces the "+rel.FromName+" relationship.",
+					nil,
+					openAPIRequest(linkage),
+					"204", nil,
+				),
+			}
+
+			if !rel.ToOne {
+				relPath["post"] = g.operation(name, prefix+".relationship.add",
+					"Adds resources to the "+rel.FromName+" relationship.",
+					nil,
+					openAPIRequest(linkage),
+					"204", nil,
+				)
+				relPath["delete"] = g.operation(name, prefix+".relationship.remove",
+					"Removes resources from the "+rel.FromName+" relationship.",
+					nil,
+					openAPIRequest(linkage),
+					"204", nil,
+				)
+			}
+
+			paths["/"+name+"/{id}/relationships/"+rel.FromName] = relPath
+		}
+	}
+
+	return paths
+}
+
+// operation returns an operation object. body is the request body and can be
+// nil. The response for status has the schema resp, or no content if nil.
+func (g openAPIGenerator) operation(
+	tag, id, summary string,
+	params []map[string]any,
+	body map[string]any,
+	status string, resp map[string]any,
+) map[string]any {
+	success := map[string]any{"description": summary}
+	if resp != nil {
+		success["content"] = openAPIContent(resp)
+	}
+
+	op := map[string]any{
+		"tags":        []string{tag},
+		"operationId": id,
+		"summary":     summary,
+		"responses": map[string]any{
+			status:    success,
+			"default": openAPIRef("responses", "Error"),
+		},
+	}
+
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if body != nil {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content":  openAPIContent(body),
+		}
+	}
+
+	return op
+}
+
+// schemas returns the schemas of the types and of the objects common to all
+// documents. The names of the latter are capitalized so they do not collide
+// with type names.
+func (g openAPIGenerator) schemas() map[string]any {
+	schemas := map[string]any{
+		"Error":  openAPIErrorSchema(),
+		"Errors": openAPIErrorsSchema(),
+		"JSONAPI": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"version": map[string]any{"type": "string"},
+			},
+		},
+		"Links": openAPILinks(),
+		"Meta":  map[string]any{"type": "object"},
+	}
+
+	// Any resource, as found among the included resources
+	resources := make([]map[string]any, 0, len(g.schema.Types))
+
+	for _, typ := range g.schema.Types {
+		resources = append(resources, openAPIRef("schemas", typ.Name))
+	}
+
+	schemas["Resource"] = map[string]any{"oneOf": resources}
+
+	for _, typ := range g.schema.Types {
+		name := typ.Name
+
+		schemas[name] = g.resource(typ, false)
+		schemas[name+".create"] = g.resource(typ, true)
+		schemas[name+".identifier"] = map[string]any{
+			"type":     "object",
+			"required": []string{"type", "id"},
+			"properties": map[string]any{
+				"type": map[string]any{"type": "string", "enum": []string{name}},
+				"id":   map[string]any{"type": "string"},
+				"meta": openAPIRef("schemas", "Meta"),
+			},
+		}
+	}
+
+	return schemas
+}
+
+// resource returns the schema of a resource object of type typ.
+//
+// The fields are not required since sparse fieldsets and partial updates can
+// leave any of them out. If create is true, the schema describes a new
+// resource: the ID is optional, the required attributes and relationships are
+// required, and the attributes that can only be set once are not read-only.
+func (g openAPIGenerator) resource(typ Type, create bool) map[string]any {
+	attrs := map[string]any{}
+	requiredAttrs := []string{}
+
+	for _, attr := range typ.Attrs {
+		attrs[attr.Name] = openAPIAttrSchema(attr, create)
+
+		if create && attr.Required {
+			requiredAttrs = append(requiredAttrs, attr.Name)
+		}
+	}
+
+	sort.Strings(requiredAttrs)
+
+	rels := map[string]any{}
+	requiredRels := []string{}
+
+	for _, rel := range typ.Rels {
+		var linkage map[string]any
+
+		switch {
+		case rel.ToOne && rel.Required:
+			linkage = openAPIRelRef(rel, ".identifier")
+		case rel.ToOne:
+			linkage = openAPINullable(openAPIRelRef(rel, ".identifier"))
+		default:
+			linkage = openAPIArray(openAPIRelRef(rel, ".identifier"))
+			relCountSchema(linkage, rel)
+		}
+
+		relObj := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"data":  linkage,
+				"links": openAPIRef("schemas", "Links"),
+				"meta":  openAPIRef("schemas", "Meta"),
+			},
+		}
+
+		if create && rel.Required {
+			relObj["required"] = []string{"data"}
+			requiredRels = append(requiredRels, rel.FromName)
+		}
+
+		rels[rel.FromName] = relObj
+	}
+
+	required := []string{"type", "id"}
+	if create {
+		required = []string{"type"}
+	}
+
+	attributes := map[string]any{"type": "object", "properties": attrs}
+	if len(requiredAttrs) > 0 {
+		attributes["required"] = requiredAttrs
+		required = append(required, "attributes")
+	}
+
+	relationships := map[string]any{"type": "object", "properties": rels}
+	if len(requiredRels) > 0 {
+		sort.Strings(requiredRels)
+		relationships["required"] = requiredRels
+		required = append(required, "relationships")
+	}
+
+	return map[string]any{
+		"type":     "object",
+		"required": required,
+		"properties": map[string]any{
+			"type":          map[string]any{"type": "string", "enum": []string{typ.Name}},
+			"id":            map[string]any{"type": "string"},
+			"attributes":    attributes,
+			"relationships": relationships,
+			"links":         openAPIRef("schemas", "Links"),
+			"meta":          openAPIRef("schemas", "Meta"),
+		},
+	}
+}
+
+func (g openAPIGenerator) parameters() map[string]any {
+	fields := map[string]any{}
+
+	for _, typ := range g.schema.Types {
+		fields[typ.Name] = map[string]any{
+			"type":        "string",
+			"description": "Comma-separated list of fields of " + typ.Name + ".",
+		}
+	}
+
+	return map[string]any{
+		"id": map[string]any{
+			"name":     "id",
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		},
+		"fields": map[string]any{
+			"name":        "fields",
+			"in":          "query",
+			"description": "Sparse fieldsets, as in fields[type]=field1,field2.",
+			"style":       "deepObject",
+			"explode":     true,
+			"schema": map[string]any{
+				"type":       "object",
+				"properties": fields,
+			},
+		},
+		"include": openAPIQueryParam(
+			"include",
+			"Comma-separated list of relationship paths to include.",
+		),
+		"sort": openAPIQueryParam(
+			"sort",
+			"Comma-separated list of fields to sort by, prefixed by - for descending order.",
+		),
+		"filter": openAPIQueryParam(
+			"filter",
+			"Filter as a JSON object or a filter label.",
+		),
+		"page": map[string]any{
+			"name":        "page",
+			"in":          "query",
+			"description": "Pagination, as in page[size]=10&page[number]=2.",
+			"style":       "deepObject",
+			"explode":     true,
+			"schema": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"size":   map[string]any{"type": "integer", "minimum": 0},
+					"number": map[string]any{"type": "integer", "minimum": 0},
+				},
+			},
+		},
+	}
+}
+
+// openAPIAttrSchema returns the schema of the values of attr, including its
+// constraints. create is true if the schema describes a new resource.
+func openAPIAttrSchema(attr Attr, create bool) map[string]any {
+	s := attrValueSchema(attr.Type)
+	nullable := attr.Nullable && !attr.Required
+
+	attrConstraintsSchema(s, attr, nullable)
+
+	if attr.ReadOnly || (attr.WriteOnce && !create) {
+		s["readOnly"] = true
+	}
+
+	switch attr.Type {
+	case AttrTypeInt8, AttrTypeInt16, AttrTypeInt32, AttrTypeUint8, AttrTypeUint16:
+		s["format"] = "int32"
+	case AttrTypeInt, AttrTypeInt64, AttrTypeUint, AttrTypeUint32, AttrTypeUint64:
+		s["format"] = "int64"
+	case AttrTypeFloat32:
+		s["format"] = "float"
+	case AttrTypeFloat64:
+		s["format"] = "double"
+	case AttrTypeTime:
+		s["format"] = "date-time"
+	case AttrTypeBytes:
+		s["format"] = "byte"
+	}
+
+	if nullable {
+		s["nullable"] = true
+	}
+
+	return s
+}
+
+// openAPIErrorSchema returns the schema of an error object, as represented by
+// Error.
+func openAPIErrorSchema() map[string]any {
+	str := map[string]any{"type": "string"}
+
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":     str,
+			"code":   str,
+			"status": str,
+			"title":  str,
+			"detail": str,
+			"links":  openAPIRef("schemas", "Links"),
+			"source": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"pointer":   str,
+					"parameter": str,
+				},
+			},
+			"meta": openAPIRef("schemas", "Meta"),
+		},
+	}
+}
+
+// openAPIErrorsSchema returns the schema of a document that holds errors.
+func openAPIErrorsSchema() map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"errors"},
+		"properties": map[string]any{
+			"errors":  openAPIArray(openAPIRef("schemas", "Error")),
+			"jsonapi": openAPIRef("schemas", "JSONAPI"),
+			"meta":    openAPIRef("schemas", "Meta"),
+		},
+	}
+}
+
+// openAPIDocument returns the schema of a document whose primary data is
+// described by data.
+func openAPIDocument(data map[string]any, included bool) map[string]any {
+	props := map[string]any{
+		"data":    data,
+		"jsonapi": openAPIRef("schemas", "JSONAPI"),
+		"links":   openAPIRef("schemas", "Links"),
+		"meta":    openAPIRef("schemas", "Meta"),
+	}
+
+	if included {
+		props["included"] = openAPIArray(openAPIRef("schemas", "Resource"))
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"required":   []string{"data"},
+		"properties": props,
+	}
+}
+
+// openAPIRequest returns the schema of a request document whose primary data
+// is described by data.
+func openAPIRequest(data map[string]any) map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"data"},
+		"properties": map[string]any{
+			"data": data,
+			"meta": openAPIRef("schemas", "Meta"),
+		},
+	}
+}
+
+func openAPIColParams() []map[string]any {
+	return []map[string]any{
+		openAPIRef("parameters", "fields"),
+		openAPIRef("parameters", "include"),
+		openAPIRef("parameters", "sort"),
+		openAPIRef("parameters", "filter"),
+		openAPIRef("parameters", "page"),
+	}
+}
+
+func openAPIResParams() []map[string]any {
+	return []map[string]any{
+		openAPIRef("parameters", "fields"),
+		openAPIRef("parameters", "include"),
+	}
+}
+
+func openAPIQueryParam(name, desc string) map[string]any {
+	return map[string]any{
+		"name":        name,
+		"in":          "query",
+		"description": desc,
+		"schema":      map[string]any{"type": "string"},
+	}
+}
+
+func openAPIContent(schema map[string]any) map[string]any {
+	return map[string]any{
+		MediaType: map[string]any{"schema": schema},
+	}
+}
+
+func openAPILinks() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"additionalProperties": map[string]any{
+			"oneOf": []map[string]any{
+				{"type": "string"},
+				{
+					"type": "object",
+					"properties": map[string]any{
+						"href":        map[string]any{"type": "string"},
+						"rel":         map[string]any{"type": "string"},
+						"describedby": map[string]any{},
+						"title":       map[string]any{"type": "string"},
+						"type":        map[string]any{"type": "string"},
+						"hreflang": map[string]any{
+							"oneOf": []map[string]any{
+								{"type": "string"},
+								openAPIArray(map[string]any{"type": "string"}),
+							},
+						},
+						"meta": openAPIRef("schemas", "Meta"),
+					},
+				},
+			},
+		},
+	}
+}
+
+func openAPIRef(kind, name string) map[string]any {
+	return map[string]any{"$ref": "#/components/" + kind + "/" + name}
+}
+
+// openAPIRelRef returns a reference to the schema of the type targeted by rel
+// followed by suffix, or a choice between the ones of all the types targeted by
+// a polymorphic relationship.
+func openAPIRelRef(rel Rel, suffix string) map[string]any {
+	if !rel.Polymorphic() {
+		return openAPIRef("schemas", rel.ToType+suffix)
+	}
+
+	refs := make([]map[string]any, 0, len(rel.ToTypes))
+	for _, t := range rel.ToTypes {
+		refs = append(refs, openAPIRef("schemas", t+suffix))
+	}
+
+	return map[string]any{"oneOf": refs}
+}
+
+func openAPIArray(items map[string]any) map[string]any {
+	return map[string]any{"type": "array", "items": items}
+}
+
+// openAPINullable returns a schema that accepts what ref accepts and null.
+func openAPINullable(ref map[string]any) map[string]any {
+	return map[string]any{
+		"allOf":    []map[string]any{ref},
+		"nullable": true,
+	}
+}
+
+// sortedRelNames returns the names of the relationships of typ in alphabetical
+// order.
+func sortedRelNames(typ Type) []string {
+	names := make([]string, 0, len(typ.Rels))
+	for name := range typ.Rels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
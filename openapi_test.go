@@ -0,0 +1,211 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPI(t *testing.T) {
+	assert := assert.New(t)
+
+	payload, err := MarshalOpenAPI(newHandlerSchema(), OpenAPIInfo{
+		Title:       "Blog",
+		Version:     "1.0.0",
+		Description: "A blog.",
+		ServerURL:   "https://example.org/api",
+	})
+	assert.NoError(err)
+
+	// Golden file
+	path := filepath.Join("testdata", "goldenfiles", "openapi", "handler_schema.json")
+
+	if !*update {
+		// Retrieve the expected result from file
+		expected, _ := ioutil.ReadFile(path) //nolint:gosec
+
+		assert.JSONEq(string(expected), string(payload))
+	} else {
+		dst := &bytes.Buffer{}
+		err = json.Indent(dst, payload, "", "\t")
+		assert.NoError(err)
+		err = ioutil.WriteFile(path, append(dst.Bytes(), '\n'), 0600)
+		assert.NoError(err)
+	}
+}
+
+func TestOpenAPIRefs(t *testing.T) {
+	assert := assert.New(t)
+
+	payload, err := MarshalOpenAPI(newMockSchema(), OpenAPIInfo{})
+	assert.NoError(err)
+
+	doc := map[string]any{}
+	assert.NoError(json.Unmarshal(payload, &doc))
+
+	// Every reference points to a component
+	var check func(v any)
+	check = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				parts := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+				assert.Len(parts, 3, ref)
+
+				var target any = doc
+				for _, part := range parts {
+					m, _ := target.(map[string]any)
+					target = m[part]
+				}
+
+				assert.NotNil(target, ref)
+			}
+
+			for _, val := range v {
+				check(val)
+			}
+		case []any:
+			for _, val := range v {
+				check(val)
+			}
+		}
+	}
+	check(doc)
+
+	// Attributes
+	attrs := func(typ string) map[string]any {
+		v := doc["components"]
+		for _, key := range []string{"schemas", typ, "properties", "attributes", "properties"} {
+			v = v.(map[string]any)[key]
+		}
+
+		return v.(map[string]any)
+	}
+
+	assert.Equal(map[string]any{"type": "string"}, attrs("mocktypes1")["str"])
+	assert.Equal(map[string]any{
+		"type":    "integer",
+		"format":  "int32",
+		"minimum": float64(0),
+		"maximum": float64(255),
+	}, attrs("mocktypes1")["uint8"])
+	assert.Equal(map[string]any{
+		"type":     "string",
+		"format":   "date-time",
+		"nullable": true,
+	}, attrs("mocktypes2")["timeptr"])
+	assert.Equal(map[string]any{
+		"type":     "boolean",
+		"nullable": true,
+	}, attrs("mocktypes2")["boolptr"])
+}
+
+func TestOpenAPIConstraints(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+	assert.NoError(schema.AddType(MustBuildType(constrainedType{})))
+
+	payload, err := MarshalOpenAPI(schema, OpenAPIInfo{})
+	assert.NoError(err)
+
+	doc := map[string]any{}
+	assert.NoError(json.Unmarshal(payload, &doc))
+
+	resource := func(name string) map[string]any {
+		v := doc["components"].(map[string]any)["schemas"].(map[string]any)[name]
+
+		return v.(map[string]any)
+	}
+	attrs := func(name string) map[string]any {
+		v := resource(name)["properties"].(map[string]any)["attributes"]
+
+		return v.(map[string]any)
+	}
+	attr := func(name, attr string) map[string]any {
+		return attrs(name)["properties"].(map[string]any)[attr].(map[string]any)
+	}
+
+	assert.Equal(map[string]any{
+		"type":      "string",
+		"minLength": float64(1),
+		"maxLength": float64(20),
+		"pattern":   "^[A-Z]",
+	}, attr("constrained", "title"))
+	assert.Equal(map[string]any{
+		"type": "string",
+		"enum": []any{"draft", "published"},
+	}, attr("constrained", "status"))
+	assert.Equal(map[string]any{
+		"type":     "integer",
+		"format":   "int64",
+		"minimum":  float64(0),
+		"maximum":  float64(100),
+		"nullable": true,
+	}, attr("constrained", "score"))
+
+	// Read-only and write-once attributes
+	assert.Equal(true, attr("constrained", "views")["readOnly"])
+	assert.Equal(true, attr("constrained.create", "views")["readOnly"])
+	assert.Equal(true, attr("constrained", "slug")["readOnly"])
+	assert.NotContains(attr("constrained.create", "slug"), "readOnly")
+
+	// Required attributes
+	assert.NotContains(attrs("constrained"), "required")
+	assert.Equal([]any{"title"}, attrs("constrained.create")["required"])
+	assert.Equal([]any{"type", "attributes"}, resource("constrained.create")["required"])
+}
+
+func TestOpenAPIRelConstraints(t *testing.T) {
+	assert := assert.New(t)
+
+	payload, err := MarshalOpenAPI(newConstrainedPostSchema(), OpenAPIInfo{})
+	assert.NoError(err)
+
+	doc := map[string]any{}
+	assert.NoError(json.Unmarshal(payload, &doc))
+
+	rels := func(name string) map[string]any {
+		v := doc["components"].(map[string]any)["schemas"].(map[string]any)[name]
+
+		return v.(map[string]any)["properties"].(map[string]any)["relationships"].(map[string]any)
+	}
+	rel := func(name, rel string) map[string]any {
+		return rels(name)["properties"].(map[string]any)[rel].(map[string]any)
+	}
+	data := func(name, r string) map[string]any {
+		return rel(name, r)["properties"].(map[string]any)["data"].(map[string]any)
+	}
+
+	// Bounds
+	assert.Equal(map[string]any{
+		"type":     "array",
+		"items":    map[string]any{"$ref": "#/components/schemas/people.identifier"},
+		"minItems": float64(1),
+	}, data("posts", "editors"))
+	assert.Equal(map[string]any{
+		"type":     "array",
+		"items":    map[string]any{"$ref": "#/components/schemas/tags.identifier"},
+		"maxItems": float64(2),
+	}, data("posts", "tags"))
+
+	// Required relationships
+	assert.Equal(
+		map[string]any{"$ref": "#/components/schemas/people.identifier"},
+		data("posts", "author"),
+	)
+	assert.NotContains(rels("posts"), "required")
+	assert.Equal([]any{"author", "editors"}, rels("posts.create")["required"])
+	assert.Equal([]any{"data"}, rel("posts.create", "author")["required"])
+	assert.NotContains(rel("posts.create", "tags"), "required")
+
+	create := doc["components"].(map[string]any)["schemas"].(map[string]any)["posts.create"]
+	assert.Equal([]any{"type", "relationships"}, create.(map[string]any)["required"])
+}
@@ -11,6 +11,19 @@ import (
 // If validation is not expected, it is recommended to simply build a SimpleURL
 // object with NewSimpleURL.
 func NewParams(schema *Schema, su SimpleURL, resType string) (*Params, error) {
+	return newParams(schema, su, []string{resType})
+}
+
+// newParams is like NewParams, but the resources can be of any of the types of
+// resTypes, like the ones a polymorphic relationship points to.
+//
+// The resources can only be sorted by ID if there are many types.
+func newParams(schema *Schema, su SimpleURL, resTypes []string) (*Params, error) {
+	resType := ""
+	if len(resTypes) == 1 {
+		resType = resTypes[0]
+	}
+
 	params := &Params{
 		Fields:       map[string][]string{},
 		Attrs:        map[string][]Attr{},
@@ -35,59 +48,37 @@ func NewParams(schema *Schema, su SimpleURL, resType string) (*Params, error) {
 		}
 	}
 
-	// Check inclusions
-	for i := 0; i < len(incs); i++ {
-		words := strings.Split(incs[i], ".")
+	// Build params.Include
+	//
+	// The inclusions that cannot be followed are ignored.
+	params.Include = [][]Rel{}
 
-		incRel := Rel{ToType: resType}
+	for _, inc := range incs {
+		for _, path := range includePaths(schema, resTypes, strings.Split(inc, ".")) {
+			params.Include = append(params.Include, path)
 
-		for _, word := range words {
-			if typ := schema.GetType(incRel.ToType); typ.Name != "" {
-				var ok bool
-				if incRel, ok = typ.Rels[word]; ok {
-					params.Fields[incRel.ToType] = []string{}
-				} else {
-					incs = append(incs[:i], incs[i+1:]...)
-					break
+			for _, rel := range path {
+				for _, t := range rel.TargetTypes() {
+					params.Fields[t] = []string{}
 				}
 			}
 		}
 	}
 
-	// Build params.Include
-	params.Include = make([][]Rel, len(incs))
-
-	for i := range incs {
-		words := strings.Split(incs[i], ".")
-
-		params.Include[i] = make([]Rel, len(words))
-
-		var incRel Rel
-
-		for w := range words {
-			if w == 0 {
-				typ := schema.GetType(resType)
-				incRel = typ.Rels[words[0]]
-			}
-
-			params.Include[i][w] = incRel
-
-			if w < len(words)-1 {
-				typ := schema.GetType(incRel.ToType)
-				incRel = typ.Rels[words[w+1]]
-			}
+	for _, t := range resTypes {
+		if t != "" {
+			params.Fields[t] = []string{}
 		}
 	}
 
-	if resType != "" {
-		params.Fields[resType] = []string{}
-	}
-
 	// Fields
 	for t, fields := range su.Fields {
-		if t != resType {
+		if t != resType || t == "" {
 			if typ := schema.GetType(t); typ.Name == "" {
-				return nil, NewErrUnknownTypeInURL(t)
+				e := NewErrUnknownTypeInURL(t)
+				e.Source["parameter"] = "fields[" + t + "]"
+
+				return nil, e
 			}
 		}
 
@@ -245,6 +236,33 @@ func NewParams(schema *Schema, su SimpleURL, resType string) (*Params, error) {
 	return params, nil
 }
 
+// includePaths returns the paths of relationships that follow the names of
+// words, starting from the types of types.
+//
+// A polymorphic relationship leads to a path for each of the types it points
+// to that has the next relationship. No path is returned if words cannot be
+// followed.
+func includePaths(schema *Schema, types, words []string) [][]Rel {
+	if len(words) == 0 {
+		return [][]Rel{{}}
+	}
+
+	paths := [][]Rel{}
+
+	for _, t := range types {
+		rel, ok := schema.GetType(t).Rels[words[0]]
+		if !ok {
+			continue
+		}
+
+		for _, path := range includePaths(schema, rel.TargetTypes(), words[1:]) {
+			paths = append(paths, append([]Rel{rel}, path...))
+		}
+	}
+
+	return paths
+}
+
 // A Params object represents all the query parameters from the URL.
 type Params struct {
 	// Fields
@@ -0,0 +1,158 @@
+package jsonapi
+
+import "sort"
+
+// Polymorphic returns true if the relationship can point to resources of
+// different types (see Rel.ToTypes).
+func (r Rel) Polymorphic() bool {
+	return len(r.ToTypes) > 0
+}
+
+// TargetTypes returns the types of the resources the relationship can point
+// to.
+func (r Rel) TargetTypes() []string {
+	if r.Polymorphic() {
+		return r.ToTypes
+	}
+
+	return []string{r.ToType}
+}
+
+// Targets returns true if the relationship can point to resources of type typ.
+func (r Rel) Targets(typ string) bool {
+	for _, t := range r.TargetTypes() {
+		if t == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// relZeroValue returns the value of the relationship rel when it is empty.
+func relZeroValue(rel Rel) any {
+	return relValue(rel, nil)
+}
+
+// relIdentifiers returns the identifiers of the resources that the
+// relationship rel of res points to.
+func relIdentifiers(res Resource, rel Rel) Identifiers {
+	switch v := res.Get(rel.FromName).(type) {
+	case string:
+		if v != "" {
+			return Identifiers{{Type: rel.ToType, ID: v}}
+		}
+	case []string:
+		return NewIdentifiers(rel.ToType, v)
+	case Identifier:
+		if v.ID != "" {
+			return Identifiers{v}
+		}
+	case Identifiers:
+		return append(Identifiers{}, v...)
+	}
+
+	return Identifiers{}
+}
+
+// relValue returns idens as a value of the relationship rel: an ID or a slice
+// of IDs, or an Identifier or Identifiers if rel is polymorphic.
+func relValue(rel Rel, idens Identifiers) any {
+	switch {
+	case rel.Polymorphic() && rel.ToOne:
+		if len(idens) == 0 {
+			return Identifier{}
+		}
+
+		return idens[0]
+	case rel.Polymorphic():
+		return append(Identifiers{}, idens...)
+	case rel.ToOne:
+		if len(idens) == 0 {
+			return ""
+		}
+
+		return idens[0].ID
+	default:
+		return idens.IDs()
+	}
+}
+
+// setRel sets the relationship rel of the resource of type typ identified by
+// id to idens in store.
+//
+// Polymorphic relationships are set with Store.Update since the other methods
+// of Store only deal with IDs.
+func setRel(store Store, typ, id string, rel Rel, idens Identifiers) error {
+	switch {
+	case rel.Polymorphic():
+		res := &SoftResource{Type: &Type{Name: typ}}
+		res.AddRel(rel)
+		res.SetID(id)
+		res.Set(rel.FromName, relValue(rel, idens))
+
+		return store.Update(res)
+	case rel.ToOne:
+		toID := ""
+		if len(idens) > 0 {
+			toID = idens[0].ID
+		}
+
+		return store.UpdateToOne(id, rel.FromName, toID)
+	default:
+		return store.UpdateToMany(id, rel.FromName, idens.IDs())
+	}
+}
+
+// addIdentifiers returns the union of idens and newIdens.
+func addIdentifiers(idens, newIdens Identifiers) Identifiers {
+	result := append(Identifiers{}, idens...)
+
+	for _, iden := range newIdens {
+		if !hasIdentifier(result, iden) {
+			result = append(result, iden)
+		}
+	}
+
+	return result
+}
+
+// removeIdentifiers returns the identifiers of idens that are not in oldIdens.
+func removeIdentifiers(idens, oldIdens Identifiers) Identifiers {
+	result := make(Identifiers, 0, len(idens))
+
+	for _, iden := range idens {
+		if !hasIdentifier(oldIdens, iden) {
+			result = append(result, iden)
+		}
+	}
+
+	return result
+}
+
+// hasIdentifier returns true if idens holds an identifier with the same type
+// and ID as iden.
+func hasIdentifier(idens Identifiers, iden Identifier) bool {
+	for _, iden2 := range idens {
+		if iden.Type == iden2.Type && iden.ID == iden2.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortIdentifiers returns a copy of idens sorted by type and ID.
+func sortIdentifiers(idens Identifiers) Identifiers {
+	sorted := append(Identifiers{}, idens...)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Type != sorted[j].Type {
+			return sorted[i].Type < sorted[j].Type
+		}
+
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	return sorted
+}
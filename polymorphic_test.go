@@ -0,0 +1,545 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolymorphicRelTags(t *testing.T) {
+	assert := assert.New(t)
+
+	typ, err := BuildType(polyComment{})
+	assert.NoError(err)
+
+	assert.Equal(Rel{
+		FromType: "comments",
+		FromName: "commentable",
+		ToOne:    true,
+		ToTypes:  []string{"articles", "videos"},
+		ToName:   "comments",
+	}, typ.Rels["commentable"])
+	assert.True(typ.Rels["commentable"].Polymorphic())
+	assert.True(typ.Rels["commentable"].Targets("videos"))
+	assert.False(typ.Rels["commentable"].Targets("people"))
+	assert.Equal("comments_commentable_articles|videos_comments", typ.Rels["commentable"].String())
+
+	typ, err = BuildType(polyPerson{})
+	assert.NoError(err)
+	assert.False(typ.Rels["favorites"].ToOne)
+	assert.Equal([]string{"articles", "videos"}, typ.Rels["favorites"].TargetTypes())
+
+	// Invalid field type
+	err = Check(struct {
+		ID          string `json:"id" api:"comments"`
+		Commentable string `json:"commentable" api:"rel,articles|videos"`
+	}{})
+	assert.EqualError(
+		err,
+		`jsonapi: polymorphic relationship "Commentable" of type "comments" `+
+			`is not Identifier or Identifiers`,
+	)
+
+	// Both a type and a set of types
+	err = (&Type{Name: "comments"}).AddRel(Rel{
+		FromName: "commentable",
+		ToType:   "articles",
+		ToTypes:  []string{"videos"},
+	})
+	assert.EqualError(err, `jsonapi: relationship "commentable" has both ToType and ToTypes`)
+
+	// Wrapper
+	comment := &polyComment{
+		ID:          "c1",
+		Commentable: Identifier{Type: "videos", ID: "v1"},
+	}
+	wrap := Wrap(comment)
+
+	assert.Equal(Identifier{Type: "videos", ID: "v1"}, wrap.Get("commentable"))
+
+	wrap.Set("commentable", Identifier{Type: "articles", ID: "a1"})
+	assert.Equal(Identifier{Type: "articles", ID: "a1"}, comment.Commentable)
+
+	person := &polyPerson{Favorites: Identifiers{{Type: "videos", ID: "v1"}}}
+	cp := Wrap(person).Copy()
+	person.Favorites[0].ID = "v2"
+	assert.Equal(Identifiers{{Type: "videos", ID: "v1"}}, cp.Get("favorites"))
+}
+
+func TestPolymorphicSoftResource(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newPolySchema()
+	typ := schema.GetType("people")
+	sr := &SoftResource{Type: &typ}
+
+	assert.Equal(Identifiers{}, sr.Get("favorites"))
+
+	// Values that are not typed are ignored.
+	sr.Set("favorites", []string{"a1"})
+	assert.Equal(Identifiers{}, sr.Get("favorites"))
+
+	favs := Identifiers{{Type: "articles", ID: "a1"}, {Type: "videos", ID: "v1"}}
+	sr.Set("favorites", favs)
+	assert.Equal(favs, sr.Get("favorites"))
+
+	cp := sr.Copy()
+	favs[0].ID = "a2"
+	assert.Equal("a1", cp.Get("favorites").(Identifiers)[0].ID)
+}
+
+func TestPolymorphicMarshaling(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newPolySchema()
+
+	payload := `{
+		"data": {
+			"type": "people",
+			"id": "p1",
+			"attributes": {"name": "Alice"},
+			"relationships": {
+				"favorites": {
+					"data": [
+						{"type": "videos", "id": "v1"},
+						{"type": "articles", "id": "a1"}
+					]
+				}
+			}
+		}
+	}`
+
+	doc, err := UnmarshalDocument([]byte(payload), schema)
+	assert.NoError(err)
+
+	res := doc.Data.(Resource)
+	assert.Equal(Identifiers{
+		{Type: "videos", ID: "v1"},
+		{Type: "articles", ID: "a1"},
+	}, res.Get("favorites"))
+
+	// The identifiers are sorted by type and ID.
+	pl := MarshalResource(
+		res, "", []string{"name", "favorites"}, map[string][]string{"people": {"favorites"}},
+	)
+	assert.Contains(
+		string(pl),
+		`"data":[{"id":"a1","type":"articles"},{"id":"v1","type":"videos"}]`,
+	)
+
+	res2, err := UnmarshalResource(pl, schema)
+	assert.NoError(err)
+	assert.Equal(Identifiers{
+		{Type: "articles", ID: "a1"},
+		{Type: "videos", ID: "v1"},
+	}, res2.Get("favorites"))
+
+	// The types are compared.
+	res3, _ := UnmarshalResource(pl, schema)
+	assert.True(Equal(res2, res3))
+
+	res3.Set("favorites", Identifiers{{Type: "articles", ID: "a1"}, {Type: "articles", ID: "v1"}})
+	assert.False(Equal(res2, res3))
+
+	// Types that are not targeted
+	payload = `{
+		"type": "comments",
+		"id": "c1",
+		"relationships": {"commentable": {"data": {"type": "people", "id": "p1"}}}
+	}`
+
+	_, err = UnmarshalResource([]byte(payload), schema)
+	if e, ok := err.(Error); assert.True(ok) {
+		assert.Equal("/relationships/commentable/data", e.Source["pointer"])
+	}
+}
+
+func TestPolymorphicJSONSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newPolySchema()
+	typ := schema.GetType("comments")
+
+	errs := typ.Validate([]byte(`{"data": {
+		"type": "comments",
+		"relationships": {"commentable": {"data": {"type": "videos", "id": "v1"}}}
+	}}`))
+	assert.Empty(errs)
+
+	errs = typ.Validate([]byte(`{"data": {
+		"type": "comments",
+		"relationships": {"commentable": {"data": {"type": "people", "id": "p1"}}}
+	}}`))
+	if assert.Len(errs, 1) {
+		assert.Equal("/data/relationships/commentable/data/type", errs[0].Source["pointer"])
+		assert.Equal(`The value must be one of ["articles","videos"].`, errs[0].Detail)
+	}
+
+	// OpenAPI
+	pl, err := MarshalOpenAPI(schema, OpenAPIInfo{Title: "Comments", Version: "1.0"})
+	assert.NoError(err)
+	assert.Contains(
+		string(pl),
+		`"oneOf":[{"$ref":"#/components/schemas/articles.identifier"},`+
+			`{"$ref":"#/components/schemas/videos.identifier"}]`,
+	)
+}
+
+func TestPolymorphicSchemaCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newPolySchema()
+	assert.Empty(schema.Check())
+
+	// Missing type
+	schema = &Schema{}
+	for _, v := range []any{polyComment{}, polyArticle{}, polyPerson{}} {
+		_ = schema.AddType(MustBuildType(v))
+	}
+
+	assert.Contains(
+		errorMessages(schema.Check()),
+		`jsonapi: type "videos" of relationship "commentable" of type "comments" does not exist`,
+	)
+
+	// Missing inverse
+	schema = newPolySchema()
+	schema.RemoveRel("videos", "comments")
+
+	assert.Equal([]string{
+		`jsonapi: relationship "commentable" of type "comments" ` +
+			`and its inverse in type "videos" do not point each other`,
+	}, errorMessages(schema.Check()))
+
+	// Duplicate type
+	schema = newPolySchema()
+	schema.RemoveRel("comments", "commentable")
+	_ = schema.AddRel("comments", Rel{
+		FromName: "commentable",
+		ToOne:    true,
+		ToTypes:  []string{"articles", "articles"},
+	})
+
+	assert.Contains(
+		errorMessages(schema.Check()),
+		`jsonapi: type "articles" is listed twice in relationship "commentable" of type "comments"`,
+	)
+}
+
+func TestPolymorphicHandler(t *testing.T) {
+	schema := newPolySchema()
+
+	stores := map[string]Store{}
+	for i := range schema.Types {
+		stores[schema.Types[i].Name] = NewMemoryStore(schema.Types[i])
+	}
+
+	handler := NewHandler(schema, stores)
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		status   int
+		contains string
+	}{
+		{
+			name:   "create person",
+			method: "POST",
+			url:    "/people",
+			body:   `{"data":{"type":"people","id":"p1","attributes":{"name":"Alice"}}}`,
+			status: 201,
+		}, {
+			name:   "create article",
+			method: "POST",
+			url:    "/articles",
+			body: `{"data":{"type":"articles","id":"a1","attributes":{"title":"Go"},` +
+				`"relationships":{"author":{"data":{"type":"people","id":"p1"}}}}}`,
+			status: 201,
+		}, {
+			name:   "create video",
+			method: "POST",
+			url:    "/videos",
+			body: `{"data":{"type":"videos","id":"v1",` +
+				`"relationships":{"author":{"data":{"type":"people","id":"p1"}}}}}`,
+			status: 201,
+		}, {
+			name:   "create comment on article",
+			method: "POST",
+			url:    "/comments",
+			body: `{"data":{"type":"comments","id":"c1",` +
+				`"relationships":{"commentable":{"data":{"type":"articles","id":"a1"}}}}}`,
+			status: 201,
+		}, {
+			name:   "create comment on video",
+			method: "POST",
+			url:    "/comments",
+			body: `{"data":{"type":"comments","id":"c2",` +
+				`"relationships":{"commentable":{"data":{"type":"videos","id":"v1"}}}}}`,
+			status: 201,
+		}, {
+			name:   "create comment on person",
+			method: "POST",
+			url:    "/comments",
+			body: `{"data":{"type":"comments","id":"c3",` +
+				`"relationships":{"commentable":{"data":{"type":"people","id":"p1"}}}}}`,
+			status: 400,
+		}, {
+			name:     "get inverse relationship",
+			method:   "GET",
+			url:      "/videos/v1/relationships/comments",
+			status:   200,
+			contains: `"data":[{"id":"c2","type":"comments"}]`,
+		}, {
+			name:   "move comment to video",
+			method: "PATCH",
+			url:    "/comments/c1/relationships/commentable",
+			body:   `{"data":{"type":"videos","id":"v1"}}`,
+			status: 204,
+		}, {
+			name:   "move comment to person",
+			method: "PATCH",
+			url:    "/comments/c1/relationships/commentable",
+			body:   `{"data":{"type":"people","id":"p1"}}`,
+			status: 409,
+		}, {
+			name:     "get relationship",
+			method:   "GET",
+			url:      "/comments/c1/relationships/commentable",
+			status:   200,
+			contains: `"data":{"id":"v1","type":"videos"}`,
+		}, {
+			name:   "add favorites",
+			method: "POST",
+			url:    "/people/p1/relationships/favorites",
+			body:   `{"data":[{"type":"videos","id":"v1"},{"type":"articles","id":"a1"}]}`,
+			status: 204,
+		}, {
+			name:   "remove favorite",
+			method: "DELETE",
+			url:    "/people/p1/relationships/favorites",
+			body:   `{"data":[{"type":"videos","id":"v1"}]}`,
+			status: 204,
+		},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		req := httptest.NewRequest(test.method, test.url, bytes.NewBufferString(test.body))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(test.status, rec.Code, test.name)
+
+		assert.Contains(rec.Body.String(), test.contains, test.name)
+	}
+
+	assert := assert.New(t)
+
+	// The inverse relationships follow the polymorphic relationship.
+	article, _ := stores["articles"].Resource("a1")
+	assert.Equal([]string{}, article.Get("comments"))
+
+	video, _ := stores["videos"].Resource("v1")
+	assert.Equal([]string{"c2", "c1"}, video.Get("comments"))
+
+	person, _ := stores["people"].Resource("p1")
+	assert.Equal(Identifiers{{Type: "articles", ID: "a1"}}, person.Get("favorites"))
+
+	// Related resources of different types
+	req := httptest.NewRequest("GET", "/people/p1/favorites", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(200, rec.Code)
+	assert.Contains(rec.Body.String(), `"id":"a1","links":{"self":"/articles/a1"}`)
+
+	// Atomic operations
+	ops, err := UnmarshalOperations([]byte(`{
+		"atomic:operations": [{
+			"op": "update",
+			"ref": {"type": "comments", "id": "c2", "relationship": "commentable"},
+			"data": {"type": "articles", "id": "a1"}
+		}, {
+			"op": "add",
+			"ref": {"type": "people", "id": "p1", "relationship": "favorites"},
+			"data": [{"type": "videos", "id": "v1"}]
+		}]
+	}`), schema)
+	assert.NoError(err)
+
+	_, err = handler.ExecuteOperations(ops)
+	assert.NoError(err)
+
+	article, _ = stores["articles"].Resource("a1")
+	assert.Equal([]string{"c2"}, article.Get("comments"))
+
+	person, _ = stores["people"].Resource("p1")
+	assert.Equal(Identifiers{
+		{Type: "articles", ID: "a1"},
+		{Type: "videos", ID: "v1"},
+	}, person.Get("favorites"))
+
+	// Inclusions through each possible type
+	req = httptest.NewRequest("GET", "/comments?include=commentable.author", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(200, rec.Code)
+
+	doc, err := UnmarshalDocument(rec.Body.Bytes(), schema)
+	if assert.NoError(err) {
+		included := []string{}
+		for _, res := range doc.Included {
+			included = append(included, res.GetType().Name+"/"+res.Get("id").(string))
+		}
+
+		assert.ElementsMatch([]string{"articles/a1", "videos/v1", "people/p1"}, included)
+	}
+}
+
+func TestPolymorphicClient(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newPolySchema()
+
+	stores := map[string]Store{}
+	for i := range schema.Types {
+		stores[schema.Types[i].Name] = NewMemoryStore(schema.Types[i])
+	}
+
+	server := httptest.NewServer(NewHandler(schema, stores))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", schema)
+
+	for _, name := range []string{"articles", "videos"} {
+		typ := schema.GetType(name)
+		res := typ.New()
+		res.Set("id", name[:1]+"1")
+
+		_, err := client.Create(res)
+		assert.NoError(err)
+	}
+
+	comments := schema.GetType("comments")
+	comment := comments.New()
+	comment.Set("id", "c1")
+	comment.Set("commentable", Identifier{Type: "videos", ID: "v1"})
+
+	_, err := client.Create(comment)
+	assert.NoError(err)
+
+	people := schema.GetType("people")
+	person := people.New()
+	person.Set("id", "p1")
+	person.Set("favorites", Identifiers{
+		{Type: "videos", ID: "v1"},
+		{Type: "articles", ID: "a1"},
+	})
+
+	_, err = client.Create(person)
+	assert.NoError(err)
+
+	// To-one
+	idens, err := client.GetRelationshipIdentifiers("comments", "c1", "commentable")
+	assert.NoError(err)
+	assert.Equal(Identifiers{{Type: "videos", ID: "v1"}}, idens)
+
+	ids, err := client.GetRelationship("comments", "c1", "commentable")
+	assert.NoError(err)
+	assert.Equal([]string{"v1"}, ids)
+
+	// To-many
+	idens, err = client.GetRelationshipIdentifiers("people", "p1", "favorites")
+	assert.NoError(err)
+	assert.Equal(Identifiers{
+		{Type: "articles", ID: "a1"},
+		{Type: "videos", ID: "v1"},
+	}, idens)
+
+	_, err = client.GetRelationshipIdentifiers("people", "p1", "unknown")
+	assert.EqualError(err, `jsonapi: type "people" has no relationship "unknown"`)
+}
+
+func TestPolymorphicIncludePaths(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newPolySchema()
+
+	url, err := NewURLFromRaw(schema, "/comments?include=commentable.author")
+	assert.NoError(err)
+
+	comments := schema.GetType("comments")
+	articles := schema.GetType("articles")
+	videos := schema.GetType("videos")
+
+	assert.Equal([][]Rel{
+		{comments.Rels["commentable"], articles.Rels["author"]},
+		{comments.Rels["commentable"], videos.Rels["author"]},
+	}, url.Params.Include)
+
+	for _, typ := range []string{"comments", "articles", "videos", "people"} {
+		assert.Contains(url.Params.Fields, typ)
+	}
+
+	// Only the types that have the relationship are followed.
+	url, err = NewURLFromRaw(schema, "/comments?include=commentable.title")
+	assert.NoError(err)
+	assert.Equal([][]Rel{}, url.Params.Include)
+
+	// Related resources
+	url, err = NewURLFromRaw(schema, "/people/p1/favorites?include=author")
+	assert.NoError(err)
+	assert.Equal("", url.ResType)
+	assert.Len(url.Params.Include, 2)
+}
+
+func newPolySchema() *Schema {
+	schema := &Schema{}
+
+	for _, v := range []any{polyComment{}, polyArticle{}, polyVideo{}, polyPerson{}} {
+		_ = schema.AddType(MustBuildType(v))
+	}
+
+	return schema
+}
+
+func errorMessages(errs []error) []string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return msgs
+}
+
+type polyComment struct {
+	ID          string     `json:"id" api:"comments"`
+	Body        string     `json:"body" api:"attr"`
+	Commentable Identifier `json:"commentable" api:"rel,articles|videos,comments"`
+}
+
+type polyArticle struct {
+	ID       string   `json:"id" api:"articles"`
+	Title    string   `json:"title" api:"attr"`
+	Author   string   `json:"author" api:"rel,people"`
+	Comments []string `json:"comments" api:"rel,comments,commentable"`
+}
+
+type polyVideo struct {
+	ID       string   `json:"id" api:"videos"`
+	Author   string   `json:"author" api:"rel,people"`
+	Comments []string `json:"comments" api:"rel,comments,commentable"`
+}
+
+type polyPerson struct {
+	ID        string      `json:"id" api:"people"`
+	Name      string      `json:"name" api:"attr"`
+	Favorites Identifiers `json:"favorites" api:"rel,articles|videos"`
+}
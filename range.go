@@ -16,7 +16,33 @@ import (
 //
 // A non-nil Collection is always returned, but it can be empty.
 func Range(c Collection, ids []string, filter *Filter, sort []string, size uint, num uint) Collection {
-	col := sortedResources{}
+	col := sortedResources{
+		col: filterResources(c, ids, filter),
+	}
+
+	// Sort
+	col.Sort(sort)
+
+	// Pagination
+	var page Resources
+
+	skip := int(num * size) //nolint:gosec
+
+	if skip >= len(col.col) {
+		col = sortedResources{}
+	} else {
+		for i := skip; i < len(col.col) && i < skip+int(size); i++ { //nolint:gosec
+			page = append(page, col.col[i])
+		}
+	}
+
+	return &page
+}
+
+// filterResources returns the resources of c whose IDs are in ids (unless ids
+// is empty) and that are allowed by filter (unless filter is nil).
+func filterResources(c Collection, ids []string, filter *Filter) Resources {
+	col := Resources{}
 
 	// Filter IDs
 	if len(ids) > 0 {
@@ -24,45 +50,29 @@ func Range(c Collection, ids []string, filter *Filter, sort []string, size uint,
 			for _, id := range ids {
 				res := c.At(i)
 				if res.Get("id").(string) == id {
-					col.col = append(col.col, res)
+					col = append(col, res)
 				}
 			}
 		}
 	} else {
 		for i := 0; i < c.Len(); i++ {
-			col.col = append(col.col, c.At(i))
+			col = append(col, c.At(i))
 		}
 	}
 
 	// Filter
 	if filter != nil {
 		i := 0
-		for i < col.Len() {
-			if !filter.IsAllowed(col.col[i]) {
-				col.col = append(col.col[:i], col.col[i+1:]...)
+		for i < len(col) {
+			if !filter.IsAllowed(col[i]) {
+				col = append(col[:i], col[i+1:]...)
 			} else {
 				i++
 			}
 		}
 	}
 
-	// Sort
-	col.Sort(sort)
-
-	// Pagination
-	var page Resources
-
-	skip := int(num * size) //nolint:gosec
-
-	if skip >= len(col.col) {
-		col = sortedResources{}
-	} else {
-		for i := skip; i < len(col.col) && i < skip+int(size); i++ { //nolint:gosec
-			page = append(page, col.col[i])
-		}
-	}
-
-	return &page
+	return col
 }
 
 // sortedResources is an internal struct for sorting Collections with the Range
@@ -212,6 +222,41 @@ func (s sortedResources) Less(i, j int) bool {
 			}
 
 			return len(v) < len(s2) != inverse
+		case float32:
+			v2 := v2.(float32)
+			if v == v2 {
+				continue
+			}
+
+			return v < v2 != inverse
+		case float64:
+			v2 := v2.(float64)
+			if v == v2 {
+				continue
+			}
+
+			return v < v2 != inverse
+		case Decimal:
+			v2 := v2.(Decimal)
+			if v.Cmp(v2) == 0 {
+				continue
+			}
+
+			return v.Cmp(v2) < 0 != inverse
+		case []string:
+			v2 := v2.([]string)
+			if compareStrings(v, v2) == 0 {
+				continue
+			}
+
+			return compareStrings(v, v2) < 0 != inverse
+		case []float64:
+			v2 := v2.([]float64)
+			if compareNumbers(v, v2) == 0 {
+				continue
+			}
+
+			return compareNumbers(v, v2) < 0 != inverse
 		case *string:
 			v2 := v2.(*string)
 			if v == v2 {
@@ -440,8 +485,160 @@ func (s sortedResources) Less(i, j int) bool {
 			}
 
 			return v.Before(*v2) != inverse
+		case *float32:
+			v2 := v2.(*float32)
+			if v == v2 {
+				continue
+			}
+
+			if v == nil {
+				return !inverse
+			}
+
+			if v2 == nil {
+				return inverse
+			}
+
+			if *v == *v2 {
+				continue
+			}
+
+			return *v < *v2 != inverse
+		case *float64:
+			v2 := v2.(*float64)
+			if v == v2 {
+				continue
+			}
+
+			if v == nil {
+				return !inverse
+			}
+
+			if v2 == nil {
+				return inverse
+			}
+
+			if *v == *v2 {
+				continue
+			}
+
+			return *v < *v2 != inverse
+		case *Decimal:
+			v2 := v2.(*Decimal)
+			if v == v2 {
+				continue
+			}
+
+			if v == nil {
+				return !inverse
+			}
+
+			if v2 == nil {
+				return inverse
+			}
+
+			if v.Cmp(*v2) == 0 {
+				continue
+			}
+
+			return v.Cmp(*v2) < 0 != inverse
+		case *[]string:
+			v2 := v2.(*[]string)
+			if v == v2 {
+				continue
+			}
+
+			if v == nil {
+				return !inverse
+			}
+
+			if v2 == nil {
+				return inverse
+			}
+
+			if compareStrings(*v, *v2) == 0 {
+				continue
+			}
+
+			return compareStrings(*v, *v2) < 0 != inverse
+		case *[]float64:
+			v2 := v2.(*[]float64)
+			if v == v2 {
+				continue
+			}
+
+			if v == nil {
+				return !inverse
+			}
+
+			if v2 == nil {
+				return inverse
+			}
+
+			if compareNumbers(*v, *v2) == 0 {
+				continue
+			}
+
+			return compareNumbers(*v, *v2) < 0 != inverse
+		default:
+			// Registered attribute types
+			ct, cv, null, ok := customValue(v)
+			_, cv2, null2, ok2 := customValue(v2)
+
+			switch {
+			case !ok && !ok2, null && null2:
+				continue
+			case null:
+				return !inverse
+			case null2:
+				return inverse
+			}
+
+			c := ct.codec.CompareAttr(cv, cv2)
+			if c == 0 {
+				continue
+			}
+
+			return c < 0 != inverse
 		}
 	}
 
 	return false
 }
+
+// compareStrings compares s and s2 element by element, then by length. It
+// returns -1, 0, or +1 like strings.Compare.
+func compareStrings(s, s2 []string) int {
+	for i := 0; i < len(s) && i < len(s2); i++ {
+		if c := strings.Compare(s[i], s2[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareLen(len(s), len(s2))
+}
+
+// compareNumbers is like compareStrings for numbers.
+func compareNumbers(s, s2 []float64) int {
+	for i := 0; i < len(s) && i < len(s2); i++ {
+		switch {
+		case s[i] < s2[i]:
+			return -1
+		case s[i] > s2[i]:
+			return 1
+		}
+	}
+
+	return compareLen(len(s), len(s2))
+}
+
+func compareLen(l, l2 int) int {
+	switch {
+	case l < l2:
+		return -1
+	case l > l2:
+		return 1
+	default:
+		return 0
+	}
+}
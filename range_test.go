@@ -404,3 +404,80 @@ func TestSortResources(t *testing.T) {
 		_ = Range(col1, nil, nil, []string{"samename", "id"}, 100, 0)
 	})
 }
+
+func TestSortResourcesNumbersAndArrays(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		vals        []any
+		expectedIDs []string
+	}{
+		{
+			vals:        []any{float32(2), float32(-1), float32(1.5)},
+			expectedIDs: []string{"id1", "id2", "id0"},
+		}, {
+			vals:        []any{2.5, 2.25, -3.0},
+			expectedIDs: []string{"id2", "id1", "id0"},
+		}, {
+			vals:        []any{Decimal("10"), Decimal("9.99"), Decimal("1e2")},
+			expectedIDs: []string{"id1", "id0", "id2"},
+		}, {
+			vals:        []any{[]string{"b"}, []string{"a", "c"}, []string{"a"}},
+			expectedIDs: []string{"id2", "id1", "id0"},
+		}, {
+			vals:        []any{[]float64{1, 2}, []float64{1}, []float64{0, 5}},
+			expectedIDs: []string{"id2", "id1", "id0"},
+		}, {
+			vals:        []any{ptr(1.5), nilptr("float64"), ptr(-1.0)},
+			expectedIDs: []string{"id1", "id2", "id0"},
+		}, {
+			vals:        []any{ptr(float32(1)), ptr(float32(0)), nilptr("float32")},
+			expectedIDs: []string{"id2", "id1", "id0"},
+		}, {
+			vals:        []any{ptr(Decimal("1.0")), nilptr("Decimal"), ptr(Decimal("1"))},
+			expectedIDs: []string{"id1", "id0", "id2"},
+		}, {
+			vals:        []any{ptr([]string{"b"}), ptr([]string{"a"}), nilptr("[]string")},
+			expectedIDs: []string{"id2", "id1", "id0"},
+		}, {
+			vals:        []any{ptr([]float64{2}), nilptr("[]float64"), ptr([]float64{1})},
+			expectedIDs: []string{"id1", "id2", "id0"},
+		}, {
+			// Objects are not sorted.
+			vals: []any{
+				map[string]any{"a": "c"},
+				map[string]any{"a": "b"},
+				map[string]any{},
+			},
+			expectedIDs: []string{"id0", "id1", "id2"},
+		},
+	}
+
+	for _, test := range tests {
+		typ := &Type{Name: "type"}
+		ti, null := GetAttrType(fmt.Sprintf("%T", test.vals[0]))
+		_ = typ.AddAttr(Attr{
+			Name:     "attr",
+			Type:     ti,
+			Nullable: null,
+		})
+
+		col := &Resources{}
+
+		for i, val := range test.vals {
+			sr := &SoftResource{Type: typ}
+			sr.SetID("id" + strconv.Itoa(i))
+			sr.Set("attr", val)
+			col.Add(sr)
+		}
+
+		page := Range(col, nil, nil, []string{"attr", "id"}, 10, 0)
+
+		ids := []string{}
+		for i := 0; i < page.Len(); i++ {
+			ids = append(ids, page.At(i).Get("id").(string))
+		}
+
+		assert.Equal(test.expectedIDs, ids, fmt.Sprintf("%T", test.vals[0]))
+	}
+}
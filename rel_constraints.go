@@ -0,0 +1,137 @@
+package jsonapi
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CheckIDs checks ids, the IDs of the resources targeted by the relationship,
+// against the constraints of the relationship that are about those resources:
+// MinCount, MaxCount and IDPattern. It returns an Error (see
+// NewErrConstraintViolationInBody) for the first constraint that is not
+// satisfied, or nil.
+func (r Rel) CheckIDs(ids []string) error {
+	return r.checkLinkage(len(ids), ids)
+}
+
+// checkLinkage checks n, the number of related resources, and ids, the IDs of
+// those that are not identified by a local ID.
+func (r Rel) checkLinkage(n int, ids []string) error {
+	if !r.ToOne {
+		if r.MinCount > 0 && n < r.MinCount {
+			return NewErrConstraintViolationInBody(r.FromName, "min",
+				fmt.Sprintf("%q must have at least %d resources.", r.FromName, r.MinCount))
+		}
+
+		if r.MaxCount > 0 && n > r.MaxCount {
+			return NewErrConstraintViolationInBody(r.FromName, "max",
+				fmt.Sprintf("%q must have at most %d resources.", r.FromName, r.MaxCount))
+		}
+	}
+
+	if r.IDPattern != "" {
+		for _, id := range ids {
+			// An invalid pattern is rejected by Type.AddRel and BuildType,
+			// so the error is ignored. No ID matches such a pattern.
+			if matched, _ := regexp.MatchString(r.IDPattern, id); !matched {
+				return NewErrConstraintViolationInBody(r.FromName, "pattern", fmt.Sprintf(
+					"ID %q of %q must match the pattern %q.", id, r.FromName, r.IDPattern,
+				))
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkWrite checks that the relationship can be set by a client to n related
+// resources, where ids are the IDs of those that are not identified by a local
+// ID. create is true if the resource is being created.
+func (r Rel) checkWrite(n int, ids []string, create bool) error {
+	switch {
+	case r.Immutable && !create:
+		return NewErrConstraintViolationInBody(r.FromName, "immutable",
+			fmt.Sprintf("%q cannot be changed once the resource is created.", r.FromName))
+	case r.Required && n == 0:
+		return NewErrConstraintViolationInBody(r.FromName, "required",
+			fmt.Sprintf("%q is required.", r.FromName))
+	}
+
+	return r.checkLinkage(n, ids)
+}
+
+// parseRelTag reads the api tag of a relationship, like
+// "rel,tags,articles,max=10", and the pattern tag, and sets the corresponding
+// fields of rel. The name of the inverse relationship can be left empty, like
+// in "rel,tags,,max=10". The types of a polymorphic relationship are separated
+// by "|", like in "rel,articles|videos". structName is the name of the struct
+// that holds sf and is only used in errors.
+func parseRelTag(rel *Rel, sf reflect.StructField, structName string) error {
+	tag := strings.Split(sf.Tag.Get("api"), ",")
+	if len(tag) < 2 {
+		return fmt.Errorf(
+			"jsonapi: api tag of relationship %q of struct %q is invalid",
+			sf.Name,
+			structName,
+		)
+	}
+
+	if strings.Contains(tag[1], "|") {
+		rel.ToTypes = strings.Split(tag[1], "|")
+	} else {
+		rel.ToType = tag[1]
+	}
+
+	if len(tag) > 2 {
+		rel.ToName = tag[2]
+	}
+
+	for i := 3; i < len(tag); i++ {
+		var (
+			opt       = tag[i]
+			name, val = opt, ""
+			err       error
+		)
+
+		if j := strings.Index(opt, "="); j >= 0 {
+			name, val = opt[:j], opt[j+1:]
+		}
+
+		switch name {
+		case "required":
+			rel.Required = true
+		case "immutable":
+			rel.Immutable = true
+		case "min":
+			rel.MinCount, err = strconv.Atoi(val)
+		case "max":
+			rel.MaxCount, err = strconv.Atoi(val)
+		default:
+			err = fmt.Errorf("unknown option %q", opt)
+		}
+
+		if err != nil {
+			return fmt.Errorf(
+				"jsonapi: api tag of relationship %q of struct %q is invalid: %s",
+				sf.Name,
+				structName,
+				err,
+			)
+		}
+	}
+
+	rel.IDPattern = sf.Tag.Get("pattern")
+
+	if _, err := regexp.Compile(rel.IDPattern); err != nil {
+		return fmt.Errorf(
+			"jsonapi: pattern of relationship %q of struct %q is invalid",
+			sf.Name,
+			structName,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelCheckIDs(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		rel                Rel
+		ids                []string
+		expectedConstraint string
+	}{
+		{
+			rel: Rel{MinCount: 1, MaxCount: 2},
+			ids: []string{"a", "b"},
+		}, {
+			rel:                Rel{MinCount: 1, MaxCount: 2},
+			ids:                []string{},
+			expectedConstraint: "min",
+		}, {
+			rel:                Rel{MaxCount: 2},
+			ids:                []string{"a", "b", "c"},
+			expectedConstraint: "max",
+		}, {
+			rel: Rel{ToOne: true, MinCount: 2},
+			ids: []string{"a"},
+		}, {
+			rel: Rel{IDPattern: "^[0-9]+$"},
+			ids: []string{"1", "23"},
+		}, {
+			rel:                Rel{IDPattern: "^[0-9]+$"},
+			ids:                []string{"1", "a"},
+			expectedConstraint: "pattern",
+		}, {
+			rel: Rel{Required: true, Immutable: true},
+			ids: []string{},
+		},
+	}
+
+	for i, test := range tests {
+		test.rel.FromName = "rel"
+		err := test.rel.CheckIDs(test.ids)
+
+		if test.expectedConstraint == "" {
+			assert.NoError(err, i)
+		} else if e, ok := err.(Error); assert.True(ok, i) {
+			assert.Equal("422", e.Status, i)
+			assert.Equal(test.expectedConstraint, e.Meta["constraint"], i)
+			assert.Equal("rel", e.Meta["field"], i)
+		}
+	}
+
+	// Details
+	assert.EqualError(
+		Rel{FromName: "rel", MaxCount: 1}.CheckIDs([]string{"a", "b"}),
+		`422 Unprocessable Entity: "rel" must have at most 1 resources.`,
+	)
+	assert.EqualError(
+		Rel{FromName: "rel", IDPattern: "^a"}.CheckIDs([]string{"b"}),
+		`422 Unprocessable Entity: ID "b" of "rel" must match the pattern "^a".`,
+	)
+}
+
+func TestRelConstraintsTags(t *testing.T) {
+	assert := assert.New(t)
+
+	typ, err := BuildType(constrainedPost{})
+	assert.NoError(err)
+
+	assert.Equal(Rel{
+		FromType:  "posts",
+		FromName:  "author",
+		ToOne:     true,
+		ToType:    "people",
+		ToName:    "posts",
+		Required:  true,
+		Immutable: true,
+	}, typ.Rels["author"])
+	assert.Equal(Rel{
+		FromType:  "posts",
+		FromName:  "tags",
+		ToType:    "tags",
+		MaxCount:  2,
+		IDPattern: "^t[0-9]+$",
+	}, typ.Rels["tags"])
+	assert.Equal(Rel{
+		FromType: "posts",
+		FromName: "editors",
+		ToType:   "people",
+		Required: true,
+		MinCount: 1,
+	}, typ.Rels["editors"])
+
+	// Wrapper
+	assert.Equal(typ.Rels["tags"], Wrap(&constrainedPost{}).Rel("tags"))
+
+	// Invalid tags
+	err = Check(struct {
+		ID   string   `json:"id" api:"invalid"`
+		Tags []string `json:"tags" api:"rel,tags,,max=abc"`
+	}{})
+	assert.EqualError(
+		err,
+		`jsonapi: api tag of relationship "Tags" of struct "" is invalid: `+
+			`strconv.Atoi: parsing "abc": invalid syntax`,
+	)
+
+	err = Check(struct {
+		ID   string   `json:"id" api:"invalid"`
+		Tags []string `json:"tags" api:"rel,tags,,unknown"`
+	}{})
+	assert.EqualError(
+		err,
+		`jsonapi: api tag of relationship "Tags" of struct "" is invalid: `+
+			`unknown option "unknown"`,
+	)
+
+	err = Check(struct {
+		ID   string   `json:"id" api:"invalid"`
+		Tags []string `json:"tags" api:"rel,tags" pattern:"("`
+	}{})
+	assert.EqualError(err, `jsonapi: pattern of relationship "Tags" of struct "" is invalid`)
+
+	// Invalid pattern
+	err = (&Type{Name: "type"}).AddRel(Rel{FromName: "rel", ToType: "type", IDPattern: "("})
+	assert.EqualError(err, `jsonapi: pattern of relationship "rel" is invalid`)
+}
+
+func TestUnmarshalResourceRelConstraints(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newConstrainedPostSchema()
+
+	// New resources
+	tests := []struct {
+		payload         string
+		expectedPointer string
+		expectedMeta    string
+	}{
+		{
+			payload: `{
+				"author": {"data": {"type": "people", "id": "p1"}},
+				"editors": {"data": [{"type": "people", "id": "p1"}]},
+				"tags": {"data": [{"type": "tags", "id": "t1"}, {"type": "tags", "lid": "new"}]}
+			}`,
+		}, {
+			payload: `{
+				"editors": {"data": [{"type": "people", "id": "p1"}]}
+			}`,
+			expectedPointer: "/relationships/author",
+			expectedMeta:    "required",
+		}, {
+			payload: `{
+				"author": {"data": null},
+				"editors": {"data": [{"type": "people", "id": "p1"}]}
+			}`,
+			expectedPointer: "/relationships/author/data",
+			expectedMeta:    "required",
+		}, {
+			payload: `{
+				"author": {"data": {"type": "people", "id": "p1"}},
+				"editors": {"data": []}
+			}`,
+			expectedPointer: "/relationships/editors/data",
+			expectedMeta:    "required",
+		}, {
+			payload: `{
+				"author": {"data": {"type": "people", "id": "p1"}},
+				"editors": {"data": [{"type": "people", "id": "p1"}]},
+				"tags": {"data": [
+					{"type": "tags", "id": "t1"},
+					{"type": "tags", "id": "t2"},
+					{"type": "tags", "id": "t3"}
+				]}
+			}`,
+			expectedPointer: "/relationships/tags/data",
+			expectedMeta:    "max",
+		}, {
+			payload: `{
+				"author": {"data": {"type": "people", "id": "p1"}},
+				"editors": {"data": [{"type": "people", "id": "p1"}]},
+				"tags": {"data": [{"type": "tags", "id": "go"}]}
+			}`,
+			expectedPointer: "/relationships/tags/data",
+			expectedMeta:    "pattern",
+		},
+	}
+
+	for _, test := range tests {
+		payload := `{"type": "posts", "id": "p1", "relationships": ` + test.payload + `}`
+
+		_, err := UnmarshalResource([]byte(payload), schema)
+
+		if test.expectedMeta == "" {
+			assert.NoError(err, test.payload)
+			continue
+		}
+
+		if e, ok := err.(Error); assert.True(ok, test.payload) {
+			assert.Equal("422", e.Status, test.payload)
+			assert.Equal(test.expectedPointer, e.Source["pointer"], test.payload)
+			assert.Equal(test.expectedMeta, e.Meta["constraint"], test.payload)
+		}
+	}
+
+	// All the problems
+	payload := `{
+		"type": "posts",
+		"relationships": {"tags": {"data": [{"type": "tags", "id": "go"}]}}
+	}`
+
+	_, err := UnmarshalResourceAll([]byte(payload), schema)
+	if errs, ok := err.(Errors); assert.True(ok) && assert.Len(errs, 3) {
+		assert.Equal("/relationships/tags/data", errs[0].Source["pointer"])
+		assert.Equal("/relationships/author", errs[1].Source["pointer"])
+		assert.Equal("/relationships/editors", errs[2].Source["pointer"])
+	}
+
+	// Updates
+	payload = `{
+		"type": "posts",
+		"id": "p1",
+		"relationships": {"tags": {"data": [{"type": "tags", "id": "t1"}]}}
+	}`
+	res, err := UnmarshalPartialResource([]byte(payload), schema)
+	assert.NoError(err)
+	assert.Equal([]string{"t1"}, res.Get("tags"))
+
+	payload = `{
+		"type": "posts",
+		"id": "p1",
+		"relationships": {"author": {"data": {"type": "people", "id": "p2"}}}
+	}`
+	_, err = UnmarshalPartialResource([]byte(payload), schema)
+	assert.EqualError(
+		err,
+		`422 Unprocessable Entity: "author" cannot be changed once the resource is created.`,
+	)
+
+	payload = `{
+		"type": "posts",
+		"id": "p1",
+		"relationships": {"editors": {"data": []}}
+	}`
+	_, err = UnmarshalPartialResource([]byte(payload), schema)
+	assert.Equal("required", err.(Error).Meta["constraint"])
+
+	// Documents are only checked against the constraints on the related
+	// resources, since they are not always sent by clients.
+	payload = `{"data": {
+		"type": "posts",
+		"id": "p1",
+		"relationships": {"author": {"data": null}}
+	}}`
+	_, err = UnmarshalDocument([]byte(payload), schema)
+	assert.NoError(err)
+
+	payload = `{"data": {
+		"type": "posts",
+		"id": "p1",
+		"relationships": {"tags": {"data": [{"type": "tags", "id": "go"}]}}
+	}}`
+	_, err = UnmarshalDocument([]byte(payload), schema)
+	assert.EqualError(
+		err,
+		`422 Unprocessable Entity: ID "go" of "tags" must match the pattern "^t[0-9]+$".`,
+	)
+}
+
+func newConstrainedPostSchema() *Schema {
+	schema := &Schema{}
+
+	for _, v := range []any{constrainedPost{}, constrainedPerson{}, constrainedTag{}} {
+		_ = schema.AddType(MustBuildType(v))
+	}
+
+	return schema
+}
+
+type constrainedPost struct {
+	ID      string   `json:"id" api:"posts"`
+	Title   string   `json:"title" api:"attr"`
+	Author  string   `json:"author" api:"rel,people,posts,required,immutable"`
+	Editors []string `json:"editors" api:"rel,people,,required,min=1"`
+	Tags    []string `json:"tags" api:"rel,tags,,max=2" pattern:"^t[0-9]+$"`
+}
+
+type constrainedPerson struct {
+	ID    string   `json:"id" api:"people"`
+	Name  string   `json:"name" api:"attr"`
+	Posts []string `json:"posts" api:"rel,posts,author"`
+}
+
+type constrainedTag struct {
+	ID string `json:"id" api:"tags"`
+}
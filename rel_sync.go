@@ -0,0 +1,301 @@
+package jsonapi
+
+// A RelSync keeps both sides of two-way relationships consistent.
+//
+// When a resource is created, updated, or deleted, the inverse relationships of
+// the resources it points to (or used to point to) are updated. For example, if
+// an article's author is set to a user, the article is added to the articles
+// of that user, and removed from the articles of the previous author.
+//
+// The relationships and their inverses are taken from Schema. Collections maps
+// type names to the collections that hold the resources to update. Resources
+// are modified in place through Set, which means the collections must return
+// the stored resources themselves (like SoftCollection does).
+//
+// Resources that cannot be found in the collections are ignored.
+type RelSync struct {
+	Schema      *Schema
+	Collections map[string]Collection
+}
+
+// Created updates the inverse relationships after res was created.
+func (s *RelSync) Created(res Resource) {
+	_ = s.sync().resource(nil, res)
+}
+
+// Updated updates the inverse relationships after old was updated to res.
+//
+// old must be a copy of the resource made before the update. Only the
+// relationships defined in res are considered, which means res can be a
+// partial resource.
+func (s *RelSync) Updated(old, res Resource) {
+	_ = s.sync().resource(old, res)
+}
+
+// Deleted updates the inverse relationships after res was deleted.
+func (s *RelSync) Deleted(res Resource) {
+	_ = s.sync().resource(res, nil)
+}
+
+func (s *RelSync) sync() relSync {
+	return relSync{
+		schema: s.Schema,
+		target: colRelTarget(s.Collections),
+	}
+}
+
+// relSync is the engine behind RelSync. It applies the changes to a relTarget,
+// which allows it to work with collections as well as stores.
+type relSync struct {
+	schema *Schema
+	target relTarget
+}
+
+// resource updates the inverse relationships after old became res.
+//
+// old is nil when res was created, and res is nil when old was deleted.
+func (s relSync) resource(old, res Resource) error {
+	ref := res
+	if ref == nil {
+		ref = old
+	}
+
+	typ := ref.GetType().Name
+	id := ref.Get("id").(string)
+
+	for _, rel := range ref.Rels() {
+		var oldIdens, newIdens Identifiers
+
+		if old != nil {
+			if _, ok := old.Rels()[rel.FromName]; ok {
+				oldIdens = relIdentifiers(old, rel)
+			}
+		}
+
+		if res != nil {
+			newIdens = relIdentifiers(res, rel)
+		}
+
+		err := s.rel(typ, id, rel.FromName, oldIdens, newIdens)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rel updates the inverse relationships after the relationship named name of
+// the resource identified by typ and id went from oldIdens to newIdens.
+//
+// The inverse of a polymorphic relationship is found in the type of each
+// related resource.
+func (s relSync) rel(typ, id, name string, oldIdens, newIdens Identifiers) error {
+	rel, ok := s.schema.GetType(typ).Rels[name]
+	if !ok || rel.ToName == "" {
+		return nil
+	}
+
+	self := Identifiers{{Type: typ, ID: id}}
+
+	// Resources that are now related
+	for _, to := range removeIdentifiers(newIdens, oldIdens) {
+		inv, ok := s.inverse(rel, to.Type)
+		if !ok {
+			continue
+		}
+
+		prev, found, err := s.target.get(to.Type, to.ID, inv)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			continue
+		}
+
+		// If the inverse relationship is a to-one, the resource
+		// that it used to point to loses its link to the resource.
+		if inv.ToOne {
+			for _, p := range prev {
+				if hasIdentifier(self, p) {
+					continue
+				}
+
+				prevRel, ok := s.schema.GetType(p.Type).Rels[inv.ToName]
+				if !ok {
+					continue
+				}
+
+				err = s.target.remove(p.Type, p.ID, prevRel, Identifiers{to})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err = s.target.add(to.Type, to.ID, inv, self)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Resources that are not related anymore
+	for _, to := range removeIdentifiers(oldIdens, newIdens) {
+		inv, ok := s.inverse(rel, to.Type)
+		if !ok {
+			continue
+		}
+
+		_, found, err := s.target.get(to.Type, to.ID, inv)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			continue
+		}
+
+		err = s.target.remove(to.Type, to.ID, inv, self)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inverse returns the inverse of relationship rel found in type typ.
+//
+// false is returned if there is no such relationship.
+func (s relSync) inverse(rel Rel, typ string) (Rel, bool) {
+	inv, ok := s.schema.GetType(typ).Rels[rel.ToName]
+
+	return inv, ok
+}
+
+// A relTarget gives relSync access to the relationships of the resources.
+type relTarget interface {
+	// get returns the identifiers of relationship rel of the resource
+	// identified by typ and id, and whether the resource was found.
+	get(typ, id string, rel Rel) (Identifiers, bool, error)
+
+	// add adds idens to the relationship. A to-one relationship is
+	// set to the first identifier.
+	add(typ, id string, rel Rel, idens Identifiers) error
+
+	// remove removes idens from the relationship. A to-one
+	// relationship is emptied if its value is one of the identifiers.
+	remove(typ, id string, rel Rel, idens Identifiers) error
+}
+
+// colRelTarget is a relTarget that modifies the resources of collections.
+type colRelTarget map[string]Collection
+
+func (t colRelTarget) get(typ, id string, rel Rel) (Identifiers, bool, error) {
+	if res := t.resource(typ, id); res != nil {
+		return relIdentifiers(res, rel), true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (t colRelTarget) add(typ, id string, rel Rel, idens Identifiers) error {
+	if res := t.resource(typ, id); res != nil && len(idens) > 0 {
+		if rel.ToOne {
+			res.Set(rel.FromName, relValue(rel, idens[:1]))
+		} else {
+			res.Set(rel.FromName, relValue(rel, addIdentifiers(relIdentifiers(res, rel), idens)))
+		}
+	}
+
+	return nil
+}
+
+func (t colRelTarget) remove(typ, id string, rel Rel, idens Identifiers) error {
+	if res := t.resource(typ, id); res != nil {
+		current := relIdentifiers(res, rel)
+
+		if rel.ToOne {
+			if len(removeIdentifiers(current, idens)) == 0 {
+				res.Set(rel.FromName, relZeroValue(rel))
+			}
+		} else {
+			res.Set(rel.FromName, relValue(rel, removeIdentifiers(current, idens)))
+		}
+	}
+
+	return nil
+}
+
+func (t colRelTarget) resource(typ, id string) Resource {
+	if col, ok := t[typ]; ok {
+		for i := 0; i < col.Len(); i++ {
+			if res := col.At(i); res.Get("id").(string) == id {
+				return res
+			}
+		}
+	}
+
+	return nil
+}
+
+// storeRelTarget is a relTarget that modifies the resources of stores.
+//
+// Polymorphic relationships are set as a whole since Store.AddToMany and
+// Store.RemoveFromMany only deal with IDs.
+type storeRelTarget map[string]Store
+
+func (t storeRelTarget) get(typ, id string, rel Rel) (Identifiers, bool, error) {
+	store, ok := t[typ]
+	if !ok {
+		return nil, false, nil
+	}
+
+	res, err := store.Resource(id)
+	if err != nil || res == nil {
+		return nil, false, err
+	}
+
+	return relIdentifiers(res, rel), true, nil
+}
+
+func (t storeRelTarget) add(typ, id string, rel Rel, idens Identifiers) error {
+	if len(idens) == 0 {
+		return nil
+	}
+
+	switch {
+	case rel.ToOne:
+		return setRel(t[typ], typ, id, rel, idens[:1])
+	case rel.Polymorphic():
+		current, found, err := t.get(typ, id, rel)
+		if err != nil || !found {
+			return err
+		}
+
+		return setRel(t[typ], typ, id, rel, addIdentifiers(current, idens))
+	default:
+		return t[typ].AddToMany(id, rel.FromName, idens.IDs())
+	}
+}
+
+func (t storeRelTarget) remove(typ, id string, rel Rel, idens Identifiers) error {
+	if !rel.ToOne && !rel.Polymorphic() {
+		return t[typ].RemoveFromMany(id, rel.FromName, idens.IDs())
+	}
+
+	current, found, err := t.get(typ, id, rel)
+	if err != nil || !found {
+		return err
+	}
+
+	switch {
+	case !rel.ToOne:
+		return setRel(t[typ], typ, id, rel, removeIdentifiers(current, idens))
+	case len(current) > 0 && len(removeIdentifiers(current, idens)) == 0:
+		return setRel(t[typ], typ, id, rel, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mfcochauxlaberge/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelSync(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+	for _, name := range []string{"users", "passports", "articles", "tags"} {
+		_ = schema.AddType(Type{Name: name})
+	}
+
+	// One-to-one
+	_ = schema.AddTwoWayRel(Rel{
+		FromType: "users",
+		FromName: "passport",
+		ToOne:    true,
+		ToType:   "passports",
+		ToName:   "owner",
+		FromOne:  true,
+	})
+	// One-to-many
+	_ = schema.AddTwoWayRel(Rel{
+		FromType: "articles",
+		FromName: "author",
+		ToOne:    true,
+		ToType:   "users",
+		ToName:   "articles",
+		FromOne:  false,
+	})
+	// Many-to-many
+	_ = schema.AddTwoWayRel(Rel{
+		FromType: "articles",
+		FromName: "tags",
+		ToOne:    false,
+		ToType:   "tags",
+		ToName:   "articles",
+		FromOne:  false,
+	})
+
+	cols := map[string]Collection{}
+	for i := range schema.Types {
+		cols[schema.Types[i].Name] = &SoftCollection{Type: &schema.Types[i]}
+	}
+
+	add := func(typ, id string) *SoftResource {
+		rtyp := schema.GetType(typ).Copy()
+		res := &SoftResource{Type: &rtyp}
+		res.SetID(id)
+		cols[typ].(*SoftCollection).Add(res)
+
+		return cols[typ].(*SoftCollection).Resource(id, nil).(*SoftResource)
+	}
+	get := func(typ, id, field string) any {
+		return cols[typ].(*SoftCollection).Resource(id, nil).Get(field)
+	}
+
+	sync := &RelSync{
+		Schema:      schema,
+		Collections: cols,
+	}
+
+	u1 := add("users", "u1")
+	u2 := add("users", "u2")
+	_ = add("passports", "p1")
+	_ = add("tags", "t1")
+	_ = add("tags", "t2")
+
+	// Create
+	a1 := add("articles", "a1")
+	a1.Set("author", "u1")
+	a1.Set("tags", []string{"t1", "t2"})
+	sync.Created(a1)
+
+	assert.Equal([]string{"a1"}, get("users", "u1", "articles"))
+	assert.Equal([]string{"a1"}, get("tags", "t1", "articles"))
+	assert.Equal([]string{"a1"}, get("tags", "t2", "articles"))
+
+	// Update one-to-many and many-to-many
+	old := a1.Copy()
+	a1.Set("author", "u2")
+	a1.Set("tags", []string{"t2"})
+	sync.Updated(old, a1)
+
+	assert.Equal([]string{}, get("users", "u1", "articles"))
+	assert.Equal([]string{"a1"}, get("users", "u2", "articles"))
+	assert.Equal([]string{}, get("tags", "t1", "articles"))
+	assert.Equal([]string{"a1"}, get("tags", "t2", "articles"))
+
+	// Update the to-many side of a one-to-many
+	old = u1.Copy()
+	u1.Set("articles", []string{"a1"})
+	sync.Updated(old, u1)
+
+	assert.Equal("u1", get("articles", "a1", "author"))
+	assert.Equal([]string{}, get("users", "u2", "articles"))
+
+	// Update one-to-one
+	old = u1.Copy()
+	u1.Set("passport", "p1")
+	sync.Updated(old, u1)
+
+	assert.Equal("u1", get("passports", "p1", "owner"))
+
+	old = u2.Copy()
+	u2.Set("passport", "p1")
+	sync.Updated(old, u2)
+
+	assert.Equal("u2", get("passports", "p1", "owner"))
+	assert.Equal("", get("users", "u1", "passport"))
+
+	// Partial update
+	partial := &SoftResource{Type: &Type{Name: "users"}}
+	partial.SetID("u2")
+	sync.Updated(u2.Copy(), partial)
+
+	assert.Equal("u2", get("passports", "p1", "owner"))
+
+	// Unknown resources are ignored
+	old = u2.Copy()
+	u2.Set("passport", "p2")
+	sync.Updated(old, u2)
+
+	assert.Equal("", get("passports", "p1", "owner"))
+
+	// Delete
+	cols["articles"].(*SoftCollection).Remove("a1")
+	sync.Deleted(a1)
+
+	assert.Equal([]string{}, get("users", "u1", "articles"))
+	assert.Equal([]string{}, get("tags", "t2", "articles"))
+}
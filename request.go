@@ -9,7 +9,16 @@ import (
 //
 // schema can be nil, in which case no checks will be done to insure that the
 // request respects a specific schema.
+//
+// The media type is negotiated by a Negotiator that supports no extensions and
+// no profiles, so a request that requires an extension is rejected.
 func NewRequest(r *http.Request, schema *Schema) (*Request, error) {
+	return (&Negotiator{}).NewRequest(r, schema)
+}
+
+// newRequest builds and returns a *Request based on r and schema, without
+// looking at the media type.
+func newRequest(r *http.Request, schema *Schema) (*Request, error) {
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return nil, err
@@ -48,4 +57,9 @@ type Request struct {
 	Method string
 	URL    *URL
 	Doc    *Document
+
+	// Ext and Profile hold the URIs of the extensions and profiles that
+	// apply to the request, as negotiated by a Negotiator.
+	Ext     []string
+	Profile []string
 }
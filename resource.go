@@ -28,13 +28,22 @@ func MarshalResource(r Resource, prepath string, fields []string, relData map[st
 	mapPl["id"] = r.Get("id").(string)
 	mapPl["type"] = r.GetType().Name
 
+	// Local ID
+	if l, ok := r.(LIDHolder); ok && l.GetLID() != "" {
+		mapPl["lid"] = l.GetLID()
+
+		if mapPl["id"] == "" {
+			delete(mapPl, "id")
+		}
+	}
+
 	// Attributes
 	attrs := map[string]any{}
 
 	for _, attr := range r.Attrs() {
 		for _, field := range fields {
 			if field == attr.Name {
-				attrs[attr.Name] = r.Get(attr.Name)
+				attrs[attr.Name] = marshalableAttr(attr, r.Get(attr.Name))
 				break
 			}
 		}
@@ -60,18 +69,22 @@ func MarshalResource(r Resource, prepath string, fields []string, relData map[st
 		if include {
 			var raw json.RawMessage
 
-			if rel.ToOne {
-				s := map[string]map[string]string{
-					"links": buildRelationshipLinks(r, prepath, rel.FromName),
-				}
+			s := map[string]any{
+				"links": buildRelationshipLinks(r, prepath, rel.FromName),
+			}
+
+			if rm, ok := r.(RelMetaHolder); ok && len(rm.RelMeta(rel.FromName)) > 0 {
+				s["meta"] = rm.RelMeta(rel.FromName)
+			}
 
+			if rel.ToOne {
 				for _, n := range relData[r.GetType().Name] {
 					if n == rel.FromName {
-						id := r.Get(rel.FromName).(string)
-						if id != "" {
+						idens := relIdentifiers(r, rel)
+						if len(idens) > 0 {
 							s["data"] = map[string]string{
-								"id":   r.Get(rel.FromName).(string),
-								"type": rel.ToType,
+								"id":   idens[0].ID,
+								"type": idens[0].Type,
 							}
 						} else {
 							s["data"] = nil
@@ -84,21 +97,16 @@ func MarshalResource(r Resource, prepath string, fields []string, relData map[st
 				raw, _ = json.Marshal(s)
 				rels[rel.FromName] = &raw
 			} else {
-				s := map[string]any{
-					"links": buildRelationshipLinks(r, prepath, rel.FromName),
-				}
-
 				for _, n := range relData[r.GetType().Name] {
 					if n == rel.FromName {
-						ids := r.Get(rel.FromName).([]string)
-						sort.Strings(ids)
+						idens := sortIdentifiers(relIdentifiers(r, rel))
 
-						data := make([]map[string]string, 0, len(ids))
+						data := make([]map[string]string, 0, len(idens))
 
-						for _, id := range ids {
+						for _, iden := range idens {
 							data = append(data, map[string]string{
-								"id":   id,
-								"type": rel.ToType,
+								"id":   iden.ID,
+								"type": iden.Type,
 							})
 						}
 
@@ -119,10 +127,18 @@ func MarshalResource(r Resource, prepath string, fields []string, relData map[st
 	}
 
 	// Links
-	mapPl["links"] = map[string]string{
-		"self": buildSelfLink(r, prepath),
+	links := map[string]Link{
+		"self": {HRef: buildSelfLink(r, prepath)},
 	}
 
+	if l, ok := r.(LinkHolder); ok {
+		for name, link := range l.Links() {
+			links[name] = link
+		}
+	}
+
+	mapPl["links"] = links
+
 	// Meta
 	if m, ok := r.(MetaHolder); ok {
 		if len(m.Meta()) > 0 {
@@ -136,77 +152,93 @@ func MarshalResource(r Resource, prepath string, fields []string, relData map[st
 	return pl
 }
 
-// UnmarshalResource unmarshals a JSON-encoded payload into a Resource.
-func UnmarshalResource(data []byte, schema *Schema) (Resource, error) {
-	var rske resourceSkeleton
-
-	err := json.Unmarshal(data, &rske)
-	if err != nil {
-		return nil, NewErrBadRequest(
-			"Invalid JSON",
-			"The provided JSON body could not be read.",
-		)
+// resourceObject returns a map that represents res as a resource object sent by
+// a client: all the fields are included, the relationships only hold their
+// resource linkage, and there are no links.
+//
+// linkage returns the resource identifier object of a related resource.
+func resourceObject(res Resource, linkage func(typ, id string) map[string]string) map[string]any {
+	m := map[string]any{
+		"type": res.GetType().Name,
 	}
 
-	typ := schema.GetType(rske.Type)
-	res := typ.New()
-
-	res.Set("id", rske.ID)
+	if id := res.Get("id").(string); id != "" {
+		m["id"] = id
+	}
 
-	for a, v := range rske.Attributes {
-		if attr, ok := typ.Attrs[a]; ok {
-			val, err := attr.UnmarshalToType(v)
-			if err != nil {
-				return nil, err
-			}
+	if l, ok := res.(LIDHolder); ok && l.GetLID() != "" {
+		m["lid"] = l.GetLID()
+	}
 
-			res.Set(attr.Name, val)
-		} else {
-			return nil, NewErrUnknownFieldInBody(typ.Name, a)
-		}
+	attrs := map[string]any{}
+	for _, attr := range res.Attrs() {
+		attrs[attr.Name] = marshalableAttr(attr, res.Get(attr.Name))
 	}
 
-	for r, v := range rske.Relationships {
-		if rel, ok := typ.Rels[r]; ok {
-			if len(v.Data) > 0 {
-				if rel.ToOne {
-					var iden Identifier
+	if len(attrs) > 0 {
+		m["attributes"] = attrs
+	}
 
-					err = json.Unmarshal(v.Data, &iden)
-					res.Set(rel.FromName, iden.ID)
-				} else {
-					var idens Identifiers
+	rels := map[string]any{}
 
-					err = json.Unmarshal(v.Data, &idens)
+	for _, rel := range res.Rels() {
+		idens := relIdentifiers(res, rel)
 
-					ids := make([]string, len(idens))
+		if rel.ToOne {
+			var data any
+			if len(idens) > 0 {
+				data = linkage(idens[0].Type, idens[0].ID)
+			}
 
-					for i := range idens {
-						ids[i] = idens[i].ID
-					}
+			rels[rel.FromName] = map[string]any{"data": data}
+		} else {
+			data := make([]map[string]string, 0, len(idens))
 
-					res.Set(rel.FromName, ids)
-				}
+			for _, iden := range idens {
+				data = append(data, linkage(iden.Type, iden.ID))
 			}
 
-			if err != nil {
-				return nil, NewErrInvalidFieldValueInBody(
-					rel.FromName,
-					string(v.Data),
-					typ.Name,
-				)
-			}
-		} else {
-			return nil, NewErrUnknownFieldInBody(typ.Name, r)
+			rels[rel.FromName] = map[string]any{"data": data}
 		}
 	}
 
-	// Meta
-	if m, ok := res.(MetaHolder); ok {
-		m.SetMeta(rske.Meta)
+	if len(rels) > 0 {
+		m["relationships"] = rels
+	}
+
+	if mh, ok := res.(MetaHolder); ok && len(mh.Meta()) > 0 {
+		m["meta"] = mh.Meta()
+	}
+
+	return m
+}
+
+// UnmarshalResource unmarshals a JSON-encoded payload into a Resource.
+//
+// The source pointer of a returned Error is relative to the resource object,
+// like "/attributes/title".
+func UnmarshalResource(data []byte, schema *Schema) (Resource, error) {
+	u := &unmarshaler{schema: schema, constraints: true}
+
+	return u.resource(data, "", false)
+}
+
+// UnmarshalResourceAll is like UnmarshalResource, but it does not stop at the
+// first invalid member.
+//
+// If the payload contains problems, the returned error is of type Errors and
+// holds one Error per problem, each with a source pointer relative to the
+// resource object (like "/attributes/title"). An unknown type is also reported
+// as an error.
+func UnmarshalResourceAll(data []byte, schema *Schema) (Resource, error) {
+	u := &unmarshaler{schema: schema, collect: true, constraints: true}
+
+	res, err := u.resource(data, "", false)
+	if err != nil {
+		return nil, err
 	}
 
-	return res, nil
+	return res, u.err()
 }
 
 // UnmarshalPartialResource unmarshals the given payload into a *SoftResource.
@@ -221,77 +253,14 @@ func UnmarshalResource(data []byte, schema *Schema) (Resource, error) {
 // are added and set to their zero value, but UnmarshalPartialResource does not
 // do that. Therefore, the user is able to tell which fields have been set.
 func UnmarshalPartialResource(data []byte, schema *Schema) (*SoftResource, error) {
-	var rske resourceSkeleton
+	u := &unmarshaler{schema: schema, constraints: true}
 
-	err := json.Unmarshal(data, &rske)
+	res, err := u.resource(data, "", true)
 	if err != nil {
-		return nil, NewErrBadRequest(
-			"Invalid JSON",
-			"The provided JSON body could not be read.",
-		)
+		return nil, err
 	}
 
-	typ := schema.GetType(rske.Type)
-	newType := Type{
-		Name: typ.Name,
-	}
-	res := &SoftResource{
-		Type: &newType,
-		id:   rske.ID,
-	}
-
-	for a, v := range rske.Attributes {
-		if attr, ok := typ.Attrs[a]; ok {
-			val, err := attr.UnmarshalToType(v)
-			if err != nil {
-				return nil, err
-			}
-
-			_ = newType.AddAttr(attr)
-			res.Set(attr.Name, val)
-		} else {
-			return nil, NewErrUnknownFieldInBody(typ.Name, a)
-		}
-	}
-
-	for r, v := range rske.Relationships {
-		if rel, ok := typ.Rels[r]; ok {
-			if len(v.Data) > 0 {
-				if rel.ToOne {
-					var iden Identifier
-
-					err = json.Unmarshal(v.Data, &iden)
-					_ = newType.AddRel(rel)
-					res.Set(rel.FromName, iden.ID)
-				} else {
-					var idens Identifiers
-
-					err = json.Unmarshal(v.Data, &idens)
-
-					ids := make([]string, len(idens))
-
-					for i := range idens {
-						ids[i] = idens[i].ID
-					}
-
-					_ = newType.AddRel(rel)
-					res.Set(rel.FromName, ids)
-				}
-			}
-
-			if err != nil {
-				return nil, NewErrInvalidFieldValueInBody(
-					rel.FromName,
-					string(v.Data),
-					typ.Name,
-				)
-			}
-		} else {
-			return nil, NewErrUnknownFieldInBody(typ.Name, r)
-		}
-	}
-
-	return res, nil
+	return res.(*SoftResource), nil
 }
 
 // Equal reports whether r1 and r2 are equal.
@@ -377,23 +346,21 @@ func Equal(r1, r2 Resource) bool {
 
 	for i, rel1 := range r1Rels {
 		rel2 := r2Rels[i]
-		if rel1.ToOne != rel2.ToOne {
+		if rel1.ToOne != rel2.ToOne || rel1.Polymorphic() != rel2.Polymorphic() {
 			return false
 		}
 
-		if rel1.ToOne {
-			if r1.Get(rel1.FromName).(string) != r2.Get(rel2.FromName).(string) {
-				return false
-			}
-		} else {
-			v1 := r1.Get(rel1.FromName).([]string)
+		// The types only matter for polymorphic relationships.
+		v1 := relIdentifiers(r1, rel1)
+		v2 := relIdentifiers(r2, rel2)
 
-			v2 := r2.Get(rel2.FromName).([]string)
+		if len(v1) != len(v2) {
+			return false
+		}
 
-			if len(v1) != 0 || len(v2) != 0 {
-				if !reflect.DeepEqual(v1, v2) {
-					return false
-				}
+		for j := range v1 {
+			if v1[j].ID != v2[j].ID || (rel1.Polymorphic() && v1[j].Type != v2[j].Type) {
+				return false
 			}
 		}
 	}
@@ -92,6 +92,7 @@ func TestUnmarshalPartialResource(t *testing.T) {
 			err,
 			`400 Bad Request: "unknown" is not a known field.`,
 		)
+		assert.Equal("/attributes/unknown", err.(Error).Source["pointer"])
 	})
 
 	t.Run("partial resource (invalid relationship)", func(t *testing.T) {
@@ -155,6 +156,183 @@ func TestUnmarshalPartialResource(t *testing.T) {
 	})
 }
 
+func TestUnmarshalResourceAll(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	typ, _ := BuildType(mocktype{})
+	typ.NewFunc = func() Resource {
+		return Wrap(&mocktype{})
+	}
+	schema := &Schema{Types: []Type{typ}}
+
+	// Valid
+	res, err := UnmarshalResourceAll([]byte(`{
+		"id": "abc123",
+		"type": "mocktype",
+		"attributes": {"str": "abc", "int": 1}
+	}`), schema)
+	assert.NoError(err)
+	assert.Equal("abc", res.Get("str"))
+	assert.Equal(1, res.Get("int"))
+
+	// Invalid
+	_, err = UnmarshalResourceAll([]byte(`{
+		"id": "abc123",
+		"type": "mocktype",
+		"attributes": {"int": "not an int", "a/b": "abc"},
+		"relationships": {"to-x": {"data": {"type": "mocktype", "id": "id2"}}}
+	}`), schema)
+	assert.Equal(Errors{
+		withPointer(NewErrUnknownFieldInBody("mocktype", "a/b"), "/attributes/a~1b"),
+		withPointer(
+			NewErrInvalidFieldValueInBody("int", `"not an int"`, "int"),
+			"/attributes/int",
+		),
+		withPointer(
+			NewErrInvalidFieldValueInBody(
+				"to-x",
+				`{"type": "mocktype", "id": "id2"}`,
+				"mocktype",
+			),
+			"/relationships/to-x/data",
+		),
+	}, err)
+
+	// Unknown type
+	_, err = UnmarshalResourceAll([]byte(`{"id": "abc123", "type": "unknown"}`), schema)
+	assert.Equal(Errors{withPointer(NewErrUnknownTypeInBody("unknown"), "/type")}, err)
+}
+
+// withPointer returns err with its source pointer set to pointer.
+func withPointer(err Error, pointer string) Error {
+	err.Source["pointer"] = pointer
+
+	return err
+}
+
+func TestResourceRelMeta(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+
+	payload := `{
+		"type": "articles",
+		"id": "a1",
+		"relationships": {
+			"author": {
+				"links": {"self": "/custom/self", "about": {"href": "/about"}}
+			},
+			"related": {
+				"data": [{"type": "articles", "id": "a2"}],
+				"meta": {"count": 1}
+			}
+		}
+	}`
+
+	res, err := UnmarshalResource([]byte(payload), schema)
+	assert.NoError(err)
+
+	rm := res.(RelMetaHolder)
+	assert.Nil(rm.RelMeta("author"))
+	assert.Equal(map[string]Link{
+		"self":  {HRef: "/custom/self"},
+		"about": {HRef: "/about"},
+	}, rm.RelLinks("author"))
+	assert.Equal(Meta{"count": 1.0}, rm.RelMeta("related"))
+	assert.Nil(rm.RelLinks("related"))
+
+	// The generated links are replaced or completed.
+	pl := MarshalResource(
+		res,
+		"",
+		[]string{"author", "related"},
+		map[string][]string{"articles": {"related"}},
+	)
+	assert.JSONEq(`{
+		"type": "articles",
+		"id": "a1",
+		"relationships": {
+			"author": {
+				"links": {
+					"self": "/custom/self",
+					"related": "/articles/a1/author",
+					"about": "/about"
+				}
+			},
+			"related": {
+				"data": [{"type": "articles", "id": "a2"}],
+				"links": {
+					"self": "/articles/a1/relationships/related",
+					"related": "/articles/a1/related"
+				},
+				"meta": {"count": 1}
+			}
+		},
+		"links": {"self": "/articles/a1"}
+	}`, string(pl))
+
+	// Partial resources
+	res, err = UnmarshalPartialResource([]byte(payload), schema)
+	assert.NoError(err)
+	assert.Nil(res.(RelMetaHolder).RelMeta("related"))
+}
+
+func TestResourceLinks(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newHandlerSchema()
+
+	payload := `{
+		"data": {
+			"type": "articles",
+			"id": "a1",
+			"relationships": {"author": {"data": {"type": "users", "id": "u2"}}}
+		},
+		"included": [{
+			"type": "users",
+			"id": "u2",
+			"links": {
+				"self": "/custom/u2",
+				"profile": {"href": "/profiles/u2", "hreflang": ["en", "fr"]}
+			}
+		}]
+	}`
+
+	doc, err := UnmarshalDocument([]byte(payload), schema)
+	assert.NoError(err)
+
+	assert.Nil(doc.Data.(LinkHolder).Links())
+
+	inc := doc.Included[0]
+	assert.Equal(map[string]Link{
+		"self":    {HRef: "/custom/u2"},
+		"profile": {HRef: "/profiles/u2", HRefLang: []string{"en", "fr"}},
+	}, inc.(LinkHolder).Links())
+
+	// The self link is replaced and the others are added.
+	assert.JSONEq(`{
+		"type": "users",
+		"id": "u2",
+		"attributes": {"name": ""},
+		"links": {
+			"self": "/custom/u2",
+			"profile": {"href": "/profiles/u2", "hreflang": ["en", "fr"]}
+		}
+	}`, string(MarshalResource(inc, "", []string{"name"}, nil)))
+
+	// Wrapped structs
+	wrap := Wrap(&mocktype{ID: "id1"})
+	wrap.SetLinks(map[string]Link{"about": {HRef: "/about"}})
+	assert.Equal(map[string]Link{"about": {HRef: "/about"}}, wrap.Links())
+
+	// The links survive the addition to a collection
+	typ := schema.GetType("users")
+	col := &SoftCollection{Type: &typ}
+	col.Add(inc)
+	assert.Equal(inc.(LinkHolder).Links(), col.At(0).(LinkHolder).Links())
+}
+
 func TestEqual(t *testing.T) {
 	assert := assert.New(t)
 
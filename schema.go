@@ -102,7 +102,7 @@ func (s *Schema) RemoveRel(typ string, rel string) {
 // The types must already exist in the schema.
 func (s *Schema) AddTwoWayRel(rel Rel) error {
 	rel1 := rel.Normalize()
-	rel2 := rel.Invert()
+	rel2 := rel1.Invert()
 	found1 := false
 	found2 := false
 
@@ -194,17 +194,52 @@ func (s *Schema) Check() []error {
 	for _, typ := range s.Types {
 		// Relationships
 		for _, rel := range typ.Rels {
-			var targetType Type
+			var targetTypes []Type
 
-			// Does the relationship point to a type that exists?
-			if targetType = s.GetType(rel.ToType); targetType.Name == "" {
+			// Does the relationship have both a type and a set of types?
+			if rel.Polymorphic() && rel.ToType != "" {
 				errs = append(errs, fmt.Errorf(
-					"jsonapi: field ToType of relationship %q of type %q does not exist",
+					"jsonapi: relationship %q of type %q has both ToType and ToTypes",
 					rel.FromName,
 					typ.Name,
 				))
 			}
 
+			for i, t := range rel.TargetTypes() {
+				// Is the type listed more than once?
+				if i > 0 && (Rel{ToTypes: rel.ToTypes[:i]}).Targets(t) {
+					errs = append(errs, fmt.Errorf(
+						"jsonapi: type %q is listed twice in relationship %q of type %q",
+						t,
+						rel.FromName,
+						typ.Name,
+					))
+
+					continue
+				}
+
+				// Does the relationship point to a type that exists?
+				targetType := s.GetType(t)
+
+				switch {
+				case targetType.Name != "":
+					targetTypes = append(targetTypes, targetType)
+				case rel.Polymorphic():
+					errs = append(errs, fmt.Errorf(
+						"jsonapi: type %q of relationship %q of type %q does not exist",
+						t,
+						rel.FromName,
+						typ.Name,
+					))
+				default:
+					errs = append(errs, fmt.Errorf(
+						"jsonapi: field ToType of relationship %q of type %q does not exist",
+						rel.FromName,
+						typ.Name,
+					))
+				}
+			}
+
 			// Skip to next relationship here if there's no inverse
 			if rel.ToName == "" {
 				continue
@@ -220,9 +255,14 @@ func (s *Schema) Check() []error {
 					typ.Name,
 					rel.FromType,
 				))
-			} else {
-				// Do both relationships (current and inverse) point
-				// to each other?
+
+				continue
+			}
+
+			// Do both relationships (current and inverse) point to
+			// each other? A polymorphic relationship has an inverse
+			// in each of the types it points to.
+			for _, targetType := range targetTypes {
 				var found bool
 
 				for _, invRel := range targetType.Rels {
@@ -231,7 +271,20 @@ func (s *Schema) Check() []error {
 					}
 				}
 
-				if !found {
+				if found {
+					continue
+				}
+
+				if rel.Polymorphic() {
+					errs = append(errs, fmt.Errorf(
+						"jsonapi: "+
+							"relationship %q of type %q and its inverse in type %q "+
+							"do not point each other",
+						rel.FromName,
+						typ.Name,
+						targetType.Name,
+					))
+				} else {
 					errs = append(errs, fmt.Errorf(
 						"jsonapi: "+
 							"relationship %q of type %q and its inverse do not point each other",
@@ -113,6 +113,23 @@ func TestSchemaAddTwoWayRel(t *testing.T) {
 	})
 	assert.NoError(err)
 
+	// Add two-way relationship (inverted when normalized)
+	schema = &Schema{}
+	_ = schema.AddType(Type{Name: "type1"})
+	_ = schema.AddType(Type{Name: "type2"})
+
+	err = schema.AddTwoWayRel(Rel{
+		FromType: "type2",
+		FromName: "parent",
+		ToOne:    true,
+		ToType:   "type1",
+		ToName:   "children",
+		FromOne:  false,
+	})
+	assert.NoError(err)
+	assert.Contains(schema.GetType("type1").Rels, "children")
+	assert.Contains(schema.GetType("type2").Rels, "parent")
+
 	// Add two-way relationship (missing type)
 	schema = &Schema{}
 	_ = schema.AddType(Type{Name: "type1"})
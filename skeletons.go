@@ -6,19 +6,23 @@ type payloadSkeleton struct {
 	Data     json.RawMessage   `json:"data"`
 	Errors   []Error           `json:"errors"`
 	Included []json.RawMessage `json:"included"`
+	Links    map[string]Link   `json:"links"`
 	Meta     Meta              `json:"meta"`
+	JSONAPI  JSONAPI           `json:"jsonapi"`
 }
 
 type resourceSkeleton struct {
 	ID            string                          `json:"id"`
+	LID           string                          `json:"lid"`
 	Type          string                          `json:"type"`
 	Attributes    map[string]json.RawMessage      `json:"attributes"`
 	Relationships map[string]relationshipSkeleton `json:"relationships"`
+	Links         map[string]Link                 `json:"links"`
 	Meta          Meta                            `json:"meta"`
 }
 
 type relationshipSkeleton struct {
-	Data  json.RawMessage            `json:"data"`
-	Links map[string]json.RawMessage `json:"links"`
-	Meta  map[string]json.RawMessage `json:"meta"`
+	Data  json.RawMessage `json:"data"`
+	Links map[string]Link `json:"links"`
+	Meta  Meta            `json:"meta"`
 }
@@ -6,6 +6,10 @@ type SoftCollection struct {
 	Type *Type
 
 	col []*SoftResource
+
+	// total is the number of resources found before pagination plus
+	// one, which makes the zero value mean that it is unknown.
+	total int
 }
 
 // SetType sets the collection's type.
@@ -42,6 +46,22 @@ func (s *SoftCollection) At(i int) Resource {
 	return nil
 }
 
+// Total returns the number of resources found before the collection was split
+// into pages, or -1 if it is unknown.
+func (s *SoftCollection) Total() int {
+	return s.total - 1
+}
+
+// SetTotal sets the number of resources found before the collection was split
+// into pages. A negative number means that it is unknown.
+func (s *SoftCollection) SetTotal(total int) {
+	if total < 0 {
+		total = -1
+	}
+
+	s.total = total + 1
+}
+
 // Resource returns the element with an ID equal to id.
 //
 // It builds and returns a SoftResource with only the specified fields.
@@ -70,14 +90,33 @@ func (s *SoftCollection) Add(r Resource) {
 
 	for _, rel := range r.Rels() {
 		sr.AddRel(rel)
+		sr.Set(rel.FromName, copyRelValue(r.Get(rel.FromName)))
+	}
 
-		if rel.ToOne {
-			sr.Set(rel.FromName, r.Get(rel.FromName).(string))
-		} else {
-			sr.Set(rel.FromName, r.Get(rel.FromName).([]string))
+	if m, ok := r.(MetaHolder); ok {
+		sr.SetMeta(copyMeta(m.Meta()))
+	}
+
+	if l, ok := r.(LinkHolder); ok {
+		sr.SetLinks(copyLinks(l.Links()))
+	}
+
+	if rm, ok := r.(RelMetaHolder); ok {
+		for _, rel := range r.Rels() {
+			if m := rm.RelMeta(rel.FromName); m != nil {
+				sr.SetRelMeta(rel.FromName, copyMeta(m))
+			}
+
+			if l := rm.RelLinks(rel.FromName); l != nil {
+				sr.SetRelLinks(rel.FromName, copyLinks(l))
+			}
 		}
 	}
 
+	if l, ok := r.(LIDHolder); ok {
+		sr.lid = l.GetLID()
+	}
+
 	s.col = append(s.col, sr)
 }
 
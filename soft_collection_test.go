@@ -168,4 +168,13 @@ func TestSoftCollectionMiscellaneous(t *testing.T) {
 
 	sc := &SoftCollection{}
 	assert.Nil(sc.At(99), "nonexistent element")
+
+	// Total
+	assert.Equal(-1, sc.Total(), "unknown total")
+	sc.SetTotal(0)
+	assert.Equal(0, sc.Total())
+	sc.SetTotal(12)
+	assert.Equal(12, sc.Total())
+	sc.SetTotal(-5)
+	assert.Equal(-1, sc.Total())
 }
@@ -2,6 +2,7 @@ package jsonapi
 
 import (
 	"fmt"
+	"reflect"
 	"time"
 )
 
@@ -14,9 +15,14 @@ import (
 type SoftResource struct {
 	Type *Type
 
-	id   string
-	data map[string]any
-	meta Meta
+	id    string
+	lid   string
+	data  map[string]any
+	meta  Meta
+	links map[string]Link
+
+	relMeta  map[string]Meta
+	relLinks map[string]map[string]Link
 }
 
 // Attrs returns the resource's attributes.
@@ -158,27 +164,41 @@ func (sr *SoftResource) Set(key string, v any) {
 			sr.data[key] = GetZeroValue(attr.Type, attr.Nullable)
 		}
 	} else if rel, ok := sr.Type.Rels[key]; ok {
-		if _, ok := v.(string); ok && rel.ToOne {
-			sr.data[key] = v
-		} else if _, ok := v.([]string); ok && !rel.ToOne {
+		if reflect.TypeOf(v) == reflect.TypeOf(relZeroValue(rel)) {
 			sr.data[key] = v
 		}
 	}
 }
 
-// Copy returns a new SoftResource object with the same type and values.
+// Copy returns a new SoftResource object with the same type and values, as
+// well as copies of the meta values and links.
 func (sr *SoftResource) Copy() Resource {
 	sr.check()
 
 	typ := sr.Type.Copy()
 
 	return &SoftResource{
-		Type: &typ,
-		id:   sr.id,
-		data: copyData(sr.data),
+		Type:     &typ,
+		id:       sr.id,
+		lid:      sr.lid,
+		data:     copyData(sr.data),
+		meta:     copyMeta(sr.meta),
+		links:    copyLinks(sr.links),
+		relMeta:  copyRelMeta(sr.relMeta),
+		relLinks: copyRelLinks(sr.relLinks),
 	}
 }
 
+// GetLID returns the resource's local ID.
+func (sr *SoftResource) GetLID() string {
+	return sr.lid
+}
+
+// SetLID sets the resource's local ID.
+func (sr *SoftResource) SetLID(lid string) {
+	sr.lid = lid
+}
+
 // Meta returns the meta values of the resource.
 func (sr *SoftResource) Meta() Meta {
 	return sr.meta
@@ -189,6 +209,44 @@ func (sr *SoftResource) SetMeta(m Meta) {
 	sr.meta = m
 }
 
+// Links returns the links of the resource.
+func (sr *SoftResource) Links() map[string]Link {
+	return sr.links
+}
+
+// SetLinks sets the links of the resource.
+func (sr *SoftResource) SetLinks(links map[string]Link) {
+	sr.links = links
+}
+
+// RelMeta returns the meta values of the relationship rel.
+func (sr *SoftResource) RelMeta(rel string) Meta {
+	return sr.relMeta[rel]
+}
+
+// SetRelMeta sets the meta values of the relationship rel.
+func (sr *SoftResource) SetRelMeta(rel string, m Meta) {
+	if sr.relMeta == nil {
+		sr.relMeta = map[string]Meta{}
+	}
+
+	sr.relMeta[rel] = m
+}
+
+// RelLinks returns the links of the relationship rel.
+func (sr *SoftResource) RelLinks(rel string) map[string]Link {
+	return sr.relLinks[rel]
+}
+
+// SetRelLinks sets the links of the relationship rel.
+func (sr *SoftResource) SetRelLinks(rel string, links map[string]Link) {
+	if sr.relLinks == nil {
+		sr.relLinks = map[string]map[string]Link{}
+	}
+
+	sr.relLinks[rel] = links
+}
+
 func (sr *SoftResource) fields() []string {
 	fields := make([]string, 0, len(sr.Type.Attrs)+len(sr.Type.Rels))
 	for i := range sr.Type.Attrs {
@@ -229,11 +287,7 @@ func (sr *SoftResource) check() {
 	for i := range sr.Type.Rels {
 		n := sr.Type.Rels[i].FromName
 		if _, ok := sr.data[n]; !ok {
-			if sr.Type.Rels[i].ToOne {
-				sr.data[n] = ""
-			} else {
-				sr.data[n] = []string{}
-			}
+			sr.data[n] = relZeroValue(sr.Type.Rels[i])
 		}
 	}
 
@@ -290,11 +344,29 @@ func copyData(d map[string]any) map[string]any {
 		case []uint8:
 			nv := make([]byte, len(v2))
 			_ = copy(nv, v2)
-			d2[k] = v2
+			d2[k] = nv
 		case []string:
 			nv := make([]string, len(v2))
 			_ = copy(nv, v2)
+			d2[k] = nv
+		case Identifier:
+			d2[k] = v2
+		case Identifiers:
+			nv := make(Identifiers, len(v2))
+			_ = copy(nv, v2)
+			d2[k] = nv
+		case float32:
+			d2[k] = v2
+		case float64:
 			d2[k] = v2
+		case Decimal:
+			d2[k] = v2
+		case []float64:
+			nv := make([]float64, len(v2))
+			_ = copy(nv, v2)
+			d2[k] = nv
+		case map[string]any:
+			d2[k] = copyJSONValue(v2)
 		case *string:
 			d2[k] = v2
 		case *int:
@@ -327,10 +399,125 @@ func copyData(d map[string]any) map[string]any {
 			} else {
 				nv := make([]byte, len(*v2))
 				_ = copy(nv, *v2)
-				d2[k] = v2
+				d2[k] = &nv
+			}
+		case *float32:
+			d2[k] = v2
+		case *float64:
+			d2[k] = v2
+		case *Decimal:
+			d2[k] = v2
+		case *[]string:
+			if v2 == nil {
+				d2[k] = (*[]string)(nil)
+			} else {
+				nv := make([]string, len(*v2))
+				_ = copy(nv, *v2)
+				d2[k] = &nv
 			}
+		case *[]float64:
+			if v2 == nil {
+				d2[k] = (*[]float64)(nil)
+			} else {
+				nv := make([]float64, len(*v2))
+				_ = copy(nv, *v2)
+				d2[k] = &nv
+			}
+		case *map[string]any:
+			if v2 == nil {
+				d2[k] = (*map[string]any)(nil)
+			} else {
+				nv := copyJSONValue(*v2).(map[string]any)
+				d2[k] = &nv
+			}
+		default:
+			// Values of registered attribute types are copied as
+			// is, but not shared through pointers.
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+				p := reflect.New(rv.Elem().Type())
+				p.Elem().Set(rv.Elem())
+				v = p.Interface()
+			}
+
+			d2[k] = v
 		}
 	}
 
 	return d2
 }
+
+// copyRelMeta returns a copy of the meta values of relationships that does not
+// share its maps with relMeta.
+func copyRelMeta(relMeta map[string]Meta) map[string]Meta {
+	if relMeta == nil {
+		return nil
+	}
+
+	c := make(map[string]Meta, len(relMeta))
+	for rel, m := range relMeta {
+		c[rel] = copyMeta(m)
+	}
+
+	return c
+}
+
+// copyMeta returns a deep copy of m.
+func copyMeta(m Meta) Meta {
+	if m == nil {
+		return nil
+	}
+
+	return Meta(copyJSONValue(map[string]any(m)).(map[string]any))
+}
+
+// copyRelLinks returns a copy of the links of relationships that does not
+// share its maps with relLinks.
+func copyRelLinks(relLinks map[string]map[string]Link) map[string]map[string]Link {
+	if relLinks == nil {
+		return nil
+	}
+
+	c := make(map[string]map[string]Link, len(relLinks))
+	for rel, links := range relLinks {
+		c[rel] = copyLinks(links)
+	}
+
+	return c
+}
+
+// copyLinks returns a copy of links.
+func copyLinks(links map[string]Link) map[string]Link {
+	if links == nil {
+		return nil
+	}
+
+	c := make(map[string]Link, len(links))
+	for name, link := range links {
+		c[name] = link
+	}
+
+	return c
+}
+
+// copyJSONValue deeply copies v, which is made of the values produced by
+// json.Unmarshal when the destination is an empty interface.
+func copyJSONValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = copyJSONValue(e)
+		}
+
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = copyJSONValue(e)
+		}
+
+		return s
+	default:
+		return v
+	}
+}
@@ -233,6 +233,18 @@ func TestSoftResourceCopy(t *testing.T) {
 		"*bool":      ptr(true),
 		"*time.Time": ptr(now),
 		"*[]uint8":   ptr([]byte{'a', 'b', 'c'}),
+		"float32":    float32(1.5),
+		"float64":    2.5,
+		"decimal":    Decimal("12.50"),
+		"[]string":   []string{"a", "b"},
+		"[]float64":  []float64{1, 2},
+		"object":     map[string]any{"a": []any{"b"}},
+		"*float32":   ptr(float32(1.5)),
+		"*float64":   ptr(2.5),
+		"*decimal":   ptr(Decimal("12.50")),
+		"*[]string":  ptr([]string{"a", "b"}),
+		"*[]float64": ptr([]float64{1, 2}),
+		"*object":    ptr(map[string]any{"a": []any{"b"}}),
 	}
 
 	for t, v := range attrs {
@@ -272,6 +284,24 @@ func TestSoftResourceCopy(t *testing.T) {
 	// Copy
 	sr2 := sr.Copy()
 	assert.Equal(true, Equal(sr, sr2))
+
+	// Arrays and objects are not shared
+	sr2.Get("[]string").([]string)[0] = "z"
+	sr2.Get("object").(map[string]any)["a"].([]any)[0] = "z"
+	(*sr2.Get("*object").(*map[string]any))["a"] = "z"
+	assert.Equal([]string{"a", "b"}, sr.Get("[]string"))
+	assert.Equal(map[string]any{"a": []any{"b"}}, sr.Get("object"))
+	assert.Equal(ptr(map[string]any{"a": []any{"b"}}), sr.Get("*object"))
+
+	// Meta values and links are not shared
+	sr.SetMeta(Meta{"a": map[string]any{"b": 1}})
+	sr.SetLinks(map[string]Link{"about": {HRef: "/about"}})
+
+	sr2 = sr.Copy()
+	sr2.(*SoftResource).Meta()["a"].(map[string]any)["b"] = 2
+	sr2.(*SoftResource).Links()["about"] = Link{HRef: "/other"}
+	assert.Equal(Meta{"a": map[string]any{"b": 1}}, sr.Meta())
+	assert.Equal(map[string]Link{"about": {HRef: "/about"}}, sr.Links())
 }
 
 func TestSoftResourceMeta(t *testing.T) {
@@ -296,6 +326,29 @@ func TestSoftResourceMeta(t *testing.T) {
 	assert.Equal(meta, sr.Meta())
 }
 
+func TestSoftResourceRelMeta(t *testing.T) {
+	assert := assert.New(t)
+
+	typ, _ := BuildType(mocktype{})
+	sr := &SoftResource{Type: &typ}
+	assert.Nil(sr.RelMeta("to-1"))
+	assert.Nil(sr.RelLinks("to-1"))
+
+	sr.SetRelMeta("to-x", Meta{"count": 2})
+	sr.SetRelLinks("to-x", map[string]Link{"about": {HRef: "/about"}})
+	assert.Equal(Meta{"count": 2}, sr.RelMeta("to-x"))
+	assert.Equal(map[string]Link{"about": {HRef: "/about"}}, sr.RelLinks("to-x"))
+	assert.Nil(sr.RelMeta("to-1"))
+
+	// The values survive the addition to a collection
+	col := &SoftCollection{Type: &typ}
+	col.Add(sr)
+
+	rm := col.At(0).(RelMetaHolder)
+	assert.Equal(Meta{"count": 2}, rm.RelMeta("to-x"))
+	assert.Equal(map[string]Link{"about": {HRef: "/about"}}, rm.RelLinks("to-x"))
+}
+
 func TestSoftResourceGetSetID(t *testing.T) {
 	assert := assert.New(t)
 
@@ -304,3 +357,22 @@ func TestSoftResourceGetSetID(t *testing.T) {
 
 	assert.Equal("abc123", sr.Get("id"))
 }
+
+func TestSoftResourceLID(t *testing.T) {
+	assert := assert.New(t)
+
+	typ, _ := BuildType(mocktype{})
+	sr := &SoftResource{Type: &typ}
+	assert.Equal("", sr.GetLID())
+
+	sr.SetLID("local1")
+	assert.Equal("local1", sr.GetLID())
+
+	// The local ID is part of the copy
+	assert.Equal("local1", sr.Copy().(*SoftResource).GetLID())
+
+	// The local ID survives the addition to a collection
+	col := &SoftCollection{Type: &typ}
+	col.Add(sr)
+	assert.Equal("local1", col.At(0).(LIDHolder).GetLID())
+}
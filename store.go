@@ -0,0 +1,68 @@
+package jsonapi
+
+// A Store defines the interface of a backend that persists the resources of a
+// single type.
+//
+// A Handler uses one Store per type to serve a JSON:API. Errors returned by a
+// Store should be of type Error when they are meant to be shown to the client,
+// otherwise a generic internal server error is reported. Methods that target a
+// resource that does not exist should return the error from NewErrNotFound.
+//
+// MemoryStore is an implementation that keeps everything in memory. The
+// storetest package provides a test suite that any implementation can run to
+// make sure it behaves as expected.
+type Store interface {
+	// Resource returns the resource identified by id.
+	//
+	// A nil Resource and a nil error are returned if the resource does
+	// not exist.
+	Resource(id string) (Resource, error)
+
+	// Range returns the resources arranged according to params.
+	//
+	// Only the resources whose IDs are in ids are considered, unless
+	// ids is empty, in which case all resources are considered. The
+	// filter, the sorting rules and the pagination are taken from
+	// params, which can be nil.
+	//
+	// The returned collection may implement TotalHolder to report the
+	// number of resources found before pagination.
+	Range(ids []string, params *Params) (Collection, error)
+
+	// Insert adds res to the store.
+	Insert(res Resource) error
+
+	// Update sets the fields of the stored resource that has the same
+	// ID as res to the values found in res.
+	//
+	// Only the attributes and relationships defined in res are updated,
+	// which means res can be a partial resource like the ones returned
+	// by UnmarshalPartialResource.
+	Update(res Resource) error
+
+	// Delete removes the resource identified by id.
+	Delete(id string) error
+
+	// The following methods only deal with IDs, so they are never
+	// called for polymorphic relationships, which are set with Update
+	// instead.
+
+	// UpdateToOne sets the to-one relationship named rel of the
+	// resource identified by id to toID. An empty toID empties the
+	// relationship.
+	UpdateToOne(id, rel, toID string) error
+
+	// UpdateToMany replaces the IDs of the to-many relationship named
+	// rel of the resource identified by id with ids.
+	UpdateToMany(id, rel string, ids []string) error
+
+	// AddToMany adds ids to the to-many relationship named rel of the
+	// resource identified by id. IDs that are already part of the
+	// relationship are ignored.
+	AddToMany(id, rel string, ids []string) error
+
+	// RemoveFromMany removes ids from the to-many relationship named
+	// rel of the resource identified by id. IDs that are not part of
+	// the relationship are ignored.
+	RemoveFromMany(id, rel string, ids []string) error
+}
@@ -0,0 +1,587 @@
+/*
+Package storetest implements a test suite for implementations of the
+jsonapi.Store interface.
+
+A store implementation can be checked from its own test suite:
+
+	func TestMyStore(t *testing.T) {
+		storetest.TestStore(t, func(typ jsonapi.Type) jsonapi.Store {
+			return NewMyStore(typ)
+		})
+	}
+*/
+package storetest
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/mfcochauxlaberge/jsonapi"
+)
+
+// This is for backward compatibility with
+// Go versions under 1.18.
+type any = interface{}
+
+// TestStore runs a series of tests against the stores returned by newStore.
+//
+// newStore must return a new and empty store that manages resources of the
+// given type. It is called once per test.
+func TestStore(t *testing.T, newStore func(jsonapi.Type) jsonapi.Store) {
+	tests := []struct {
+		name string
+		test func(*testing.T, jsonapi.Store)
+	}{
+		{name: "insert and get", test: testInsertAndGet},
+		{name: "insert existing", test: testInsertExisting},
+		{name: "get unknown", test: testGetUnknown},
+		{name: "copies", test: testCopies},
+		{name: "relationship meta and links", test: testRelMeta},
+		{name: "links", test: testLinks},
+		{name: "range", test: testRange},
+		{name: "range with ids", test: testRangeWithIDs},
+		{name: "range with filter", test: testRangeWithFilter},
+		{name: "range with pagination", test: testRangeWithPagination},
+		{name: "update", test: testUpdate},
+		{name: "update copies", test: testUpdateCopies},
+		{name: "update unknown", test: testUpdateUnknown},
+		{name: "delete", test: testDelete},
+		{name: "delete unknown", test: testDeleteUnknown},
+		{name: "update to-one", test: testUpdateToOne},
+		{name: "update to-many", test: testUpdateToMany},
+		{name: "add to to-many", test: testAddToMany},
+		{name: "remove from to-many", test: testRemoveFromMany},
+		{name: "concurrency", test: testConcurrency},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, newStore(Type()))
+		})
+	}
+}
+
+// Type returns the type used by TestStore.
+//
+// It has a few attributes, including a slice of strings named tags, a to-one
+// relationship named parent, and a to-many relationship named children. Both
+// relationships point to the type itself.
+func Type() jsonapi.Type {
+	typ := jsonapi.Type{Name: "things"}
+
+	_ = typ.AddAttr(jsonapi.Attr{
+		Name: "name",
+		Type: jsonapi.AttrTypeString,
+	})
+	_ = typ.AddAttr(jsonapi.Attr{
+		Name: "rank",
+		Type: jsonapi.AttrTypeInt,
+	})
+	_ = typ.AddAttr(jsonapi.Attr{
+		Name:     "note",
+		Type:     jsonapi.AttrTypeString,
+		Nullable: true,
+	})
+	_ = typ.AddAttr(jsonapi.Attr{
+		Name: "tags",
+		Type: jsonapi.AttrTypeStrings,
+	})
+	_ = typ.AddRel(jsonapi.Rel{
+		FromType: "things",
+		FromName: "parent",
+		ToOne:    true,
+		ToType:   "things",
+	})
+	_ = typ.AddRel(jsonapi.Rel{
+		FromType: "things",
+		FromName: "children",
+		ToOne:    false,
+		ToType:   "things",
+	})
+
+	return typ
+}
+
+func testInsertAndGet(t *testing.T, store jsonapi.Store) {
+	res := newThing("t1", "one", 1)
+	res.Set("parent", "t2")
+	res.Set("children", []string{"t3", "t4"})
+
+	mustInsert(t, store, res)
+
+	got := mustGet(t, store, "t1")
+	if got == nil {
+		t.Fatal("inserted resource not found")
+	}
+
+	if !jsonapi.EqualStrict(res, got) {
+		t.Errorf("got %v, expected %v", dump(got), dump(res))
+	}
+}
+
+func testInsertExisting(t *testing.T, store jsonapi.Store) {
+	mustInsert(t, store, newThing("t1", "one", 1))
+
+	if err := store.Insert(newThing("t1", "other", 2)); err == nil {
+		t.Error("inserting a resource with an existing ID should fail")
+	}
+
+	if got := mustGet(t, store, "t1"); got.Get("name") != "one" {
+		t.Errorf("resource was modified by a failed insertion: %v", dump(got))
+	}
+}
+
+func testGetUnknown(t *testing.T, store jsonapi.Store) {
+	if got := mustGet(t, store, "unknown"); got != nil {
+		t.Errorf("got %v, expected nil", dump(got))
+	}
+}
+
+func testCopies(t *testing.T, store jsonapi.Store) {
+	res := newThing("t1", "one", 1)
+	res.Set("children", []string{"t2"})
+
+	mustInsert(t, store, res)
+
+	// Modifying the inserted resource must not modify the store.
+	res.Set("name", "modified")
+	res.Get("children").([]string)[0] = "modified"
+
+	got := mustGet(t, store, "t1")
+	if got.Get("name") != "one" || got.Get("children").([]string)[0] != "t2" {
+		t.Errorf("store shares values with the inserted resource: %v", dump(got))
+	}
+
+	// Modifying a returned resource must not modify the store.
+	got.Set("name", "modified")
+	got.Get("children").([]string)[0] = "modified"
+
+	got = mustGet(t, store, "t1")
+	if got.Get("name") != "one" || got.Get("children").([]string)[0] != "t2" {
+		t.Errorf("store shares values with the returned resources: %v", dump(got))
+	}
+}
+
+func testRelMeta(t *testing.T, store jsonapi.Store) {
+	res := newThing("t1", "one", 1)
+	res.(jsonapi.RelMetaHolder).SetRelMeta("parent", jsonapi.Meta{"k": 1})
+	res.(jsonapi.RelMetaHolder).SetRelLinks("children", map[string]jsonapi.Link{
+		"about": {HRef: "/about"},
+	})
+
+	mustInsert(t, store, res)
+
+	check := func(got jsonapi.Resource) {
+		t.Helper()
+
+		rm, ok := got.(jsonapi.RelMetaHolder)
+		if !ok {
+			t.Fatal("returned resource does not hold relationship meta values")
+		}
+
+		if v := rm.RelMeta("parent")["k"]; v != 1 {
+			t.Errorf("got meta value %v, expected 1", v)
+		}
+
+		if l := rm.RelLinks("children")["about"]; l.HRef != "/about" {
+			t.Errorf("got link %q, expected \"/about\"", l.HRef)
+		}
+	}
+
+	got := mustGet(t, store, "t1")
+	check(got)
+	check(mustRange(t, store, nil, nil).At(0))
+
+	// Modifying a returned resource must not modify the store.
+	got.(jsonapi.RelMetaHolder).RelMeta("parent")["k"] = 2
+	got.(jsonapi.RelMetaHolder).SetRelLinks("children", nil)
+
+	check(mustGet(t, store, "t1"))
+}
+
+func testLinks(t *testing.T, store jsonapi.Store) {
+	res := newThing("t1", "one", 1)
+	res.(jsonapi.LinkHolder).SetLinks(map[string]jsonapi.Link{
+		"about": {HRef: "/about"},
+	})
+
+	mustInsert(t, store, res)
+
+	for _, got := range []jsonapi.Resource{
+		mustGet(t, store, "t1"),
+		mustRange(t, store, nil, nil).At(0),
+	} {
+		lh, ok := got.(jsonapi.LinkHolder)
+		if !ok {
+			t.Fatal("returned resource does not hold links")
+		}
+
+		if l := lh.Links()["about"]; l.HRef != "/about" {
+			t.Errorf("got link %q, expected \"/about\"", l.HRef)
+		}
+	}
+}
+
+func testRange(t *testing.T, store jsonapi.Store) {
+	insertThings(t, store)
+
+	col := mustRange(t, store, nil, &jsonapi.Params{
+		SortingRules: []string{"-rank", "id"},
+	})
+	expected := []string{"t3", "t5", "t1", "t4", "t2"}
+
+	if ids := collectionIDs(col); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("got %v, expected %v", ids, expected)
+	}
+
+	col = mustRange(t, store, nil, nil)
+	expected = []string{"t1", "t2", "t3", "t4", "t5"}
+
+	ids := collectionIDs(col)
+	sort.Strings(ids)
+
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("got %v, expected %v", ids, expected)
+	}
+}
+
+func testRangeWithIDs(t *testing.T, store jsonapi.Store) {
+	insertThings(t, store)
+
+	col := mustRange(t, store, []string{"t2", "t4", "unknown"}, &jsonapi.Params{
+		SortingRules: []string{"id"},
+	})
+	expected := []string{"t2", "t4"}
+
+	if ids := collectionIDs(col); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("got %v, expected %v", ids, expected)
+	}
+}
+
+func testRangeWithFilter(t *testing.T, store jsonapi.Store) {
+	insertThings(t, store)
+
+	col := mustRange(t, store, nil, &jsonapi.Params{
+		Filter: &jsonapi.Filter{
+			Field: "name",
+			Op:    "=",
+			Val:   "b",
+		},
+		SortingRules: []string{"id"},
+	})
+	expected := []string{"t2", "t4"}
+
+	if ids := collectionIDs(col); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("got %v, expected %v", ids, expected)
+	}
+}
+
+func testRangeWithPagination(t *testing.T, store jsonapi.Store) {
+	insertThings(t, store)
+
+	col := mustRange(t, store, nil, &jsonapi.Params{
+		SortingRules: []string{"id"},
+		Page: map[string]any{
+			"size":   2,
+			"number": 1,
+		},
+	})
+	expected := []string{"t3", "t4"}
+
+	if ids := collectionIDs(col); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("got %v, expected %v", ids, expected)
+	}
+
+	// The total is optional.
+	if th, ok := col.(jsonapi.TotalHolder); ok && th.Total() >= 0 && th.Total() != 5 {
+		t.Errorf("got total %d, expected 5", th.Total())
+	}
+}
+
+func testUpdate(t *testing.T, store jsonapi.Store) {
+	res := newThing("t1", "one", 1)
+	res.Set("parent", "t2")
+	mustInsert(t, store, res)
+
+	// Partial resource
+	typ := &jsonapi.Type{Name: "things"}
+	_ = typ.AddAttr(Type().Attrs["name"])
+	_ = typ.AddRel(Type().Rels["children"])
+
+	partial := &jsonapi.SoftResource{Type: typ}
+	partial.SetID("t1")
+	partial.Set("name", "updated")
+	partial.Set("children", []string{"t3"})
+
+	if err := store.Update(partial); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	res.Set("name", "updated")
+	res.Set("children", []string{"t3"})
+
+	if got := mustGet(t, store, "t1"); !jsonapi.EqualStrict(res, got) {
+		t.Errorf("got %v, expected %v", dump(got), dump(res))
+	}
+}
+
+func testUpdateCopies(t *testing.T, store jsonapi.Store) {
+	mustInsert(t, store, newThing("t1", "one", 1))
+
+	res := newThing("t1", "one", 1)
+	res.Set("tags", []string{"a"})
+	res.Set("children", []string{"t2"})
+
+	if err := store.Update(res); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Modifying the resource used for the update must not modify the store.
+	res.Get("tags").([]string)[0] = "modified"
+	res.Get("children").([]string)[0] = "modified"
+
+	got := mustGet(t, store, "t1")
+	if got.Get("tags").([]string)[0] != "a" || got.Get("children").([]string)[0] != "t2" {
+		t.Errorf("store shares values with the updated resource: %v", dump(got))
+	}
+}
+
+func testUpdateUnknown(t *testing.T, store jsonapi.Store) {
+	if err := store.Update(newThing("unknown", "one", 1)); err == nil {
+		t.Error("updating an unknown resource should fail")
+	}
+}
+
+func testDelete(t *testing.T, store jsonapi.Store) {
+	insertThings(t, store)
+
+	if err := store.Delete("t2"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := mustGet(t, store, "t2"); got != nil {
+		t.Errorf("deleted resource still exists: %v", dump(got))
+	}
+
+	ids := collectionIDs(mustRange(t, store, nil, nil))
+	sort.Strings(ids)
+
+	if expected := []string{"t1", "t3", "t4", "t5"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("got %v, expected %v", ids, expected)
+	}
+}
+
+func testDeleteUnknown(t *testing.T, store jsonapi.Store) {
+	if err := store.Delete("unknown"); err == nil {
+		t.Error("deleting an unknown resource should fail")
+	}
+}
+
+func testUpdateToOne(t *testing.T, store jsonapi.Store) {
+	mustInsert(t, store, newThing("t1", "one", 1))
+
+	if err := store.UpdateToOne("t1", "parent", "t2"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := mustGet(t, store, "t1").Get("parent"); got != "t2" {
+		t.Errorf("got %q, expected %q", got, "t2")
+	}
+
+	if err := store.UpdateToOne("t1", "parent", ""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := mustGet(t, store, "t1").Get("parent"); got != "" {
+		t.Errorf("got %q, expected an empty string", got)
+	}
+
+	if err := store.UpdateToOne("unknown", "parent", "t2"); err == nil {
+		t.Error("updating an unknown resource should fail")
+	}
+
+	if err := store.UpdateToOne("t1", "children", "t2"); err == nil {
+		t.Error("updating a to-many relationship as a to-one should fail")
+	}
+}
+
+func testUpdateToMany(t *testing.T, store jsonapi.Store) {
+	res := newThing("t1", "one", 1)
+	res.Set("children", []string{"t2"})
+	mustInsert(t, store, res)
+
+	if err := store.UpdateToMany("t1", "children", []string{"t3", "t4"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	assertChildren(t, store, "t1", []string{"t3", "t4"})
+
+	if err := store.UpdateToMany("t1", "children", []string{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	assertChildren(t, store, "t1", []string{})
+
+	if err := store.UpdateToMany("unknown", "children", []string{"t2"}); err == nil {
+		t.Error("updating an unknown resource should fail")
+	}
+
+	if err := store.UpdateToMany("t1", "parent", []string{"t2"}); err == nil {
+		t.Error("updating a to-one relationship as a to-many should fail")
+	}
+}
+
+func testAddToMany(t *testing.T, store jsonapi.Store) {
+	res := newThing("t1", "one", 1)
+	res.Set("children", []string{"t2"})
+	mustInsert(t, store, res)
+
+	if err := store.AddToMany("t1", "children", []string{"t2", "t3"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	assertChildren(t, store, "t1", []string{"t2", "t3"})
+
+	if err := store.AddToMany("unknown", "children", []string{"t2"}); err == nil {
+		t.Error("updating an unknown resource should fail")
+	}
+}
+
+func testRemoveFromMany(t *testing.T, store jsonapi.Store) {
+	res := newThing("t1", "one", 1)
+	res.Set("children", []string{"t2", "t3", "t4"})
+	mustInsert(t, store, res)
+
+	if err := store.RemoveFromMany("t1", "children", []string{"t3", "t5"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	assertChildren(t, store, "t1", []string{"t2", "t4"})
+
+	if err := store.RemoveFromMany("unknown", "children", []string{"t2"}); err == nil {
+		t.Error("updating an unknown resource should fail")
+	}
+}
+
+func testConcurrency(t *testing.T, store jsonapi.Store) {
+	mustInsert(t, store, newThing("t0", "zero", 0))
+
+	var wg sync.WaitGroup
+
+	for i := 1; i <= 10; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			id := "t" + strconv.Itoa(i)
+			_ = store.Insert(newThing(id, "name", i))
+			_ = store.AddToMany("t0", "children", []string{id})
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			_, _ = store.Range(nil, &jsonapi.Params{SortingRules: []string{"rank"}})
+			_, _ = store.Resource("t0")
+		}()
+	}
+
+	wg.Wait()
+
+	if n := mustRange(t, store, nil, nil).Len(); n != 11 {
+		t.Errorf("got %d resources, expected 11", n)
+	}
+
+	if n := len(mustGet(t, store, "t0").Get("children").([]string)); n != 10 {
+		t.Errorf("got %d children, expected 10", n)
+	}
+}
+
+func newThing(id, name string, rank int) jsonapi.Resource {
+	typ := Type()
+	res := typ.New()
+	res.Set("id", id)
+	res.Set("name", name)
+	res.Set("rank", rank)
+
+	return res
+}
+
+func insertThings(t *testing.T, store jsonapi.Store) {
+	mustInsert(t, store, newThing("t1", "a", 2))
+	mustInsert(t, store, newThing("t2", "b", 1))
+	mustInsert(t, store, newThing("t3", "c", 3))
+	mustInsert(t, store, newThing("t4", "b", 2))
+	mustInsert(t, store, newThing("t5", "d", 3))
+}
+
+func mustInsert(t *testing.T, store jsonapi.Store, res jsonapi.Resource) {
+	t.Helper()
+
+	if err := store.Insert(res); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func mustGet(t *testing.T, store jsonapi.Store, id string) jsonapi.Resource {
+	t.Helper()
+
+	res, err := store.Resource(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return res
+}
+
+func mustRange(t *testing.T, store jsonapi.Store, ids []string, params *jsonapi.Params) jsonapi.Collection {
+	t.Helper()
+
+	col, err := store.Range(ids, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return col
+}
+
+func assertChildren(t *testing.T, store jsonapi.Store, id string, expected []string) {
+	t.Helper()
+
+	children := append([]string{}, mustGet(t, store, id).Get("children").([]string)...)
+	sort.Strings(children)
+
+	if !reflect.DeepEqual(children, expected) {
+		t.Errorf("got %v, expected %v", children, expected)
+	}
+}
+
+func collectionIDs(col jsonapi.Collection) []string {
+	ids := make([]string, 0, col.Len())
+
+	for i := 0; i < col.Len(); i++ {
+		ids = append(ids, col.At(i).Get("id").(string))
+	}
+
+	return ids
+}
+
+func dump(res jsonapi.Resource) map[string]any {
+	m := map[string]any{"id": res.Get("id")}
+
+	for name := range res.Attrs() {
+		m[name] = res.Get(name)
+	}
+
+	for name := range res.Rels() {
+		m[name] = res.Get(name)
+	}
+
+	return m
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -25,6 +26,13 @@ import (
 //   - bool
 //   - time (Go type is time.Time)
 //   - bytes (Go type is []uint8 or []byte)
+//   - float32, float64
+//   - decimal (Go type is Decimal)
+//   - strings (Go type is []string)
+//   - numbers (Go type is []float64)
+//   - object (Go type is map[string]any)
+//
+// Other types can be registered with RegisterAttrType.
 //
 // An asterisk is present as a prefix when the type is nullable (like *string).
 //
@@ -46,6 +54,12 @@ const (
 	AttrTypeBool
 	AttrTypeTime
 	AttrTypeBytes
+	AttrTypeFloat32
+	AttrTypeFloat64
+	AttrTypeDecimal
+	AttrTypeStrings
+	AttrTypeNumbers
+	AttrTypeObject
 )
 
 // A Type stores all the necessary information about a type as represented in
@@ -77,6 +91,10 @@ func (t *Type) AddAttr(attr Attr) error {
 		return fmt.Errorf("jsonapi: attribute type is invalid")
 	}
 
+	if _, err := regexp.Compile(attr.Pattern); err != nil {
+		return fmt.Errorf("jsonapi: pattern of attribute %q is invalid", attr.Name)
+	}
+
 	// Make sure the name isn't already used
 	for i := range t.Attrs {
 		if t.Attrs[i].Name == attr.Name {
@@ -109,10 +127,18 @@ func (t *Type) AddRel(rel Rel) error {
 		return fmt.Errorf("jsonapi: relationship name is empty")
 	}
 
-	if rel.ToType == "" {
+	if rel.ToType == "" && !rel.Polymorphic() {
 		return fmt.Errorf("jsonapi: relationship type is empty")
 	}
 
+	if rel.ToType != "" && rel.Polymorphic() {
+		return fmt.Errorf("jsonapi: relationship %q has both ToType and ToTypes", rel.FromName)
+	}
+
+	if _, err := regexp.Compile(rel.IDPattern); err != nil {
+		return fmt.Errorf("jsonapi: pattern of relationship %q is invalid", rel.FromName)
+	}
+
 	// Make sure the name isn't already used
 	for i := range t.Rels {
 		if t.Rels[i].FromName == rel.FromName {
@@ -185,10 +211,14 @@ func (t Type) Copy() Type {
 	}
 
 	for name, attr := range t.Attrs {
-		ctyp.Attrs[name] = attr
+		ctyp.Attrs[name] = attr.copy()
 	}
 
 	for name, rel := range t.Rels {
+		if rel.ToTypes != nil {
+			rel.ToTypes = append([]string{}, rel.ToTypes...)
+		}
+
 		ctyp.Rels[name] = rel
 	}
 
@@ -198,10 +228,47 @@ func (t Type) Copy() Type {
 }
 
 // Attr represents a resource attribute.
+//
+// The constraints on values are checked whenever resources are unmarshaled.
+// Required, ReadOnly and WriteOnce are only enforced by UnmarshalResource,
+// UnmarshalResourceAll and UnmarshalPartialResource, which read the resources
+// sent by clients. The constraints can also be defined with the options of the
+// api tag of a struct field, like `api:"attr,required,min=0,enum=a|b"`, and a
+// pattern tag for Pattern.
 type Attr struct {
 	Name     string
 	Type     int
 	Nullable bool
+
+	// Required attributes must be set to a value other than null when a
+	// resource is created, and cannot be set to null afterwards (required).
+	Required bool
+
+	// Min and Max are the bounds of the values of numbers (min and max).
+	Min *float64
+	Max *float64
+
+	// MinLength and MaxLength are the bounds of the number of characters of
+	// strings, the number of bytes of bytes, and the number of elements of
+	// arrays and objects (minlen and maxlen).
+	MinLength *int
+	MaxLength *int
+
+	// Pattern is a regular expression that strings must match (see package
+	// regexp).
+	Pattern string
+
+	// Enum holds the only accepted values (enum, separated by "|"). Values
+	// other than strings are compared using their default format (see
+	// package fmt).
+	Enum []string
+
+	// ReadOnly attributes cannot be set by clients (readonly).
+	ReadOnly bool
+
+	// WriteOnce attributes can only be set when a resource is created
+	// (writeonce).
+	WriteOnce bool
 }
 
 // UnmarshalToType unmarshals the data into a value of the type represented by
@@ -352,8 +419,74 @@ func (a Attr) UnmarshalToType(data []byte) (any, error) {
 		} else {
 			v = s
 		}
+	case AttrTypeFloat32:
+		var f float32
+
+		err = json.Unmarshal(data, &f)
+		v = f
+
+		if a.Nullable {
+			v = &f
+		}
+	case AttrTypeFloat64:
+		var f float64
+
+		err = json.Unmarshal(data, &f)
+		v = f
+
+		if a.Nullable {
+			v = &f
+		}
+	case AttrTypeDecimal:
+		var d Decimal
+
+		err = json.Unmarshal(data, &d)
+		v = d
+
+		if a.Nullable {
+			v = &d
+		}
+	case AttrTypeStrings:
+		s := []string{}
+
+		err = json.Unmarshal(data, &s)
+		if s == nil {
+			s = []string{}
+		}
+
+		v = s
+
+		if a.Nullable {
+			v = &s
+		}
+	case AttrTypeNumbers:
+		s := []float64{}
+
+		err = json.Unmarshal(data, &s)
+		if s == nil {
+			s = []float64{}
+		}
+
+		v = s
+
+		if a.Nullable {
+			v = &s
+		}
+	case AttrTypeObject:
+		m := map[string]any{}
+
+		err = json.Unmarshal(data, &m)
+		if m == nil {
+			m = map[string]any{}
+		}
+
+		v = m
+
+		if a.Nullable {
+			v = &m
+		}
 	default:
-		err = errors.New("attribute is of invalid or unknown type")
+		v, err = unmarshalCustomAttr(a.Type, a.Nullable, data)
 	}
 
 	if err != nil {
@@ -368,6 +501,13 @@ func (a Attr) UnmarshalToType(data []byte) (any, error) {
 }
 
 // Rel represents a resource relationship.
+//
+// The constraints on the related resources are checked whenever resources are
+// unmarshaled. Required and Immutable are only enforced by UnmarshalResource,
+// UnmarshalResourceAll, UnmarshalPartialResource and the handler's relationship
+// endpoints. The constraints can also be defined with the options of the api
+// tag of a struct field, like `api:"rel,tags,articles,required,max=10"`, and a
+// pattern tag for IDPattern.
 type Rel struct {
 	FromType string
 	FromName string
@@ -375,6 +515,30 @@ type Rel struct {
 	ToType   string
 	ToName   string
 	FromOne  bool
+
+	// ToTypes holds the types of the resources that a polymorphic
+	// relationship can point to, in which case ToType is empty. The value
+	// of such a relationship is an Identifier (to-one) or Identifiers
+	// (to-many) instead of IDs, since the type of each resource is needed.
+	ToTypes []string
+
+	// Required relationships must be set when a resource is created and
+	// cannot be emptied afterwards: a to-one relationship cannot be null and
+	// a to-many one cannot be empty (required).
+	Required bool
+
+	// MinCount and MaxCount are the bounds of the number of resources of a
+	// to-many relationship (min and max). 0 means no bound.
+	MinCount int
+	MaxCount int
+
+	// IDPattern is a regular expression that the IDs of the related resources
+	// must match (see package regexp).
+	IDPattern string
+
+	// Immutable relationships can only be set when a resource is created
+	// (immutable).
+	Immutable bool
 }
 
 // Invert returns the inverse relationship of r.
@@ -392,12 +556,13 @@ func (r *Rel) Invert() Rel {
 // Normalize inverts the relationship if necessary in order to have it in the
 // right direction and returns the result.
 //
-// This is the form stored in Schema.Rels.
+// This is the form stored in Schema.Rels. Polymorphic relationships are never
+// inverted since they have an inverse per type they can point to.
 func (r *Rel) Normalize() Rel {
 	from := r.FromType + r.FromName
 	to := r.ToType + r.ToName
 
-	if from < to || r.ToName == "" {
+	if from < to || r.ToName == "" || r.Polymorphic() {
 		return *r
 	}
 
@@ -412,7 +577,7 @@ func (r Rel) String() string {
 
 	id := r.FromType + "_" + r.FromName
 	if r.ToName != "" {
-		id += "_" + r.ToType + "_" + r.ToName
+		id += "_" + strings.Join(r.TargetTypes(), "|") + "_" + r.ToName
 	}
 
 	return id
@@ -456,7 +621,23 @@ func GetAttrType(t string) (int, bool) {
 		return AttrTypeTime, nullable
 	case "[]uint8", "[]byte", "bytes":
 		return AttrTypeBytes, nullable
+	case "float32":
+		return AttrTypeFloat32, nullable
+	case "float64":
+		return AttrTypeFloat64, nullable
+	case "jsonapi.Decimal", "decimal":
+		return AttrTypeDecimal, nullable
+	case "[]string", "strings":
+		return AttrTypeStrings, nullable
+	case "[]float64", "numbers":
+		return AttrTypeNumbers, nullable
+	case "map[string]interface {}", "object":
+		return AttrTypeObject, nullable
 	default:
+		if typ := attrTypes.find(t); typ != AttrTypeInvalid {
+			return typ, nullable
+		}
+
 		return AttrTypeInvalid, false
 	}
 }
@@ -495,8 +676,22 @@ func GetAttrTypeString(t int, nullable bool) string {
 		str = "time"
 	case AttrTypeBytes:
 		str = "bytes"
+	case AttrTypeFloat32:
+		str = "float32"
+	case AttrTypeFloat64:
+		str = "float64"
+	case AttrTypeDecimal:
+		str = "decimal"
+	case AttrTypeStrings:
+		str = "strings"
+	case AttrTypeNumbers:
+		str = "numbers"
+	case AttrTypeObject:
+		str = "object"
 	default:
-		str = ""
+		if ct, ok := attrTypes.get(t); ok {
+			str = ct.name
+		}
 	}
 
 	if nullable {
@@ -596,7 +791,43 @@ func GetZeroValue(t int, nullable bool) any {
 		}
 
 		return []byte{}
+	case AttrTypeFloat32:
+		if nullable {
+			return (*float32)(nil)
+		}
+
+		return float32(0)
+	case AttrTypeFloat64:
+		if nullable {
+			return (*float64)(nil)
+		}
+
+		return float64(0)
+	case AttrTypeDecimal:
+		if nullable {
+			return (*Decimal)(nil)
+		}
+
+		return Decimal("")
+	case AttrTypeStrings:
+		if nullable {
+			return (*[]string)(nil)
+		}
+
+		return []string{}
+	case AttrTypeNumbers:
+		if nullable {
+			return (*[]float64)(nil)
+		}
+
+		return []float64{}
+	case AttrTypeObject:
+		if nullable {
+			return (*map[string]any)(nil)
+		}
+
+		return map[string]any{}
 	default:
-		return nil
+		return customZeroValue(t, nullable)
 	}
 }
@@ -124,6 +124,12 @@ func TestAttrUnmarshalToType(t *testing.T) {
 		vuint32 = uint32(32)
 		vuint64 = uint64(64)
 		vbool   = true
+		vf32    = float32(1.5)
+		vf64    = float64(2.5)
+		vdec    = Decimal("12.50")
+		vstrs   = []string{"a", "b"}
+		vnums   = []float64{1, 2.5}
+		vobj    = map[string]any{"a": "b", "c": []any{float64(1)}}
 	)
 
 	tests := []struct {
@@ -157,6 +163,18 @@ func TestAttrUnmarshalToType(t *testing.T) {
 		{val: &vbool},           // *bool
 		{val: &time.Time{}},     // *time
 		{val: &[]byte{1, 2, 3}}, // *[]byte
+		{val: vf32},             // float32
+		{val: vf64},             // float64
+		{val: vdec},             // Decimal
+		{val: vstrs},            // []string
+		{val: vnums},            // []float64
+		{val: vobj},             // map[string]any
+		{val: &vf32},            // *float32
+		{val: &vf64},            // *float64
+		{val: &vdec},            // *Decimal
+		{val: &vstrs},           // *[]string
+		{val: &vnums},           // *[]float64
+		{val: &vobj},            // *map[string]any
 	}
 
 	attr := Attr{}
@@ -176,6 +194,28 @@ func TestAttrUnmarshalToType(t *testing.T) {
 	assert.NoError(err)
 	assert.Nil(val)
 
+	// Empty arrays and objects
+	attr.Nullable = false
+	attr.Type = AttrTypeStrings
+	val, err = attr.UnmarshalToType([]byte("null"))
+	assert.NoError(err)
+	assert.Equal([]string{}, val)
+
+	attr.Type = AttrTypeObject
+	val, err = attr.UnmarshalToType([]byte("null"))
+	assert.NoError(err)
+	assert.Equal(map[string]any{}, val)
+
+	// Decimal as a string
+	attr.Type = AttrTypeDecimal
+	val, err = attr.UnmarshalToType([]byte(`"-1.25e3"`))
+	assert.NoError(err)
+	assert.Equal(Decimal("-1.25e3"), val)
+
+	// Invalid decimal
+	_, err = attr.UnmarshalToType([]byte(`"1.2.3"`))
+	assert.Error(err)
+
 	// False value
 	attr.Type = AttrTypeBool
 	val, err = attr.UnmarshalToType([]byte("nottrue"))
@@ -408,6 +448,46 @@ func TestGetAttrType(t *testing.T) {
 	assert.Equal(AttrTypeBytes, typ)
 	assert.True(nullable)
 
+	typ, nullable = GetAttrType("float32")
+	assert.Equal(AttrTypeFloat32, typ)
+	assert.False(nullable)
+
+	typ, nullable = GetAttrType("*float64")
+	assert.Equal(AttrTypeFloat64, typ)
+	assert.True(nullable)
+
+	typ, nullable = GetAttrType("jsonapi.Decimal")
+	assert.Equal(AttrTypeDecimal, typ)
+	assert.False(nullable)
+
+	typ, nullable = GetAttrType("*decimal")
+	assert.Equal(AttrTypeDecimal, typ)
+	assert.True(nullable)
+
+	typ, nullable = GetAttrType("[]string")
+	assert.Equal(AttrTypeStrings, typ)
+	assert.False(nullable)
+
+	typ, nullable = GetAttrType("*strings")
+	assert.Equal(AttrTypeStrings, typ)
+	assert.True(nullable)
+
+	typ, nullable = GetAttrType("[]float64")
+	assert.Equal(AttrTypeNumbers, typ)
+	assert.False(nullable)
+
+	typ, nullable = GetAttrType("numbers")
+	assert.Equal(AttrTypeNumbers, typ)
+	assert.False(nullable)
+
+	typ, nullable = GetAttrType("map[string]interface {}")
+	assert.Equal(AttrTypeObject, typ)
+	assert.False(nullable)
+
+	typ, nullable = GetAttrType("*object")
+	assert.Equal(AttrTypeObject, typ)
+	assert.True(nullable)
+
 	typ, nullable = GetAttrType("invalid")
 	assert.Equal(AttrTypeInvalid, typ)
 	assert.False(nullable)
@@ -448,6 +528,18 @@ func TestGetAttrTypeString(t *testing.T) {
 	assert.Equal("*bool", GetAttrTypeString(AttrTypeBool, true))
 	assert.Equal("*time", GetAttrTypeString(AttrTypeTime, true))
 	assert.Equal("*bytes", GetAttrTypeString(AttrTypeBytes, true))
+	assert.Equal("float32", GetAttrTypeString(AttrTypeFloat32, false))
+	assert.Equal("float64", GetAttrTypeString(AttrTypeFloat64, false))
+	assert.Equal("decimal", GetAttrTypeString(AttrTypeDecimal, false))
+	assert.Equal("strings", GetAttrTypeString(AttrTypeStrings, false))
+	assert.Equal("numbers", GetAttrTypeString(AttrTypeNumbers, false))
+	assert.Equal("object", GetAttrTypeString(AttrTypeObject, false))
+	assert.Equal("*float32", GetAttrTypeString(AttrTypeFloat32, true))
+	assert.Equal("*float64", GetAttrTypeString(AttrTypeFloat64, true))
+	assert.Equal("*decimal", GetAttrTypeString(AttrTypeDecimal, true))
+	assert.Equal("*strings", GetAttrTypeString(AttrTypeStrings, true))
+	assert.Equal("*numbers", GetAttrTypeString(AttrTypeNumbers, true))
+	assert.Equal("*object", GetAttrTypeString(AttrTypeObject, true))
 	assert.Equal("", GetAttrTypeString(AttrTypeInvalid, false))
 	assert.Equal("", GetAttrTypeString(999, false))
 }
@@ -483,6 +575,18 @@ func TestGetZeroValue(t *testing.T) {
 	assert.Equal(nilptr("bool"), GetZeroValue(AttrTypeBool, true))
 	assert.Equal(nilptr("time.Time"), GetZeroValue(AttrTypeTime, true))
 	assert.Equal(nilptr("[]byte"), GetZeroValue(AttrTypeBytes, true))
+	assert.Equal(float32(0), GetZeroValue(AttrTypeFloat32, false))
+	assert.Equal(float64(0), GetZeroValue(AttrTypeFloat64, false))
+	assert.Equal(Decimal(""), GetZeroValue(AttrTypeDecimal, false))
+	assert.Equal([]string{}, GetZeroValue(AttrTypeStrings, false))
+	assert.Equal([]float64{}, GetZeroValue(AttrTypeNumbers, false))
+	assert.Equal(map[string]any{}, GetZeroValue(AttrTypeObject, false))
+	assert.Equal(nilptr("float32"), GetZeroValue(AttrTypeFloat32, true))
+	assert.Equal(nilptr("float64"), GetZeroValue(AttrTypeFloat64, true))
+	assert.Equal(nilptr("Decimal"), GetZeroValue(AttrTypeDecimal, true))
+	assert.Equal(nilptr("[]string"), GetZeroValue(AttrTypeStrings, true))
+	assert.Equal(nilptr("[]float64"), GetZeroValue(AttrTypeNumbers, true))
+	assert.Equal(nilptr("map[string]any"), GetZeroValue(AttrTypeObject, true))
 	assert.Equal(nil, GetZeroValue(AttrTypeInvalid, false))
 	assert.Equal(nil, GetZeroValue(999, false))
 }
@@ -0,0 +1,441 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// An unmarshaler unmarshals the members of documents and resources.
+//
+// By default, it stops at the first problem and returns the corresponding
+// error. If collect is true, the errors are recorded in errs with their source
+// pointers instead and unmarshaling goes on with the next member.
+//
+// Resources of unknown types are only reported when errors are collected or
+// strictTypes is true.
+//
+// If relData is not nil, the relationships found with data are added to it.
+//
+// The constraints of the attributes and relationships on values are always
+// checked. If constraints is true, the other ones (like Attr.ReadOnly) are
+// enforced too, as the resources are sent by clients to be created or updated.
+type unmarshaler struct {
+	schema      *Schema
+	collect     bool
+	strictTypes bool
+	constraints bool
+	relData     map[string][]string
+	errs        Errors
+}
+
+// fail returns err with pointer prepended to its source pointer if errors are
+// not collected. Otherwise, err is recorded that way and nil is returned.
+func (u *unmarshaler) fail(err error, pointer string) error {
+	if !u.collect {
+		return withPointer(err, pointer)
+	}
+
+	e, ok := withPointer(err, pointer).(Error)
+	if !ok {
+		e = NewErrBadRequest("Invalid JSON", err.Error())
+		e.Source["pointer"] = pointer
+	}
+
+	u.errs = append(u.errs, e)
+
+	return nil
+}
+
+// err returns the collected errors, or nil if there are none.
+func (u *unmarshaler) err() error {
+	if len(u.errs) == 0 {
+		return nil
+	}
+
+	return u.errs
+}
+
+// resource unmarshals the resource object found in data at pointer.
+//
+// If partial is true, the resource is a *SoftResource that only holds the fields
+// found in data, as explained in the documentation of UnmarshalPartialResource.
+//
+// When errors are collected, the returned resource is nil if it could not be
+// read at all.
+func (u *unmarshaler) resource(data []byte, pointer string, partial bool) (Resource, error) {
+	var rske resourceSkeleton
+
+	err := json.Unmarshal(data, &rske)
+	if err != nil {
+		return nil, u.fail(NewErrBadRequest(
+			"Invalid JSON",
+			"The provided JSON body could not be read.",
+		), pointer)
+	}
+
+	typ := u.schema.GetType(rske.Type)
+
+	if (u.collect || u.strictTypes) && typ.Name == "" {
+		return nil, u.fail(NewErrUnknownTypeInBody(rske.Type), pointer+"/type")
+	}
+
+	var (
+		res     Resource
+		newType *Type
+	)
+
+	if partial {
+		newType = &Type{Name: typ.Name}
+		res = &SoftResource{Type: newType}
+	} else {
+		res = typ.New()
+	}
+
+	res.Set("id", rske.ID)
+
+	attrNames := make([]string, 0, len(rske.Attributes))
+	for a := range rske.Attributes {
+		attrNames = append(attrNames, a)
+	}
+
+	sort.Strings(attrNames)
+
+	for _, a := range attrNames {
+		ptr := pointer + "/attributes/" + escapeJSONPointer(a)
+
+		attr, ok := typ.Attrs[a]
+		if !ok {
+			if err = u.fail(NewErrUnknownFieldInBody(typ.Name, a), ptr); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		val, err := attr.UnmarshalToType(rske.Attributes[a])
+		if err == nil && u.constraints {
+			err = attr.checkWrite(val, !partial)
+		} else if err == nil {
+			err = attr.CheckValue(val)
+		}
+
+		if err != nil {
+			if err = u.fail(err, ptr); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if partial {
+			_ = newType.AddAttr(attr)
+		}
+
+		res.Set(attr.Name, val)
+	}
+
+	// Required attributes
+	if u.constraints && !partial {
+		for _, a := range typ.Fields() {
+			if _, ok := rske.Attributes[a]; ok || !typ.Attrs[a].Required {
+				continue
+			}
+
+			err = u.fail(NewErrConstraintViolationInBody(
+				a, "required", fmt.Sprintf("%q is required.", a),
+			), pointer+"/attributes/"+escapeJSONPointer(a))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	relNames := make([]string, 0, len(rske.Relationships))
+	for r := range rske.Relationships {
+		relNames = append(relNames, r)
+	}
+
+	sort.Strings(relNames)
+
+	for _, r := range relNames {
+		ptr := pointer + "/relationships/" + escapeJSONPointer(r)
+		v := rske.Relationships[r]
+
+		rel, ok := typ.Rels[r]
+		if !ok {
+			if err = u.fail(NewErrUnknownFieldInBody(typ.Name, r), ptr); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		var idens Identifiers
+
+		if len(v.Data) > 0 {
+			if partial {
+				_ = newType.AddRel(rel)
+			}
+
+			if rel.ToOne {
+				var iden Identifier
+
+				err = json.Unmarshal(v.Data, &iden)
+
+				if iden.idOrLID() != "" {
+					idens = Identifiers{iden}
+				}
+			} else {
+				err = json.Unmarshal(v.Data, &idens)
+			}
+
+			if err == nil && rel.Polymorphic() {
+				err = checkIdentifierTypes(rel, idens)
+			}
+
+			// The local IDs are kept as IDs until they are resolved.
+			values := make(Identifiers, len(idens))
+			for i, iden := range idens {
+				values[i] = Identifier{Type: iden.Type, ID: iden.idOrLID()}
+			}
+
+			res.Set(rel.FromName, relValue(rel, values))
+
+			if err == nil && u.relData != nil {
+				addRelData(u.relData, typ.Name, rel.FromName)
+			}
+		}
+
+		if err != nil {
+			err = u.fail(NewErrInvalidFieldValueInBody(
+				rel.FromName,
+				string(v.Data),
+				typ.Name,
+			), ptr+"/data")
+			if err != nil {
+				return nil, err
+			}
+		} else if len(v.Data) > 0 {
+			err = u.checkRel(rel, idens, partial)
+			if err != nil {
+				if err = u.fail(err, ptr+"/data"); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// Meta and links
+		if rm, ok := res.(RelMetaHolder); ok && !partial {
+			if v.Meta != nil {
+				rm.SetRelMeta(rel.FromName, v.Meta)
+			}
+
+			if v.Links != nil {
+				rm.SetRelLinks(rel.FromName, v.Links)
+			}
+		}
+	}
+
+	// Required relationships
+	if u.constraints && !partial {
+		for _, r := range typ.Fields() {
+			if len(rske.Relationships[r].Data) > 0 || !typ.Rels[r].Required {
+				continue
+			}
+
+			err = u.fail(NewErrConstraintViolationInBody(
+				r, "required", fmt.Sprintf("%q is required.", r),
+			), pointer+"/relationships/"+escapeJSONPointer(r))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Meta
+	if m, ok := res.(MetaHolder); ok && !partial {
+		m.SetMeta(rske.Meta)
+	}
+
+	// Links
+	if l, ok := res.(LinkHolder); ok && !partial && rske.Links != nil {
+		l.SetLinks(rske.Links)
+	}
+
+	// Local ID
+	if l, ok := res.(LIDHolder); ok {
+		l.SetLID(rske.LID)
+	}
+
+	return res, nil
+}
+
+// checkIdentifierTypes returns an error if an identifier of idens is of a type
+// that rel cannot point to.
+func checkIdentifierTypes(rel Rel, idens Identifiers) error {
+	for _, iden := range idens {
+		if !rel.Targets(iden.Type) {
+			return fmt.Errorf("type %q does not match relationship %q", iden.Type, rel.FromName)
+		}
+	}
+
+	return nil
+}
+
+// checkRel checks idens, the resource linkage of relationship rel, against the
+// constraints of rel. partial is true if the resource is being updated.
+func (u *unmarshaler) checkRel(rel Rel, idens Identifiers, partial bool) error {
+	ids := make([]string, 0, len(idens))
+
+	// Local IDs are not checked against the pattern of the IDs.
+	for _, iden := range idens {
+		if iden.ID != "" {
+			ids = append(ids, iden.ID)
+		}
+	}
+
+	if u.constraints {
+		return rel.checkWrite(len(idens), ids, !partial)
+	}
+
+	return rel.checkLinkage(len(idens), ids)
+}
+
+// collection unmarshals the array of resource objects found in data at
+// pointer.
+func (u *unmarshaler) collection(data []byte, pointer string) (Collection, error) {
+	var cske []json.RawMessage
+
+	err := json.Unmarshal(data, &cske)
+	if err != nil {
+		return nil, u.fail(err, pointer)
+	}
+
+	col := &Resources{}
+
+	for i := range cske {
+		res, err := u.resource(cske[i], pointer+"/"+strconv.Itoa(i), false)
+		if err != nil {
+			return nil, err
+		}
+
+		if res != nil {
+			col.Add(res)
+		}
+	}
+
+	return col, nil
+}
+
+// document unmarshals the document found in payload.
+func (u *unmarshaler) document(payload []byte) (*Document, error) {
+	doc := &Document{
+		Included:  []Resource{},
+		Resources: map[string]map[string]struct{}{},
+		Links:     map[string]Link{},
+		RelData:   map[string][]string{},
+		Meta:      map[string]any{},
+	}
+	u.relData = doc.RelData
+
+	ske := &payloadSkeleton{}
+
+	// Unmarshal
+	err := json.Unmarshal(payload, ske)
+	if err != nil {
+		if u.collect {
+			_ = u.fail(err, "")
+			return nil, u.err()
+		}
+
+		return nil, err
+	}
+
+	// Data
+	switch {
+	case len(ske.Data) > 0:
+		switch {
+		case ske.Data[0] == '{':
+			// Resource
+			res, err := u.resource(ske.Data, "/data", false)
+			if err != nil {
+				return nil, err
+			}
+
+			if res != nil {
+				doc.Data = res
+			}
+		case ske.Data[0] == '[':
+			col, err := u.collection(ske.Data, "/data")
+			if err != nil {
+				return nil, err
+			}
+
+			if col != nil {
+				doc.Data = col
+			}
+		case string(ske.Data) == "null":
+			doc.Data = nil
+		default:
+			// TODO Not exactly the right error
+			err = u.fail(NewErrMissingDataMember(), "/data")
+			if err != nil {
+				return nil, err
+			}
+		}
+	case len(ske.Errors) > 0:
+		doc.Errors = ske.Errors
+	}
+
+	// Included
+	for i, rawInc := range ske.Included {
+		ptr := "/included/" + strconv.Itoa(i)
+
+		var inc Identifier
+
+		err = json.Unmarshal(rawInc, &inc)
+		if err != nil {
+			if err = u.fail(err, ptr); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		res, err := u.resource(rawInc, ptr, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if res != nil {
+			doc.Included = append(doc.Included, res)
+		}
+	}
+
+	// Links
+	if ske.Links != nil {
+		doc.Links = ske.Links
+	}
+
+	// Meta
+	doc.Meta = ske.Meta
+
+	// JSON:API object
+	doc.JSONAPI = ske.JSONAPI
+
+	// Full linkage, which is only meaningful if all the resources could
+	// be read.
+	if len(u.errs) == 0 {
+		if errs, ok := CheckFullLinkage(doc).(Errors); ok {
+			for _, e := range errs {
+				if err = u.fail(e, ""); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return doc, nil
+}
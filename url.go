@@ -72,9 +72,17 @@ func NewURL(schema *Schema, su SimpleURL) (*URL, error) {
 	}
 
 	// Params
+	//
+	// The related resources of a polymorphic relationship can be of any of
+	// the types it points to, in which case ResType is empty.
 	var err error
 
-	url.Params, err = NewParams(schema, su, url.ResType)
+	resTypes := []string{url.ResType}
+	if url.Rel.Polymorphic() {
+		resTypes = url.Rel.ToTypes
+	}
+
+	url.Params, err = newParams(schema, su, resTypes)
 	if err != nil {
 		return nil, err
 	}
@@ -174,6 +182,15 @@ func (u *URL) String() string {
 
 	// Pagination
 	if u.IsCol {
+		for _, key := range []string{"after", "before"} {
+			if cursor, ok := u.Params.Page[key]; ok {
+				urlParams = append(
+					urlParams,
+					"page%5B"+key+"%5D="+url.QueryEscape(fmt.Sprint(cursor)),
+				)
+			}
+		}
+
 		if num, ok := u.Params.Page["number"]; ok {
 			urlParams = append(
 				urlParams,
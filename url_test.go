@@ -1,6 +1,7 @@
 package jsonapi_test
 
 import (
+	"errors"
 	"net/url"
 	"testing"
 
@@ -373,6 +374,7 @@ func TestParseParams(t *testing.T) {
 			expectedParams: Params{
 				Fields: map[string][]string{
 					"mocktypes1": mockTypes1.Fields(),
+					"mocktypes2": mockTypes2.Fields(),
 				},
 				Attrs:        map[string][]Attr{},
 				Rels:         map[string][]Rel{},
@@ -533,6 +535,28 @@ func TestParseParams(t *testing.T) {
 	}
 }
 
+func TestParseParamsErrorSources(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+
+	tests := map[string]string{
+		"/mocktypes1?fields[unknown]=str":        "fields[unknown]",
+		"/mocktypes1?fields[mocktypes1]=str,str": "fields[mocktypes1]",
+		"/mocktypes1?filter={invalid":            "filter",
+		"/mocktypes1?unknown=1":                  "unknown",
+	}
+
+	for raw, param := range tests {
+		_, err := NewURLFromRaw(schema, raw)
+
+		var e Error
+		if assert.True(errors.As(err, &e), raw) {
+			assert.Equal(param, e.Source["parameter"], raw)
+		}
+	}
+}
+
 func TestURLEscaping(t *testing.T) {
 	assert := assert.New(t)
 
@@ -569,6 +593,32 @@ func TestURLEscaping(t *testing.T) {
 				&sort=bool,int,int16,int32,int64,int8,str,time,uint,uint16,
 					uint32,uint64,uint8,id
 			`,
+		}, {
+			url: `
+				/mocktypes1
+				?fields[mocktypes1]=bool
+				&page[size]=10
+				&page[before]=b%3D
+				&page[after]=a%3D
+			`,
+			escaped: `
+				/mocktypes1
+				?fields%5Bmocktypes1%5D=bool
+				&page%5Bafter%5D=a%3D
+				&page%5Bbefore%5D=b%3D
+				&page%5Bsize%5D=10
+				&sort=bool%2Cint%2Cint16%2Cint32%2Cint64%2Cint8%2Cstr%2Ctime%2C
+				uint%2Cuint16%2Cuint32%2Cuint64%2Cuint8%2Cid
+				`,
+			unescaped: `
+				/mocktypes1
+				?fields[mocktypes1]=bool
+				&page[after]=a=
+				&page[before]=b=
+				&page[size]=10
+				&sort=bool,int,int16,int32,int64,int8,str,time,uint,uint16,
+					uint32,uint64,uint8,id
+			`,
 		},
 	}
 
@@ -3,6 +3,8 @@ package jsonapi_test
 import (
 	"strings"
 	"time"
+
+	"github.com/mfcochauxlaberge/jsonapi"
 )
 
 func makeOneLineNoSpaces(str string) string {
@@ -47,6 +49,20 @@ func ptr(v any) any {
 	// []byte
 	case []byte:
 		return &c
+	// Numbers
+	case float32:
+		return &c
+	case float64:
+		return &c
+	case jsonapi.Decimal:
+		return &c
+	// Arrays and objects
+	case []string:
+		return &c
+	case []float64:
+		return &c
+	case map[string]any:
+		return &c
 	default:
 		return nil
 	}
@@ -101,6 +117,26 @@ func nilptr(t string) any {
 	case "[]byte":
 		var p *[]byte
 		return p
+	// Numbers
+	case "float32":
+		var p *float32
+		return p
+	case "float64":
+		var p *float64
+		return p
+	case "Decimal":
+		var p *jsonapi.Decimal
+		return p
+	// Arrays and objects
+	case "[]string":
+		var p *[]string
+		return p
+	case "[]float64":
+		var p *[]float64
+		return p
+	case "map[string]any":
+		var p *map[string]any
+		return p
 	default:
 		return nil
 	}
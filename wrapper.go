@@ -17,9 +17,14 @@ type Wrapper struct {
 
 	// Structure
 	typ   string
+	lid   string
 	attrs map[string]Attr
 	rels  map[string]Rel
 	meta  Meta
+	links map[string]Link
+
+	relMeta  map[string]Meta
+	relLinks map[string]map[string]Link
 }
 
 // Wrap wraps v (a struct or a pointer to a struct) and returns a Wrapper that
@@ -73,13 +78,17 @@ func Wrap(v any) *Wrapper {
 		jsonTag := fs.Tag.Get("json")
 		apiTag := fs.Tag.Get("api")
 
-		if apiTag == "attr" {
+		if strings.Split(apiTag, ",")[0] == "attr" {
 			typ, null := GetAttrType(fs.Type.String())
-			w.attrs[jsonTag] = Attr{
+			attr := Attr{
 				Name:     jsonTag,
 				Type:     typ,
 				Nullable: null,
 			}
+
+			// The tag was already checked by Check.
+			_ = parseAttrTag(&attr, fs)
+			w.attrs[jsonTag] = attr
 		}
 	}
 
@@ -89,25 +98,22 @@ func Wrap(v any) *Wrapper {
 		fs := w.val.Type().Field(i)
 		jsonTag := fs.Tag.Get("json")
 		relTag := strings.Split(fs.Tag.Get("api"), ",")
-		invName := ""
-
-		if len(relTag) == 3 {
-			invName = relTag[2]
-		}
 
 		toOne := true
-		if fs.Type.String() == "[]string" {
+		if ft := fs.Type.String(); ft == "[]string" || ft == "jsonapi.Identifiers" {
 			toOne = false
 		}
 
 		if relTag[0] == "rel" {
-			w.rels[jsonTag] = Rel{
+			rel := Rel{
 				FromName: jsonTag,
-				ToType:   relTag[1],
 				ToOne:    toOne,
-				ToName:   invName,
 				FromType: w.typ,
 			}
+
+			// The tag was already checked by Check.
+			_ = parseRelTag(&rel, fs, w.val.Type().Name())
+			w.rels[jsonTag] = rel
 		}
 	}
 
@@ -118,6 +124,13 @@ func Wrap(v any) *Wrapper {
 		}
 	}
 
+	// Links
+	if l, ok := v.(LinkHolder); ok {
+		if len(l.Links()) > 0 {
+			w.SetLinks(l.Links())
+		}
+	}
+
 	return w
 }
 
@@ -219,16 +232,28 @@ func (w *Wrapper) Copy() Resource {
 
 	// Relationships
 	for _, rel := range w.Rels() {
-		if rel.ToOne {
-			nw.Set(rel.FromName, w.Get(rel.FromName).(string))
-		} else {
-			nw.Set(rel.FromName, w.Get(rel.FromName).([]string))
-		}
+		nw.Set(rel.FromName, copyRelValue(w.Get(rel.FromName)))
 	}
 
+	nw.lid = w.lid
+	nw.meta = copyMeta(w.meta)
+	nw.links = copyLinks(w.links)
+	nw.relMeta = copyRelMeta(w.relMeta)
+	nw.relLinks = copyRelLinks(w.relLinks)
+
 	return nw
 }
 
+// GetLID returns the resource's local ID.
+func (w *Wrapper) GetLID() string {
+	return w.lid
+}
+
+// SetLID sets the resource's local ID.
+func (w *Wrapper) SetLID(lid string) {
+	w.lid = lid
+}
+
 // Meta returns the meta values of the resource.
 func (w *Wrapper) Meta() Meta {
 	return w.meta
@@ -239,6 +264,44 @@ func (w *Wrapper) SetMeta(m Meta) {
 	w.meta = m
 }
 
+// Links returns the links of the resource.
+func (w *Wrapper) Links() map[string]Link {
+	return w.links
+}
+
+// SetLinks sets the links of the resource.
+func (w *Wrapper) SetLinks(links map[string]Link) {
+	w.links = links
+}
+
+// RelMeta returns the meta values of the relationship rel.
+func (w *Wrapper) RelMeta(rel string) Meta {
+	return w.relMeta[rel]
+}
+
+// SetRelMeta sets the meta values of the relationship rel.
+func (w *Wrapper) SetRelMeta(rel string, m Meta) {
+	if w.relMeta == nil {
+		w.relMeta = map[string]Meta{}
+	}
+
+	w.relMeta[rel] = m
+}
+
+// RelLinks returns the links of the relationship rel.
+func (w *Wrapper) RelLinks(rel string) map[string]Link {
+	return w.relLinks[rel]
+}
+
+// SetRelLinks sets the links of the relationship rel.
+func (w *Wrapper) SetRelLinks(rel string, links map[string]Link) {
+	if w.relLinks == nil {
+		w.relLinks = map[string]map[string]Link{}
+	}
+
+	w.relLinks[rel] = links
+}
+
 // Private methods
 
 func (w *Wrapper) getField(key string) any {
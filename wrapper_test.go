@@ -326,3 +326,78 @@ func TestWrapperGetAndSetErrors(t *testing.T) {
 		wrap.Set("str", 42)
 	})
 }
+
+func TestWrapperLID(t *testing.T) {
+	assert := assert.New(t)
+
+	wrap := Wrap(&mocktype{ID: "id1"})
+	assert.Equal("", wrap.GetLID())
+
+	wrap.SetLID("local1")
+	assert.Equal("local1", wrap.GetLID())
+	assert.Equal("local1", wrap.Copy().(*Wrapper).GetLID())
+}
+
+func TestWrapperRelMeta(t *testing.T) {
+	assert := assert.New(t)
+
+	wrap := Wrap(&mocktype{ID: "id1"})
+	assert.Nil(wrap.RelMeta("to-x"))
+	assert.Nil(wrap.RelLinks("to-x"))
+
+	wrap.SetRelMeta("to-x", Meta{"count": 2})
+	wrap.SetRelLinks("to-x", map[string]Link{"about": {HRef: "/about"}})
+	assert.Equal(Meta{"count": 2}, wrap.RelMeta("to-x"))
+	assert.Equal(map[string]Link{"about": {HRef: "/about"}}, wrap.RelLinks("to-x"))
+
+	// Copies
+	cp := wrap.Copy().(*Wrapper)
+	wrap.RelMeta("to-x")["count"] = 3
+	assert.Equal(Meta{"count": 2}, cp.RelMeta("to-x"))
+	assert.Equal(map[string]Link{"about": {HRef: "/about"}}, cp.RelLinks("to-x"))
+}
+
+func TestWrapNumbersAndArrays(t *testing.T) {
+	assert := assert.New(t)
+
+	res := mockTypeNumbers{
+		ID:         "id1",
+		Float32:    1.5,
+		Float64:    2.5,
+		Decimal:    "12.50",
+		Strings:    []string{"a", "b"},
+		Numbers:    []float64{1, 2.5},
+		Object:     map[string]any{"a": "b", "c": []any{float64(1)}},
+		DecimalPtr: ptr(Decimal("0.1")).(*Decimal),
+	}
+
+	wrap := Wrap(&res)
+
+	assert.Equal(AttrTypeFloat32, wrap.Attr("float32").Type)
+	assert.Equal(AttrTypeFloat64, wrap.Attr("float64").Type)
+	assert.Equal(AttrTypeDecimal, wrap.Attr("decimal").Type)
+	assert.Equal(AttrTypeStrings, wrap.Attr("strings").Type)
+	assert.Equal(AttrTypeNumbers, wrap.Attr("numbers").Type)
+	assert.Equal(AttrTypeObject, wrap.Attr("object").Type)
+	assert.True(wrap.Attr("float64ptr").Nullable)
+	assert.True(wrap.Attr("objectptr").Nullable)
+	assert.Equal(Decimal("12.50"), wrap.Get("decimal"))
+	assert.Nil(wrap.Get("float64ptr"))
+
+	wrap.Set("float64", 3.5)
+	assert.Equal(3.5, res.Float64)
+
+	// Round trip
+	schema := &Schema{}
+	_ = schema.AddType(MustBuildType(mockTypeNumbers{}))
+
+	url, _ := NewURLFromRaw(schema, "/mocktypesnumbers/id1")
+
+	payload, err := MarshalDocument(&Document{Data: wrap}, url)
+	assert.NoError(err)
+	assert.Contains(string(payload), `"decimal":12.50`)
+
+	doc, err := UnmarshalDocument(payload, schema)
+	assert.NoError(err)
+	assert.True(Equal(wrap, doc.Data.(Resource)))
+}